@@ -26,6 +26,25 @@ func main() {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 	defer store.Close()
+	store.SetGlobalTimer(cfg.GlobalTimer)
+	store.SetNoOverlap(cfg.NoOverlap)
+	store.SetTimeEntryRules(cfg.TimeEntryRules())
+	store.SetCalendar(cfg.Location(), cfg.FirstDayOfWeek())
+	store.SetBudgetThresholds(cfg.BudgetThresholds)
+	store.SetDailyPlanSlots(cfg.DailyPlanSlots)
+	store.SetWorkingHours(cfg.WorkingSchedule())
+	store.SetDefaultTaskMinutes(cfg.DefaultTaskMinutes)
+	store.SetMatrixRules(cfg.MatrixRules())
+	store.SetStaleTaskDays(cfg.StaleTaskDays)
+
+	// Close or flag timers left running by a previous crash
+	reconciled, err := store.ReconcileDanglingTimeEntries(cfg.ReconcileOptions(), time.Now())
+	if err != nil {
+		log.Fatalf("Failed to reconcile dangling time entries: %v", err)
+	}
+	if len(reconciled) > 0 {
+		log.Printf("Reconciled %d dangling time entries (policy: %s)", len(reconciled), cfg.DanglingPolicy)
+	}
 
 	// Initialize API server
 	server := api.NewServer(cfg, store)
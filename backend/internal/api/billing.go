@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// setProjectRate sets or clears a project's hourly rate
+func (s *Server) setProjectRate(w http.ResponseWriter, r *http.Request, projectID int) {
+	var req types.SetHourlyRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	rate, err := s.storage.SetProjectRate(projectID, req.HourlyRate)
+	if err != nil {
+		log.Printf("Failed to set hourly rate for project %d: %v", projectID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to set hourly rate")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*rate, "Hourly rate updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// setTaskRate sets or clears a task's hourly rate
+func (s *Server) setTaskRate(w http.ResponseWriter, r *http.Request, taskID int) {
+	var req types.SetHourlyRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	rate, err := s.storage.SetTaskRate(taskID, req.HourlyRate)
+	if err != nil {
+		log.Printf("Failed to set hourly rate for task %d: %v", taskID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to set hourly rate")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*rate, "Hourly rate updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// getTimeEntryBilling returns whether a time entry is billable and which invoice it is on
+func (s *Server) getTimeEntryBilling(w http.ResponseWriter, r *http.Request, timeEntryID int) {
+	billing, err := s.storage.GetTimeEntryBilling(timeEntryID)
+	if err != nil {
+		log.Printf("Failed to get billing for time entry %d: %v", timeEntryID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Time entry not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve time entry billing")
+		return
+	}
+
+	response := types.NewAPIResponse(*billing)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// setTimeEntryBillable marks a time entry as billable or non-billable
+func (s *Server) setTimeEntryBillable(w http.ResponseWriter, r *http.Request, timeEntryID int) {
+	var req types.SetBillableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	billing, err := s.storage.SetTimeEntryBillable(timeEntryID, *req.Billable)
+	if err != nil {
+		log.Printf("Failed to set billable flag for time entry %d: %v", timeEntryID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Time entry not found")
+			return
+		}
+		if strings.Contains(err.Error(), "is invoiced") {
+			s.writeError(w, http.StatusConflict, "Time entry is invoiced and cannot be changed")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to update time entry billing")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*billing, "Time entry billing updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleInvoices handles listing a project's invoices and drafting new ones
+func (s *Server) handleInvoices(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getInvoices(w, r)
+	case http.MethodPost:
+		s.createInvoice(w, r)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleInvoiceByID handles individual invoice operations
+func (s *Server) handleInvoiceByID(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/invoices/
+	path := r.URL.Path[len("/api/invoices/"):]
+	if path == "" || strings.Contains(path, "/") {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	invoiceID, err := strconv.Atoi(path)
+	if err != nil || invoiceID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getInvoice(w, r, invoiceID)
+	case http.MethodDelete:
+		s.deleteInvoice(w, r, invoiceID)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// getInvoices returns the invoices of the project given by the project_id query parameter
+func (s *Server) getInvoices(w http.ResponseWriter, r *http.Request) {
+	projectIDStr := r.URL.Query().Get("project_id")
+	if projectIDStr == "" {
+		s.writeError(w, http.StatusBadRequest, "project_id query parameter is required")
+		return
+	}
+
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil || projectID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	invoices, err := s.storage.GetInvoicesByProject(projectID)
+	if err != nil {
+		log.Printf("Failed to get invoices for project %d: %v", projectID, err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve invoices")
+		return
+	}
+
+	response := types.NewAPIResponse(invoices)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// createInvoice drafts an invoice from a project's unbilled time in a period
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	invoice, err := s.storage.CreateInvoice(req)
+	if err != nil {
+		log.Printf("Failed to create invoice for project %d: %v", req.ProjectID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		if strings.Contains(err.Error(), "no unbilled time entries") {
+			s.writeError(w, http.StatusUnprocessableEntity, "No unbilled time entries in the period")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to create invoice")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*invoice, "Invoice created successfully")
+	s.writeJSON(w, http.StatusCreated, response)
+}
+
+// getInvoice returns an invoice with its lines
+func (s *Server) getInvoice(w http.ResponseWriter, r *http.Request, invoiceID int) {
+	invoice, err := s.storage.GetInvoice(invoiceID)
+	if err != nil {
+		log.Printf("Failed to get invoice %d: %v", invoiceID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Invoice not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve invoice")
+		return
+	}
+
+	response := types.NewAPIResponse(*invoice)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// deleteInvoice deletes an invoice draft, releasing its time entries
+func (s *Server) deleteInvoice(w http.ResponseWriter, r *http.Request, invoiceID int) {
+	if err := s.storage.DeleteInvoice(invoiceID); err != nil {
+		log.Printf("Failed to delete invoice %d: %v", invoiceID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Invoice not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete invoice")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Invoice deleted successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// parseRoundingRule parses the rounding increment in minutes and the rounding mode query parameters,
+// writing an error response and returning false when either is invalid
+func (s *Server) parseRoundingRule(w http.ResponseWriter, incrementStr, modeStr string) (types.RoundingRule, bool) {
+	var rule types.RoundingRule
+
+	if incrementStr != "" {
+		increment, err := strconv.Atoi(incrementStr)
+		if err != nil || (increment != 0 && increment != 1 && increment != 6 && increment != 15 && increment != 30) {
+			s.writeError(w, http.StatusBadRequest, "rounding must be one of 0, 1, 6, 15 or 30 minutes")
+			return rule, false
+		}
+		rule.IncrementMinutes = increment
+	}
+
+	if modeStr != "" {
+		switch types.RoundingMode(modeStr) {
+		case types.RoundingModeNearest, types.RoundingModeUp, types.RoundingModeDown:
+			rule.Mode = types.RoundingMode(modeStr)
+		default:
+			s.writeError(w, http.StatusBadRequest, "rounding_mode must be nearest, up or down")
+			return rule, false
+		}
+	}
+
+	return rule, true
+}
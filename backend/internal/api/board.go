@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleProjectByID handles individual project operations
+func (s *Server) handleProjectByID(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/projects/
+	path := r.URL.Path[len("/api/projects/"):]
+
+	// Split path components
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) == 0 || pathParts[0] == "" {
+		s.writeError(w, http.StatusBadRequest, "Project ID is required")
+		return
+	}
+
+	// Parse project ID
+	projectID, err := strconv.Atoi(pathParts[0])
+	if err != nil || projectID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	if len(pathParts) == 2 && pathParts[1] == "board" {
+		// /api/projects/{id}/board
+		if r.Method == http.MethodGet {
+			s.getBoard(w, r, projectID)
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 2 && pathParts[1] == "time-entry-rules" {
+		// /api/projects/{id}/time-entry-rules
+		switch r.Method {
+		case http.MethodGet:
+			s.getProjectTimeEntryRules(w, r, projectID)
+		case http.MethodPut:
+			s.setProjectTimeEntryRules(w, r, projectID)
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 2 && pathParts[1] == "rate" {
+		// /api/projects/{id}/rate
+		if r.Method == http.MethodPut {
+			s.setProjectRate(w, r, projectID)
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 4 && pathParts[1] == "board" && pathParts[2] == "columns" {
+		// /api/projects/{id}/board/columns/{status}
+		if r.Method == http.MethodPut {
+			s.setWIPLimit(w, r, projectID, types.TaskStatus(pathParts[3]))
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+	}
+}
+
+// getBoard returns a project's tasks grouped by status
+func (s *Server) getBoard(w http.ResponseWriter, r *http.Request, projectID int) {
+	board, err := s.storage.GetBoard(projectID)
+	if err != nil {
+		log.Printf("Failed to get board for project %d: %v", projectID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve board")
+		return
+	}
+
+	response := types.NewAPIResponse(*board)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// setWIPLimit sets or clears the WIP limit of a board column
+func (s *Server) setWIPLimit(w http.ResponseWriter, r *http.Request, projectID int, status types.TaskStatus) {
+	switch status {
+	case types.TaskStatusPending, types.TaskStatusInProgress, types.TaskStatusCompleted, types.TaskStatusCancelled:
+	default:
+		s.writeError(w, http.StatusNotFound, "Board column not found")
+		return
+	}
+
+	var req types.SetWIPLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if err := s.storage.SetWIPLimit(projectID, status, req.WIPLimit); err != nil {
+		log.Printf("Failed to set WIP limit for project %d: %v", projectID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to set WIP limit")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "WIP limit updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// moveTask moves a task to a board column and position
+func (s *Server) moveTask(w http.ResponseWriter, r *http.Request, taskID int) {
+	var req types.MoveTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	task, err := s.storage.MoveTask(taskID, req)
+	if err != nil {
+		log.Printf("Failed to move task %d: %v", taskID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		if strings.Contains(err.Error(), "WIP limit") {
+			s.writeErrorWithCode(w, http.StatusConflict, err.Error(), "wip_limit_reached")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to move task")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*task, "Task moved successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// budgetCheckInterval is how often running timers are checked against their budgets
+const budgetCheckInterval = time.Minute
+
+// runBudgetChecks raises the budget alerts crossed by running timers until stop is closed
+func (s *Server) runBudgetChecks(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := s.storage.RaiseRunningBudgetAlerts(now); err != nil {
+				log.Printf("Failed to raise budget alerts for running timers: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// handleBudgets handles listing and creating budgets
+func (s *Server) handleBudgets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getBudgets(w, r)
+	case http.MethodPost:
+		s.createBudget(w, r)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleBudgetByID handles individual budget operations
+func (s *Server) handleBudgetByID(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/budgets/
+	path := r.URL.Path[len("/api/budgets/"):]
+	if path == "" || strings.Contains(path, "/") {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	budgetID, err := strconv.Atoi(path)
+	if err != nil || budgetID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getBudget(w, r, budgetID)
+	case http.MethodPut:
+		s.updateBudget(w, r, budgetID)
+	case http.MethodDelete:
+		s.deleteBudget(w, r, budgetID)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// getBudgets returns budgets with their remaining hours, optionally filtered by project_id or task_id
+func (s *Server) getBudgets(w http.ResponseWriter, r *http.Request) {
+	var projectID, taskID *int
+	if projectIDStr := r.URL.Query().Get("project_id"); projectIDStr != "" {
+		id, err := strconv.Atoi(projectIDStr)
+		if err != nil || id <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		projectID = &id
+	}
+	if taskIDStr := r.URL.Query().Get("task_id"); taskIDStr != "" {
+		id, err := strconv.Atoi(taskIDStr)
+		if err != nil || id <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid task ID")
+			return
+		}
+		taskID = &id
+	}
+
+	budgets, err := s.storage.GetBudgets(projectID, taskID)
+	if err != nil {
+		log.Printf("Failed to get budgets: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve budgets")
+		return
+	}
+
+	response := types.NewAPIResponse(budgets)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// createBudget creates a budget on a project or task
+func (s *Server) createBudget(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	budget, err := s.storage.CreateBudget(req)
+	if err != nil {
+		log.Printf("Failed to create budget: %v", err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "must be greater than zero") || strings.Contains(err.Error(), "either a project or a task") {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to create budget")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*budget, "Budget created successfully")
+	s.writeJSON(w, http.StatusCreated, response)
+}
+
+// getBudget returns a budget with its consumption in the current period
+func (s *Server) getBudget(w http.ResponseWriter, r *http.Request, budgetID int) {
+	budget, err := s.storage.GetBudget(budgetID)
+	if err != nil {
+		log.Printf("Failed to get budget %d: %v", budgetID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Budget not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve budget")
+		return
+	}
+
+	response := types.NewAPIResponse(*budget)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// updateBudget changes a budget's period, hours and thresholds
+func (s *Server) updateBudget(w http.ResponseWriter, r *http.Request, budgetID int) {
+	var req types.UpdateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	budget, err := s.storage.UpdateBudget(budgetID, req)
+	if err != nil {
+		log.Printf("Failed to update budget %d: %v", budgetID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Budget not found")
+			return
+		}
+		if strings.Contains(err.Error(), "must be greater than zero") {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to update budget")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*budget, "Budget updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// deleteBudget deletes a budget and its alerts
+func (s *Server) deleteBudget(w http.ResponseWriter, r *http.Request, budgetID int) {
+	if err := s.storage.DeleteBudget(budgetID); err != nil {
+		log.Printf("Failed to delete budget %d: %v", budgetID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Budget not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete budget")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Budget deleted successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleBudgetAlerts lists budget alerts, leaving out acknowledged ones unless all=true
+func (s *Server) handleBudgetAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	includeAcknowledged := false
+	if allStr := r.URL.Query().Get("all"); allStr != "" {
+		all, err := strconv.ParseBool(allStr)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "all must be true or false")
+			return
+		}
+		includeAcknowledged = all
+	}
+
+	alerts, err := s.storage.GetBudgetAlerts(includeAcknowledged)
+	if err != nil {
+		log.Printf("Failed to get budget alerts: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve budget alerts")
+		return
+	}
+
+	response := types.NewAPIResponse(alerts)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleBudgetAlertByID handles /api/budgets/alerts/{id}/acknowledge
+func (s *Server) handleBudgetAlertByID(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/budgets/alerts/"), "/")
+	if len(pathParts) != 2 || pathParts[1] != "acknowledge" {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	alertID, err := strconv.Atoi(pathParts[0])
+	if err != nil || alertID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid budget alert ID")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	alert, err := s.storage.AcknowledgeBudgetAlert(alertID)
+	if err != nil {
+		log.Printf("Failed to acknowledge budget alert %d: %v", alertID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Budget alert not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to acknowledge budget alert")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*alert, "Budget alert acknowledged successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
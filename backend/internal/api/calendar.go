@@ -0,0 +1,175 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"focused-todo/backend/internal/ical"
+	"focused-todo/backend/pkg/types"
+)
+
+// calendarDomain qualifies UIDs so they stay unique when calendars are merged
+const calendarDomain = "focused-todo"
+
+// handleCalendarFeed serves tasks with due dates as VTODOs and stopped time entries as VEVENTs, for calendar
+// apps to subscribe to. The configured calendar token must be passed as the token query parameter.
+func (s *Server) handleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if s.config.CalendarToken == "" {
+		s.writeError(w, http.StatusNotFound, "Calendar feed is not enabled")
+		return
+	}
+
+	params := r.URL.Query()
+	if subtle.ConstantTimeCompare([]byte(params.Get("token")), []byte(s.config.CalendarToken)) != 1 {
+		s.writeError(w, http.StatusUnauthorized, "Invalid calendar token")
+		return
+	}
+
+	var projectID *int
+	if projectStr := params.Get("project"); projectStr != "" {
+		id, err := strconv.Atoi(projectStr)
+		if err != nil || id <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		projectID = &id
+	}
+
+	// Logged time defaults to the last ninety days to keep feeds small
+	days := 90
+	if daysStr := params.Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > 3650 {
+			s.writeError(w, http.StatusBadRequest, "days must be between 1 and 3650")
+			return
+		}
+		days = parsed
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	// The server's local zone has no portable name, so it is not announced
+	timezone := params.Get("tz")
+	if timezone == "" {
+		timezone = s.config.Timezone
+	}
+	if timezone == "Local" {
+		timezone = ""
+	}
+
+	feed, err := s.storage.GetCalendarFeed(projectID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Printf("Failed to get calendar feed: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to generate calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="focused-todo.ics"`)
+	if err := buildCalendar(feed, loc, timezone).Encode(w); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+	}
+}
+
+// buildCalendar converts a feed to a VCALENDAR. Times are written in UTC; due dates at midnight in loc
+// are written as all-day dates. A non-empty timezone names loc for calendar apps. UIDs and timestamps
+// come from stored IDs and times, so regenerating the calendar yields the same components for unchanged
+// data, and events carry their revision as SEQUENCE so apps pick up corrected entries.
+func buildCalendar(feed *types.CalendarFeed, loc *time.Location, timezone string) ical.Component {
+	calendar := ical.Component{Name: "VCALENDAR"}
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", "-//Focused Todo//Calendar Feed//EN")
+	calendar.Add("CALSCALE", "GREGORIAN")
+	calendar.Add("METHOD", "PUBLISH")
+	calendar.AddText("X-WR-CALNAME", "Focused Todo")
+	if timezone != "" {
+		calendar.AddText("X-WR-TIMEZONE", timezone)
+	}
+
+	for _, task := range feed.Tasks {
+		todo := ical.Component{Name: "VTODO"}
+		todo.Add("UID", fmt.Sprintf("task-%d@%s", task.ID, calendarDomain))
+		todo.Add("DTSTAMP", ical.DateTime(task.UpdatedAt))
+		todo.Add("CREATED", ical.DateTime(task.CreatedAt))
+		todo.Add("LAST-MODIFIED", ical.DateTime(task.UpdatedAt))
+		todo.AddText("SUMMARY", task.Title)
+		if task.Description != "" {
+			todo.AddText("DESCRIPTION", task.Description)
+		}
+		todo.AddText("CATEGORIES", task.Project.Name)
+
+		due := task.DueDate.In(loc)
+		if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+			todo.AddWithParams("DUE", "VALUE=DATE", ical.Date(due))
+		} else {
+			todo.Add("DUE", ical.DateTime(due))
+		}
+
+		todo.Add("STATUS", todoStatus(task.Status))
+		todo.Add("PRIORITY", strconv.Itoa(todoPriority(task.Priority)))
+		if task.CompletedAt != nil {
+			todo.Add("COMPLETED", ical.DateTime(*task.CompletedAt))
+		}
+
+		calendar.Components = append(calendar.Components, todo)
+	}
+
+	for _, entry := range feed.TimeEntries {
+		event := ical.Component{Name: "VEVENT"}
+		event.Add("UID", fmt.Sprintf("time-entry-%d@%s", entry.ID, calendarDomain))
+		event.Add("DTSTAMP", ical.DateTime(entry.UpdatedAt))
+		event.Add("CREATED", ical.DateTime(entry.CreatedAt))
+		event.Add("LAST-MODIFIED", ical.DateTime(entry.UpdatedAt))
+		event.Add("SEQUENCE", strconv.Itoa(entry.Revision))
+		event.Add("DTSTART", ical.DateTime(entry.StartTime))
+		event.Add("DTEND", ical.DateTime(*entry.EndTime))
+		event.AddText("SUMMARY", entry.TaskTitle)
+		if entry.Description != "" {
+			event.AddText("DESCRIPTION", entry.Description)
+		}
+		event.AddText("CATEGORIES", entry.ProjectName)
+		event.Add("TRANSP", "OPAQUE")
+
+		calendar.Components = append(calendar.Components, event)
+	}
+
+	return calendar
+}
+
+// todoStatus maps a task status to a VTODO STATUS value
+func todoStatus(status types.TaskStatus) string {
+	switch status {
+	case types.TaskStatusInProgress:
+		return "IN-PROCESS"
+	case types.TaskStatusCompleted:
+		return "COMPLETED"
+	case types.TaskStatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// todoPriority maps a task priority, where 10 is the most important, to iCalendar's 1 (highest)
+// to 9 (lowest) scale. Priority 0 is left undefined.
+func todoPriority(priority int) int {
+	if priority <= 0 {
+		return 0
+	}
+	if priority >= 9 {
+		return 1
+	}
+	return 10 - priority
+}
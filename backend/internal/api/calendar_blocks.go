@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/internal/ical"
+	"focused-todo/backend/pkg/types"
+)
+
+// maxCalendarWindowDays limits how many days of recurring events one import expands
+const maxCalendarWindowDays = 366
+
+// handleCalendarImport imports the events of an ICS file, sent either as the request body or as the "file"
+// field of a multipart form, as calendar blocks. Recurring events are expanded between the inclusive from
+// and to dates, which default to the last week and the next ninety days. Importing a calendar again under
+// the same name updates its blocks.
+func (s *Server) handleCalendarImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+
+	calendar := strings.TrimSpace(params.Get("calendar"))
+	if calendar == "" {
+		calendar = "default"
+	}
+	if len(calendar) > 100 {
+		s.writeError(w, http.StatusBadRequest, "calendar must be at most 100 characters")
+		return
+	}
+
+	// Floating event times carry no zone, so they are read in the requested or configured timezone
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	from, until, ok := s.parseCalendarWindow(w, params, loc, -7, 90)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Multipart upload must include a file field")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	parsed, err := ical.Parse(body)
+	if err != nil {
+		log.Printf("Failed to parse calendar import: %v", err)
+		s.writeError(w, http.StatusBadRequest, "Invalid calendar: "+err.Error())
+		return
+	}
+
+	occurrences, err := ical.ExpandEvents(parsed, from, until, loc)
+	if err != nil {
+		log.Printf("Failed to expand calendar events: %v", err)
+		s.writeError(w, http.StatusBadRequest, "Invalid calendar: "+err.Error())
+		return
+	}
+
+	blocks := make([]types.CalendarBlock, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		blocks = append(blocks, types.CalendarBlock{
+			Calendar:  calendar,
+			UID:       occurrence.UID,
+			Summary:   occurrence.Summary,
+			StartTime: occurrence.Start,
+			EndTime:   occurrence.End,
+			AllDay:    occurrence.AllDay,
+		})
+	}
+
+	result, err := s.storage.ImportCalendarBlocks(calendar, blocks, from, until)
+	if err != nil {
+		log.Printf("Failed to import calendar %q: %v", calendar, err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to import calendar")
+		return
+	}
+	localizeCalendarBlocks(result.Blocks, loc)
+
+	response := types.NewAPIResponseWithMessage(*result, "Calendar imported successfully").WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleCalendarBlocks lists the calendar blocks overlapping the inclusive from and to dates, which default to
+// the current week
+func (s *Server) handleCalendarBlocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	from, until, ok := s.parseCalendarWindow(w, r.URL.Query(), loc, 0, 6)
+	if !ok {
+		return
+	}
+
+	blocks, err := s.storage.GetCalendarBlocks(from, until)
+	if err != nil {
+		log.Printf("Failed to get calendar blocks: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve calendar blocks")
+		return
+	}
+	localizeCalendarBlocks(blocks, loc)
+
+	response := types.NewAPIResponse(blocks).WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleCalendarBlockByID deletes a calendar block
+func (s *Server) handleCalendarBlockByID(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/calendar/blocks/
+	path := r.URL.Path[len("/api/calendar/blocks/"):]
+	if path == "" || strings.Contains(path, "/") {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	blockID, err := strconv.Atoi(path)
+	if err != nil || blockID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid calendar block ID")
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := s.storage.DeleteCalendarBlock(blockID); err != nil {
+		log.Printf("Failed to delete calendar block %d: %v", blockID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Calendar block not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete calendar block")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Calendar block deleted successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleCalendarBlockConvert logs calendar blocks as time entries on a task, such as a "Meetings" task
+func (s *Server) handleCalendarBlockConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.ConvertCalendarBlocksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := s.storage.ConvertCalendarBlocks(req.BlockIDs, req.TaskID)
+	if err != nil {
+		log.Printf("Failed to convert calendar blocks: %v", err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		if strings.Contains(err.Error(), "cannot track time") {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to convert calendar blocks")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*result, "Calendar blocks converted")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// parseCalendarWindow reads the inclusive from and to dates, defaulting to the given day offsets from today,
+// and returns them as the half-open range [from, until). It writes an error and returns false when invalid.
+func (s *Server) parseCalendarWindow(w http.ResponseWriter, params url.Values, loc *time.Location, fromDays, toDays int) (time.Time, time.Time, bool) {
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from, to := today.AddDate(0, 0, fromDays), today.AddDate(0, 0, toDays)
+	if fromStr := params.Get("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	if toStr := params.Get("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		s.writeError(w, http.StatusBadRequest, "to must not be before from")
+		return time.Time{}, time.Time{}, false
+	}
+
+	until := to.AddDate(0, 0, 1)
+	if until.After(from.AddDate(0, 0, maxCalendarWindowDays)) {
+		s.writeError(w, http.StatusBadRequest, "from and to must be at most 366 days apart")
+		return time.Time{}, time.Time{}, false
+	}
+	return from, until, true
+}
+
+// localizeCalendarBlocks converts block times to the request's timezone
+func localizeCalendarBlocks(blocks []types.CalendarBlock, loc *time.Location) {
+	for i := range blocks {
+		blocks[i].StartTime = blocks[i].StartTime.In(loc)
+		blocks[i].EndTime = blocks[i].EndTime.In(loc)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	created := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 5, 20, 0, 0, 0, 0, loc)
+	start := time.Date(2024, 5, 13, 7, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+
+	feed := &types.CalendarFeed{
+		Tasks: []types.TaskWithProject{{
+			Task:    types.Task{ID: 7, Title: "Ship, finally", Status: types.TaskStatusInProgress, Priority: 8, DueDate: &due, CreatedAt: created, UpdatedAt: created},
+			Project: types.Project{Name: "Website"},
+		}},
+		TimeEntries: []types.CalendarTimeEntry{{
+			TimeEntry:   types.TimeEntry{ID: 3, StartTime: start, EndTime: &end, CreatedAt: end},
+			UpdatedAt:   end.Add(time.Hour),
+			Revision:    2,
+			TaskTitle:   "Ship, finally",
+			ProjectName: "Website",
+		}},
+	}
+
+	encode := func() string {
+		var b strings.Builder
+		if err := buildCalendar(feed, loc, "Europe/Berlin").Encode(&b); err != nil {
+			t.Fatalf("Failed to encode calendar: %v", err)
+		}
+		return b.String()
+	}
+
+	out := encode()
+	for _, want := range []string{
+		"UID:task-7@focused-todo\r\n",
+		"DUE;VALUE=DATE:20240520\r\n",
+		"STATUS:IN-PROCESS\r\n",
+		"PRIORITY:2\r\n",
+		`SUMMARY:Ship\, finally` + "\r\n",
+		"UID:time-entry-3@focused-todo\r\n",
+		"DTSTART:20240513T070000Z\r\n",
+		"DTEND:20240513T083000Z\r\n",
+		"DTSTAMP:20240513T093000Z\r\n",
+		"LAST-MODIFIED:20240513T093000Z\r\n",
+		"SEQUENCE:2\r\n",
+		"X-WR-TIMEZONE:Europe/Berlin\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected calendar to contain %q", want)
+		}
+	}
+
+	if encode() != out {
+		t.Error("Expected regenerating the calendar to give the same output")
+	}
+
+	var b strings.Builder
+	if err := buildCalendar(feed, time.Local, "").Encode(&b); err != nil {
+		t.Fatalf("Failed to encode calendar: %v", err)
+	}
+	if strings.Contains(b.String(), "X-WR-TIMEZONE") {
+		t.Error("Expected no timezone to be announced without a name")
+	}
+}
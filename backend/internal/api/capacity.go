@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+const (
+	// defaultCapacityDays is how many days the capacity endpoint covers when the request does not say
+	defaultCapacityDays = 14
+
+	// maxCapacityDays is the most days the capacity endpoint covers
+	maxCapacityDays = 90
+)
+
+// handleTimeOff handles listing and adding time off
+func (s *Server) handleTimeOff(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getTimeOff(w, r)
+	case http.MethodPost:
+		s.createTimeOff(w, r)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleTimeOffByID handles deleting time off
+func (s *Server) handleTimeOffByID(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/time-off/
+	path := r.URL.Path[len("/api/time-off/"):]
+	if path == "" || strings.Contains(path, "/") {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	timeOffID, err := strconv.Atoi(path)
+	if err != nil || timeOffID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid time off ID")
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := s.storage.DeleteTimeOff(timeOffID); err != nil {
+		log.Printf("Failed to delete time off %d: %v", timeOffID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Time off not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to delete time off")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Time off deleted successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// getTimeOff returns the time off overlapping the optional from and to dates
+func (s *Server) getTimeOff(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	from, to := params.Get("from"), params.Get("to")
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			s.writeError(w, http.StatusBadRequest, "from and to must be dates in YYYY-MM-DD format")
+			return
+		}
+	}
+
+	entries, err := s.storage.GetTimeOff(from, to)
+	if err != nil {
+		log.Printf("Failed to get time off: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve time off")
+		return
+	}
+
+	response := types.NewAPIResponse(entries)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// createTimeOff adds holidays or leave
+func (s *Server) createTimeOff(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateTimeOffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	timeOff, err := s.storage.CreateTimeOff(req)
+	if err != nil {
+		log.Printf("Failed to create time off: %v", err)
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must not be") {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to create time off")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*timeOff, "Time off created successfully")
+	s.writeJSON(w, http.StatusCreated, response)
+}
+
+// handleCapacity compares the available working time of the coming days with the work due in them. Days
+// follow the configured calendar timezone, as working hours do.
+func (s *Server) handleCapacity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	days := defaultCapacityDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > maxCapacityDays {
+			s.writeError(w, http.StatusBadRequest, "days must be between 1 and 90")
+			return
+		}
+		days = parsed
+	}
+
+	capacity, err := s.storage.GetCapacity(days, time.Now())
+	if err != nil {
+		log.Printf("Failed to get capacity: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to compare capacity with workload")
+		return
+	}
+
+	response := types.NewAPIResponse(*capacity).WithTimezone(capacity.Timezone)
+	s.writeJSON(w, http.StatusOK, response)
+}
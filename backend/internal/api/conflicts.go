@@ -0,0 +1,59 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleTimeEntryConflicts returns time entries on different tasks that overlap, with proposed resolutions
+func (s *Server) handleTimeEntryConflicts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	// Dates are inclusive and default to the last thirty days
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from, to := today.AddDate(0, 0, -29), today
+	if fromStr := params.Get("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+			return
+		}
+		from = parsed
+	}
+	if toStr := params.Get("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+			return
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		s.writeError(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	conflicts, err := s.storage.GetTimeEntryConflicts(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("Failed to get time entry conflicts: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve time entry conflicts")
+		return
+	}
+
+	response := types.NewAPIResponse(conflicts).WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// splitTimeEntry splits a time entry in two at a given time
+func (s *Server) splitTimeEntry(w http.ResponseWriter, r *http.Request, timeEntryID int) {
+	var req types.SplitTimeEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if !s.checkRuleOverride(w, req.Override) {
+		return
+	}
+
+	correction, err := s.storage.SplitTimeEntry(timeEntryID, req)
+	if err != nil {
+		log.Printf("Failed to split time entry %d: %v", timeEntryID, err)
+		s.writeCorrectionError(w, err, "Failed to split time entry")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*correction, "Time entry split successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTimeEntryMerge merges consecutive time entries of one task
+func (s *Server) handleTimeEntryMerge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.MergeTimeEntriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if !s.checkRuleOverride(w, req.Override) {
+		return
+	}
+
+	correction, err := s.storage.MergeTimeEntries(req)
+	if err != nil {
+		log.Printf("Failed to merge time entries: %v", err)
+		s.writeCorrectionError(w, err, "Failed to merge time entries")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*correction, "Time entries merged successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTimeEntryMove reassigns time entries to another task
+func (s *Server) handleTimeEntryMove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.MoveTimeEntriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if !s.checkRuleOverride(w, req.Override) {
+		return
+	}
+
+	correction, err := s.storage.MoveTimeEntries(req)
+	if err != nil {
+		log.Printf("Failed to move time entries to task %d: %v", req.TaskID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		s.writeCorrectionError(w, err, "Failed to move time entries")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*correction, "Time entries moved successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// writeCorrectionError maps errors from splitting, merging and moving time entries to responses
+func (s *Server) writeCorrectionError(w http.ResponseWriter, err error, fallback string) {
+	if s.writeTimeEntryRuleError(w, err) {
+		return
+	}
+
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		s.writeError(w, http.StatusNotFound, "Time entry not found")
+	case strings.Contains(message, "is invoiced"):
+		s.writeError(w, http.StatusConflict, "Time entry is invoiced and cannot be changed")
+	case strings.Contains(message, "must fall within"), strings.Contains(message, "must belong to the same task"),
+		strings.Contains(message, "is running"), strings.Contains(message, "not adjacent"),
+		strings.Contains(message, "more than once"), strings.Contains(message, "billable"),
+		strings.Contains(message, "cannot track time"):
+		s.writeError(w, http.StatusBadRequest, message)
+	default:
+		s.writeError(w, http.StatusInternalServerError, fallback)
+	}
+}
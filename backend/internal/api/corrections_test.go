@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestTimeEntryCorrectionOverride(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	project := createTestProject(t, server)
+	task := createTestTask(t, server, project.ID)
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	var entries []*types.TimeEntry
+	for i := 0; i < 2; i++ {
+		entryStart := start.Add(time.Duration(i) * time.Hour)
+		entryEnd := entryStart.Add(30 * time.Minute)
+		entry, err := server.storage.CreateTimeEntry(types.CreateTimeEntryRequest{
+			TaskID:    task.ID,
+			StartTime: entryStart,
+			EndTime:   &entryEnd,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create time entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		handler http.HandlerFunc
+		body    interface{}
+	}{
+		{
+			name:    "split",
+			path:    fmt.Sprintf("/api/time-entries/%d/split", entries[0].ID),
+			handler: server.handleTimeEntryByID,
+			body:    types.SplitTimeEntryRequest{At: start.Add(15 * time.Minute), Override: true},
+		},
+		{
+			name:    "merge",
+			path:    "/api/time-entries/merge",
+			handler: server.handleTimeEntryMerge,
+			body:    types.MergeTimeEntriesRequest{TimeEntryIDs: []int{entries[0].ID, entries[1].ID}, Override: true},
+		},
+		{
+			name:    "move",
+			path:    "/api/time-entries/move",
+			handler: server.handleTimeEntryMove,
+			body:    types.MoveTimeEntriesRequest{TimeEntryIDs: []int{entries[0].ID}, TaskID: task.ID, Override: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("Failed to marshal request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", tt.path, bytes.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+
+			tt.handler(w, r)
+
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("Expected status 403, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var response types.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Code != "override_not_allowed" {
+				t.Errorf("Expected code override_not_allowed, got %q", response.Code)
+			}
+		})
+	}
+
+	// Nothing was changed by the rejected requests
+	for _, entry := range entries {
+		if _, err := server.storage.GetTimeEntry(entry.ID); err != nil {
+			t.Errorf("Expected time entry %d to be kept: %v", entry.ID, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"focused-todo/backend/internal/importer"
+	"focused-todo/backend/pkg/types"
+)
+
+// handleTimeEntryImport imports a Toggl or Clockify detailed report CSV, sent either as the request body
+// or as the "file" field of a multipart form. Nothing is saved unless commit=true, so clients can show
+// the dry run's duplicates and conflicts before committing. With override=true lines skip the time
+// entry rules, if the server allows it.
+func (s *Server) handleTimeEntryImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+
+	source := types.ImportSource(params.Get("source"))
+	if source != types.ImportSourceToggl && source != types.ImportSourceClockify {
+		s.writeError(w, http.StatusBadRequest, "source must be toggl or clockify")
+		return
+	}
+
+	commit := false
+	if commitStr := params.Get("commit"); commitStr != "" {
+		parsed, err := strconv.ParseBool(commitStr)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "commit must be true or false")
+			return
+		}
+		commit = parsed
+	}
+
+	// Exports are usually back-dated beyond the rules, which only an allowed override skips
+	override := false
+	if overrideStr := params.Get("override"); overrideStr != "" {
+		parsed, err := strconv.ParseBool(overrideStr)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "override must be true or false")
+			return
+		}
+		override = parsed
+	}
+	if !s.checkRuleOverride(w, override) {
+		return
+	}
+
+	// Export times carry no zone, so they are read in the requested or configured timezone
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Multipart upload must include a file field")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	records, err := importer.ParseCSV(body, source, loc)
+	if err != nil {
+		log.Printf("Failed to parse %s import: %v", source, err)
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.storage.ImportTimeEntries(source, records, !commit, override)
+	if err != nil {
+		log.Printf("Failed to import time entries from %s: %v", source, err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to import time entries")
+		return
+	}
+
+	status, message := http.StatusOK, "Dry run completed; nothing was imported"
+	if commit {
+		status, message = http.StatusCreated, "Time entries imported successfully"
+	}
+	response := types.NewAPIResponseWithMessage(*result, message).WithTimezone(loc.String())
+	s.writeJSON(w, status, response)
+}
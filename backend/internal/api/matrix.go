@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleMatrix returns open tasks across projects, or of project_id, in the quadrants of the Eisenhower matrix
+func (s *Server) handleMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var projectID *int
+	if projectIDStr := r.URL.Query().Get("project_id"); projectIDStr != "" {
+		id, err := strconv.Atoi(projectIDStr)
+		if err != nil || id <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		projectID = &id
+	}
+
+	matrix, err := s.storage.GetMatrix(projectID, time.Now())
+	if err != nil {
+		log.Printf("Failed to get matrix: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve matrix")
+		return
+	}
+
+	response := types.NewAPIResponse(*matrix)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleMatrixClassify sets the importance and urgency of tasks in bulk
+func (s *Server) handleMatrixClassify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.ClassifyTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := s.storage.ClassifyTasks(req, time.Now())
+	if err != nil {
+		log.Printf("Failed to classify tasks: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "does not exist"):
+			s.writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "cannot be combined"), strings.Contains(err.Error(), "nothing to change"),
+			strings.Contains(err.Error(), "no tasks"):
+			s.writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			s.writeError(w, http.StatusInternalServerError, "Failed to classify tasks")
+		}
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*result, "Tasks classified successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
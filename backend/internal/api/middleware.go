@@ -121,8 +121,13 @@ func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
 // bodySizeLimitMiddleware limits request body size
 func (s *Server) bodySizeLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Limit request body to 10MB
-		r.Body = http.MaxBytesReader(w, r.Body, 10*1024*1024)
+		// Limit request body to 10MB, rejecting bodies declared larger right away
+		const maxBodySize = 10 * 1024 * 1024
+		if r.ContentLength > maxBodySize {
+			s.writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
 
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"focused-todo/backend/internal/storage"
+	"focused-todo/backend/pkg/types"
+)
+
+// parsePageRequest reads the limit, cursor and include_total query parameters of a list request.
+// Pagination is opt-in: requests with neither a limit nor a cursor get every row, and a cursor without a
+// limit gets pages of the default size.
+func parsePageRequest(r *http.Request) (types.PageRequest, error) {
+	query := r.URL.Query()
+	page := types.PageRequest{Cursor: query.Get("cursor")}
+	if page.Cursor != "" {
+		page.Limit = storage.DefaultPageLimit
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > storage.MaxPageLimit {
+			return page, fmt.Errorf("limit must be an integer between 1 and %d", storage.MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	if totalStr := query.Get("include_total"); totalStr != "" {
+		includeTotal, err := strconv.ParseBool(totalStr)
+		if err != nil {
+			return page, fmt.Errorf("include_total must be a boolean")
+		}
+		page.IncludeTotal = includeTotal
+	}
+
+	return page, nil
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"focused-todo/backend/internal/storage"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		err   bool
+	}{
+		{"no pagination", "", 0, false},
+		{"cursor without limit", "?cursor=abc", storage.DefaultPageLimit, false},
+		{"explicit limit", "?limit=20", 20, false},
+		{"zero limit", "?limit=0", 0, true},
+		{"limit over maximum", "?limit=501", 0, true},
+	}
+	for _, tt := range tests {
+		page, err := parsePageRequest(httptest.NewRequest("GET", "/api/projects"+tt.query, nil))
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if !tt.err && page.Limit != tt.limit {
+			t.Errorf("%s: expected limit %d, got %d", tt.name, tt.limit, page.Limit)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handlePlannedBlocks handles listing and creating planned blocks
+func (s *Server) handlePlannedBlocks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getPlannedBlocks(w, r)
+	case http.MethodPost:
+		s.createPlannedBlock(w, r)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handlePlannedBlockByID handles individual planned block operations
+func (s *Server) handlePlannedBlockByID(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/planned-blocks/
+	path := r.URL.Path[len("/api/planned-blocks/"):]
+	if path == "" || strings.Contains(path, "/") {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	blockID, err := strconv.Atoi(path)
+	if err != nil || blockID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid planned block ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getPlannedBlock(w, r, blockID)
+	case http.MethodPut:
+		s.updatePlannedBlock(w, r, blockID)
+	case http.MethodDelete:
+		s.deletePlannedBlock(w, r, blockID)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// getPlannedBlocks returns the planned blocks of a week, optionally only those of task_id
+func (s *Server) getPlannedBlocks(w http.ResponseWriter, r *http.Request) {
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	start, ok := s.requestWeek(w, r, time.Now(), loc)
+	if !ok {
+		return
+	}
+
+	var taskID *int
+	if taskIDStr := r.URL.Query().Get("task_id"); taskIDStr != "" {
+		id, err := strconv.Atoi(taskIDStr)
+		if err != nil || id <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid task ID")
+			return
+		}
+		taskID = &id
+	}
+
+	blocks, err := s.storage.GetPlannedBlocks(start, start.AddDate(0, 0, 7), taskID)
+	if err != nil {
+		log.Printf("Failed to get planned blocks: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve planned blocks")
+		return
+	}
+	for i := range blocks {
+		localizePlannedBlock(&blocks[i], loc)
+	}
+
+	response := types.NewAPIResponse(blocks).WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// createPlannedBlock plans time on a task
+func (s *Server) createPlannedBlock(w http.ResponseWriter, r *http.Request) {
+	var req types.CreatePlannedBlockRequest
+	if !s.decodePlannedBlockRequest(w, r, &req) {
+		return
+	}
+
+	block, err := s.storage.CreatePlannedBlock(req)
+	if err != nil {
+		log.Printf("Failed to create planned block: %v", err)
+		s.writePlannedBlockError(w, err, "Failed to create planned block")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*block, "Planned block created successfully")
+	s.writeJSON(w, http.StatusCreated, response)
+}
+
+// getPlannedBlock returns a planned block
+func (s *Server) getPlannedBlock(w http.ResponseWriter, r *http.Request, blockID int) {
+	block, err := s.storage.GetPlannedBlock(blockID)
+	if err != nil {
+		log.Printf("Failed to get planned block %d: %v", blockID, err)
+		s.writePlannedBlockError(w, err, "Failed to retrieve planned block")
+		return
+	}
+
+	response := types.NewAPIResponse(*block)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// updatePlannedBlock replaces a planned block's task, times and note
+func (s *Server) updatePlannedBlock(w http.ResponseWriter, r *http.Request, blockID int) {
+	var req types.CreatePlannedBlockRequest
+	if !s.decodePlannedBlockRequest(w, r, &req) {
+		return
+	}
+
+	block, err := s.storage.UpdatePlannedBlock(blockID, req)
+	if err != nil {
+		log.Printf("Failed to update planned block %d: %v", blockID, err)
+		s.writePlannedBlockError(w, err, "Failed to update planned block")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*block, "Planned block updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// deletePlannedBlock deletes a planned block
+func (s *Server) deletePlannedBlock(w http.ResponseWriter, r *http.Request, blockID int) {
+	if err := s.storage.DeletePlannedBlock(blockID); err != nil {
+		log.Printf("Failed to delete planned block %d: %v", blockID, err)
+		s.writePlannedBlockError(w, err, "Failed to delete planned block")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Planned block deleted successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handlePlanAdherence compares a week's planned blocks with the time actually tracked
+func (s *Server) handlePlanAdherence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	start, ok := s.requestWeek(w, r, now, loc)
+	if !ok {
+		return
+	}
+
+	adherence, err := s.storage.GetPlanAdherence(start, now)
+	if err != nil {
+		log.Printf("Failed to get plan adherence: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to compare planned and tracked time")
+		return
+	}
+
+	response := types.NewAPIResponse(*adherence).WithTimezone(adherence.Timezone)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// decodePlannedBlockRequest reads and validates a planned block payload, writing an error when it is invalid
+func (s *Server) decodePlannedBlockRequest(w http.ResponseWriter, r *http.Request, req *types.CreatePlannedBlockRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return false
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(*req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return false
+	}
+	return true
+}
+
+// writePlannedBlockError maps a planned block storage error to a response
+func (s *Server) writePlannedBlockError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "not found"), strings.Contains(err.Error(), "does not exist"):
+		s.writeError(w, http.StatusNotFound, err.Error())
+	case strings.Contains(err.Error(), "overlaps"):
+		s.writeError(w, http.StatusConflict, err.Error())
+	case strings.Contains(err.Error(), "must be after"), strings.Contains(err.Error(), "longer than"):
+		s.writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.writeError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// localizePlannedBlock converts a planned block's times to the request's timezone
+func localizePlannedBlock(block *types.PlannedBlock, loc *time.Location) {
+	block.StartTime = block.StartTime.In(loc)
+	block.EndTime = block.EndTime.In(loc)
+}
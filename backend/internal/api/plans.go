@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handlePlanByDate handles the daily plan at /api/plans/{date}, where date is YYYY-MM-DD or "today" in the
+// request's timezone, and carrying its unfinished tasks forward at /api/plans/{date}/carry-forward
+func (s *Server) handlePlanByDate(w http.ResponseWriter, r *http.Request) {
+	// Extract path after /api/plans/
+	path := strings.Trim(r.URL.Path[len("/api/plans/"):], "/")
+	parts := strings.Split(path, "/")
+	if path == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "carry-forward") {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	day, err := parsePlanDate(parts[0], loc)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Plan date must be today or a date in YYYY-MM-DD format")
+		return
+	}
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodPost {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		s.carryForward(w, r, day, loc)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getPlan(w, r, day, loc)
+	case http.MethodPut:
+		s.setPlan(w, r, day, loc)
+	case http.MethodDelete:
+		s.clearPlan(w, r, day)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// getPlan returns a day's plan with how it compared with what was completed and tracked
+func (s *Server) getPlan(w http.ResponseWriter, r *http.Request, day time.Time, loc *time.Location) {
+	plan, err := s.storage.GetDailyPlan(day)
+	if err != nil {
+		log.Printf("Failed to get daily plan %s: %v", day.Format("2006-01-02"), err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve daily plan")
+		return
+	}
+
+	response := types.NewAPIResponse(*plan).WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// setPlan replaces a day's plan with the given tasks in order
+func (s *Server) setPlan(w http.ResponseWriter, r *http.Request, day time.Time, loc *time.Location) {
+	var req types.SetDailyPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	plan, err := s.storage.SetDailyPlan(day, req.TaskIDs)
+	if err != nil {
+		log.Printf("Failed to set daily plan %s: %v", day.Format("2006-01-02"), err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "at most") || strings.Contains(err.Error(), "more than once") ||
+			strings.Contains(err.Error(), "cannot be planned") {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to update daily plan")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*plan, "Daily plan updated successfully").WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// clearPlan removes every task from a day's plan
+func (s *Server) clearPlan(w http.ResponseWriter, r *http.Request, day time.Time) {
+	if err := s.storage.ClearDailyPlan(day); err != nil {
+		log.Printf("Failed to clear daily plan %s: %v", day.Format("2006-01-02"), err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to clear daily plan")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Daily plan cleared successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// carryForward moves a day's unfinished tasks to a later plan, the next day's unless the body names one
+func (s *Server) carryForward(w http.ResponseWriter, r *http.Request, day time.Time, loc *time.Location) {
+	var req types.CarryForwardRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+	}
+
+	to := day.AddDate(0, 0, 1)
+	if req.To != "" {
+		parsed, err := parsePlanDate(req.To, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "to must be today or a date in YYYY-MM-DD format")
+			return
+		}
+		to = parsed
+	}
+
+	result, err := s.storage.CarryForward(day, to)
+	if err != nil {
+		log.Printf("Failed to carry daily plan %s forward: %v", day.Format("2006-01-02"), err)
+		if strings.Contains(err.Error(), "must be after") {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to carry daily plan forward")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*result, "Unfinished tasks carried forward").WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// parsePlanDate reads a plan date, YYYY-MM-DD or "today", as midnight in loc
+func parsePlanDate(value string, loc *time.Location) (time.Time, error) {
+	if value == "today" {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/internal/storage"
+	"focused-todo/backend/pkg/types"
+)
+
+// pomodoroTickInterval is how often the engine checks for finished intervals
+const pomodoroTickInterval = time.Second
+
+// pomodoroRecorder records pomodoro work intervals as time entries
+type pomodoroRecorder struct {
+	storage *storage.Storage
+}
+
+// StartWork starts a time entry for the task at the given time
+func (r *pomodoroRecorder) StartWork(taskID int, at time.Time) error {
+	_, err := r.storage.StartTimeEntryAt(types.StartTimeEntryRequest{TaskID: taskID}, at)
+	return err
+}
+
+// StopWork stops the task's time entry at the given time.
+// An entry that was already stopped by hand is not an error.
+func (r *pomodoroRecorder) StopWork(taskID int, at time.Time) error {
+	_, err := r.storage.StopTimeEntryAt(taskID, types.StopTimeEntryRequest{}, at)
+	if err != nil && strings.Contains(err.Error(), "no active time entry") {
+		return nil
+	}
+	return err
+}
+
+// pomodoroDefaults returns the configured default pomodoro settings
+func (s *Server) pomodoroDefaults() types.PomodoroSettings {
+	return types.PomodoroSettings{
+		WorkMinutes:       s.config.PomodoroWorkMinutes,
+		ShortBreakMinutes: s.config.PomodoroShortBreakMinutes,
+		LongBreakMinutes:  s.config.PomodoroLongBreakMinutes,
+		LongBreakEvery:    s.config.PomodoroLongBreakEvery,
+		AutoStart:         s.config.PomodoroAutoStart,
+	}
+}
+
+// handlePomodoro lists all pomodoro sessions
+func (s *Server) handlePomodoro(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	response := types.NewAPIResponse(s.pomodoro.Sessions())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handlePomodoroStart starts a pomodoro session on a task
+func (s *Server) handlePomodoroStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.StartPomodoroRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	session, err := s.pomodoro.Start(req.TaskID, req.Settings)
+	if err != nil {
+		s.writePomodoroError(w, "start", err)
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*session, "Pomodoro started successfully")
+	s.writeJSON(w, http.StatusCreated, response)
+}
+
+// handlePomodoroEvents returns interval transitions after the given sequence number
+func (s *Server) handlePomodoroEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	since := 0
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		parsed, err := strconv.Atoi(sinceStr)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, "since must be a non-negative integer")
+			return
+		}
+		since = parsed
+	}
+
+	response := types.NewAPIResponse(s.pomodoro.Events(since))
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handlePomodoroByTask handles /api/pomodoro/{task_id} and its actions
+func (s *Server) handlePomodoroByTask(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/pomodoro/"), "/")
+
+	taskID, err := strconv.Atoi(pathParts[0])
+	if err != nil || taskID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	if len(pathParts) == 1 {
+		// /api/pomodoro/{task_id}
+		if r.Method != http.MethodGet {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		session, err := s.pomodoro.Get(taskID)
+		if err != nil {
+			s.writePomodoroError(w, "get", err)
+			return
+		}
+
+		response := types.NewAPIResponse(*session)
+		s.writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	if len(pathParts) != 2 {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// /api/pomodoro/{task_id}/{action}
+	var session *types.PomodoroSession
+	switch pathParts[1] {
+	case "stop":
+		if err := s.pomodoro.Stop(taskID); err != nil {
+			s.writePomodoroError(w, "stop", err)
+			return
+		}
+		response := types.NewAPIResponseWithMessage(struct{}{}, "Pomodoro stopped successfully")
+		s.writeJSON(w, http.StatusOK, response)
+		return
+	case "skip":
+		session, err = s.pomodoro.Skip(taskID)
+	case "next":
+		session, err = s.pomodoro.StartNext(taskID)
+	default:
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	if err != nil {
+		s.writePomodoroError(w, pathParts[1], err)
+		return
+	}
+
+	response := types.NewAPIResponse(*session)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// writePomodoroError maps pomodoro engine errors to HTTP responses
+func (s *Server) writePomodoroError(w http.ResponseWriter, action string, err error) {
+	log.Printf("Failed to %s pomodoro: %v", action, err)
+	switch {
+	case strings.Contains(err.Error(), "task not found"):
+		s.writeError(w, http.StatusNotFound, "Task not found")
+	case strings.Contains(err.Error(), "no pomodoro session"):
+		s.writeError(w, http.StatusNotFound, "No pomodoro session for task")
+	case strings.Contains(err.Error(), "already has"),
+		strings.Contains(err.Error(), "only breaks"),
+		strings.Contains(err.Error(), "already running"),
+		strings.Contains(err.Error(), "cannot track time"):
+		s.writeError(w, http.StatusConflict, err.Error())
+	default:
+		s.writeError(w, http.StatusInternalServerError, "Failed to "+action+" pomodoro")
+	}
+}
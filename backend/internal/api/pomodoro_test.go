@@ -0,0 +1,47 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestManualTimerEndsPomodoroSession(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	server.storage.SetGlobalTimer(true)
+	server.pomodoro.SetSingleSession(true)
+
+	project := createTestProject(t, server)
+	first := createTestTask(t, server, project.ID)
+	second := createTestTask(t, server, project.ID)
+
+	if _, err := server.pomodoro.Start(first.ID, nil); err != nil {
+		t.Fatalf("Failed to start pomodoro: %v", err)
+	}
+
+	// Starting a timer by hand on another task stops the session's entry and so ends the session,
+	// which would otherwise take the timer back when its next work interval starts
+	body, err := json.Marshal(types.StartTimeEntryRequest{TaskID: second.ID})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/time-entries/start", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	server.handleTimeEntryStart(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if sessions := server.pomodoro.Sessions(); len(sessions) != 0 {
+		t.Errorf("Expected the displaced pomodoro session to end, got %+v", sessions)
+	}
+}
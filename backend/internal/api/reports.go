@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// reportHTMLTemplate renders a time report as a simple table
+var reportHTMLTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"hours": formatHours,
+	"money": formatMoney,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Time report</title></head>
+<body>
+<h1>Time report</h1>
+<p>{{.From.Format "2006-01-02"}} to {{.To.Format "2006-01-02"}} ({{.Timezone}}), grouped by {{.GroupBy}}</p>
+<table>
+<thead><tr><th>{{.GroupBy}}</th><th>Entries</th><th>Hours</th><th>Billable hours</th><th>Amount</th></tr></thead>
+<tbody>
+{{range .Rows}}<tr><td>{{.Label}}</td><td>{{.Entries}}</td><td>{{hours .Duration}}</td><td>{{hours .BillableDuration}}</td><td>{{money .Amount}}</td></tr>
+{{end}}</tbody>
+<tfoot><tr><th>Total</th><th>{{.TotalEntries}}</th><th>{{hours .TotalDuration}}</th><th>{{hours .TotalBillableDuration}}</th><th>{{money .TotalAmount}}</th></tr></tfoot>
+</table>
+</body>
+</html>
+`))
+
+// handleTimeReport returns tracked time grouped by project, task, day, week or tag as JSON, CSV or HTML
+func (s *Server) handleTimeReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	// Dates are inclusive and default to the last seven days
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from, to := today.AddDate(0, 0, -6), today
+	if fromStr := params.Get("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+			return
+		}
+		from = parsed
+	}
+	if toStr := params.Get("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+			return
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		s.writeError(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	query := types.TimeReportQuery{
+		From:      from,
+		To:        to.AddDate(0, 0, 1),
+		GroupBy:   types.ReportGroupByProject,
+		WeekStart: s.config.FirstDayOfWeek(),
+	}
+
+	if groupBy := params.Get("group_by"); groupBy != "" {
+		switch types.ReportGroupBy(groupBy) {
+		case types.ReportGroupByProject, types.ReportGroupByTask, types.ReportGroupByDay,
+			types.ReportGroupByWeek, types.ReportGroupByTag:
+			query.GroupBy = types.ReportGroupBy(groupBy)
+		default:
+			s.writeError(w, http.StatusBadRequest, "group_by must be one of project, task, day, week or tag")
+			return
+		}
+	}
+
+	if rollupStr := params.Get("rollup"); rollupStr != "" {
+		rollup, err := strconv.ParseBool(rollupStr)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "rollup must be true or false")
+			return
+		}
+		query.Rollup = rollup
+	}
+
+	if projectIDStr := params.Get("project_id"); projectIDStr != "" {
+		projectID, err := strconv.Atoi(projectIDStr)
+		if err != nil || projectID <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		query.ProjectID = &projectID
+	}
+
+	rounding, ok := s.parseRoundingRule(w, params.Get("rounding"), params.Get("rounding_mode"))
+	if !ok {
+		return
+	}
+	query.Rounding = rounding
+
+	format := params.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "html" {
+		s.writeError(w, http.StatusBadRequest, "format must be json, csv or html")
+		return
+	}
+
+	report, err := s.storage.GetTimeReport(query)
+	if err != nil {
+		log.Printf("Failed to get time report: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to generate time report")
+		return
+	}
+
+	switch format {
+	case "csv":
+		s.writeReportCSV(w, report)
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := reportHTMLTemplate.Execute(w, report); err != nil {
+			log.Printf("Error rendering HTML report: %v", err)
+		}
+	default:
+		response := types.NewAPIResponse(*report).WithTimezone(report.Timezone)
+		s.writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// writeReportCSV writes a time report as CSV with a closing total row
+func (s *Server) writeReportCSV(w http.ResponseWriter, report *types.TimeReport) {
+	filename := fmt.Sprintf("time-report-%s-%s.csv", report.From.Format("20060102"), report.To.AddDate(0, 0, -1).Format("20060102"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	writer := csv.NewWriter(w)
+	records := [][]string{{string(report.GroupBy), "label", "project_id", "task_id", "entries", "duration_seconds", "hours", "billable_seconds", "amount"}}
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			row.Key,
+			csvSafe(row.Label),
+			optionalID(row.ProjectID),
+			optionalID(row.TaskID),
+			strconv.Itoa(row.Entries),
+			strconv.Itoa(row.Duration),
+			formatHours(row.Duration),
+			strconv.Itoa(row.BillableDuration),
+			formatMoney(row.Amount),
+		})
+	}
+	records = append(records, []string{"total", "", "", "", strconv.Itoa(report.TotalEntries), strconv.Itoa(report.TotalDuration), formatHours(report.TotalDuration),
+		strconv.Itoa(report.TotalBillableDuration), formatMoney(report.TotalAmount)})
+
+	if err := writer.WriteAll(records); err != nil {
+		log.Printf("Error writing CSV report: %v", err)
+	}
+}
+
+// formatHours formats seconds as decimal hours with two places
+func formatHours(seconds int) string {
+	return strconv.FormatFloat(float64(seconds)/3600, 'f', 2, 64)
+}
+
+// formatMoney formats minor currency units as a decimal amount with two places
+func formatMoney(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// optionalID formats an optional ID, leaving it blank when absent
+func optionalID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+// csvSafe prevents spreadsheet applications from evaluating a cell as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@") {
+		return "'" + value
+	}
+	return value
+}
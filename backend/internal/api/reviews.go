@@ -0,0 +1,61 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleTimeEntryReviews lists dangling time entries that need user review
+func (s *Server) handleTimeEntryReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	reviews, err := s.storage.GetTimeEntryReviews()
+	if err != nil {
+		log.Printf("Failed to get time entry reviews: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve time entry reviews")
+		return
+	}
+
+	response := types.NewAPIResponse(reviews)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTimeEntryReviewByID handles /api/time-entries/reviews/{id}/resolve
+func (s *Server) handleTimeEntryReviewByID(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/time-entries/reviews/"), "/")
+	if len(pathParts) != 2 || pathParts[1] != "resolve" {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	timeEntryID, err := strconv.Atoi(pathParts[0])
+	if err != nil || timeEntryID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid time entry ID")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := s.storage.ResolveTimeEntryReview(timeEntryID); err != nil {
+		log.Printf("Failed to resolve time entry review: %v", err)
+		if strings.Contains(err.Error(), "no open review") {
+			s.writeError(w, http.StatusNotFound, "No open review for time entry")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to resolve time entry review")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(struct{}{}, "Time entry review resolved successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
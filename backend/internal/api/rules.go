@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"focused-todo/backend/internal/storage"
+	"focused-todo/backend/pkg/types"
+)
+
+// getProjectTimeEntryRules returns a project's rule overrides and the rules that apply to it
+func (s *Server) getProjectTimeEntryRules(w http.ResponseWriter, r *http.Request, projectID int) {
+	rules, err := s.storage.GetProjectTimeEntryRules(projectID)
+	if err != nil {
+		log.Printf("Failed to get time entry rules for project %d: %v", projectID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve time entry rules")
+		return
+	}
+
+	response := types.NewAPIResponse(*rules)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// setProjectTimeEntryRules replaces a project's rule overrides
+func (s *Server) setProjectTimeEntryRules(w http.ResponseWriter, r *http.Request, projectID int) {
+	var req types.TimeEntryRulesOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	rules, err := s.storage.SetProjectTimeEntryRules(projectID, req)
+	if err != nil {
+		log.Printf("Failed to set time entry rules for project %d: %v", projectID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to update time entry rules")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*rules, "Time entry rules updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// checkRuleOverride rejects requests that skip the time entry rules unless the server allows it
+func (s *Server) checkRuleOverride(w http.ResponseWriter, override bool) bool {
+	if override && !s.config.AllowRuleOverride {
+		s.writeErrorWithCode(w, http.StatusForbidden, "Overriding time entry rules is not enabled on this server", "override_not_allowed")
+		return false
+	}
+	return true
+}
+
+// writeTimeEntryRuleError writes the code of a broken time entry rule, reporting whether err was one
+func (s *Server) writeTimeEntryRuleError(w http.ResponseWriter, err error) bool {
+	var ruleErr *storage.TimeEntryRuleError
+	if !errors.As(err, &ruleErr) {
+		return false
+	}
+
+	status := http.StatusUnprocessableEntity
+	if ruleErr.Code == types.TimeEntryRuleOverlap || ruleErr.Code == types.TimeEntryRuleCrossOverlap {
+		status = http.StatusConflict
+	}
+	s.writeErrorWithCode(w, status, ruleErr.Message, string(ruleErr.Code))
+	return true
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// setTaskEstimate sets or clears how long a task is expected to take
+func (s *Server) setTaskEstimate(w http.ResponseWriter, r *http.Request, taskID int) {
+	var req types.SetTaskEstimateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	estimate, err := s.storage.SetTaskEstimate(taskID, req.Minutes)
+	if err != nil {
+		log.Printf("Failed to set estimate for task %d: %v", taskID, err)
+		if strings.Contains(err.Error(), "does not exist") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to set task estimate")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*estimate, "Task estimate updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleProposeSchedule places open tasks into the free working time of the coming days, saving the
+// proposal as planned blocks when asked to commit it
+func (s *Server) handleProposeSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.ProposeScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	proposal, err := s.storage.ProposeSchedule(req, time.Now())
+	if err != nil {
+		log.Printf("Failed to propose schedule: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "does not exist"):
+			s.writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "overlaps"):
+			s.writeError(w, http.StatusConflict, err.Error())
+		default:
+			s.writeError(w, http.StatusInternalServerError, "Failed to propose schedule")
+		}
+		return
+	}
+	localizeScheduleProposal(proposal, loc)
+
+	if req.Commit {
+		response := types.NewAPIResponseWithMessage(*proposal, "Schedule committed as planned blocks").WithTimezone(loc.String())
+		s.writeJSON(w, http.StatusCreated, response)
+		return
+	}
+
+	response := types.NewAPIResponse(*proposal).WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// localizeScheduleProposal converts a proposal's times to the request's timezone
+func localizeScheduleProposal(proposal *types.ScheduleProposal, loc *time.Location) {
+	proposal.Start = proposal.Start.In(loc)
+	proposal.End = proposal.End.In(loc)
+	for i := range proposal.Blocks {
+		proposal.Blocks[i].StartTime = proposal.Blocks[i].StartTime.In(loc)
+		proposal.Blocks[i].EndTime = proposal.Blocks[i].EndTime.In(loc)
+	}
+	for i := range proposal.Tasks {
+		proposal.Tasks[i].Finish = proposal.Tasks[i].Finish.In(loc)
+	}
+	for i := range proposal.Committed {
+		localizePlannedBlock(&proposal.Committed[i], loc)
+	}
+}
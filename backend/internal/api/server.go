@@ -13,6 +13,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"focused-todo/backend/internal/config"
+	"focused-todo/backend/internal/pomodoro"
 	"focused-todo/backend/internal/storage"
 	"focused-todo/backend/pkg/types"
 )
@@ -23,17 +24,24 @@ type Server struct {
 	storage   *storage.Storage
 	server    *http.Server
 	validator *validator.Validate
+	pomodoro  *pomodoro.Engine
+	stop      chan struct{}
 }
 
 // NewServer creates a new Server instance
 func NewServer(cfg *config.Config, store *storage.Storage) *Server {
 	v := validator.New()
 
-	return &Server{
+	s := &Server{
 		config:    cfg,
 		storage:   store,
 		validator: v,
+		stop:      make(chan struct{}),
 	}
+	s.pomodoro = pomodoro.NewEngine(pomodoro.SystemClock{}, &pomodoroRecorder{storage: store}, s.pomodoroDefaults())
+	s.pomodoro.SetSingleSession(cfg.GlobalTimer)
+
+	return s
 }
 
 // Start starts the HTTP server
@@ -42,17 +50,86 @@ func (s *Server) Start() error {
 
 	// API routes
 	mux.HandleFunc("/api/health", s.handleHealth)
+	mux.HandleFunc("/api/projects/", s.handleProjectByID)
 	mux.HandleFunc("/api/projects", s.handleProjects)
 	mux.HandleFunc("/api/tasks/reorder", s.handleTasksReorder)
+	mux.HandleFunc("/api/tasks/reviews/summary", s.handleTaskReviewSummary)
+	mux.HandleFunc("/api/tasks/reviews", s.handleTaskReviews)
 	mux.HandleFunc("/api/tasks/", s.handleTaskByID)
 	mux.HandleFunc("/api/tasks", s.handleTasks)
 
+	// Smart view routes
+	mux.HandleFunc("/api/views/", s.handleViews)
+	mux.HandleFunc("/api/timeline", s.handleTimeline)
+
+	// Daily plan routes
+	mux.HandleFunc("/api/plans/", s.handlePlanByDate)
+
+	// Planned block routes
+	mux.HandleFunc("/api/planned-blocks/adherence", s.handlePlanAdherence)
+	mux.HandleFunc("/api/planned-blocks/", s.handlePlannedBlockByID)
+	mux.HandleFunc("/api/planned-blocks", s.handlePlannedBlocks)
+	mux.HandleFunc("/api/schedule/propose", s.handleProposeSchedule)
+	mux.HandleFunc("/api/time-off/", s.handleTimeOffByID)
+	mux.HandleFunc("/api/time-off", s.handleTimeOff)
+	mux.HandleFunc("/api/capacity", s.handleCapacity)
+	mux.HandleFunc("/api/matrix/classify", s.handleMatrixClassify)
+	mux.HandleFunc("/api/matrix", s.handleMatrix)
+
+	// Report routes
+	mux.HandleFunc("/api/reports/time", s.handleTimeReport)
+
+	// Invoice routes
+	mux.HandleFunc("/api/invoices/", s.handleInvoiceByID)
+	mux.HandleFunc("/api/invoices", s.handleInvoices)
+
+	// Calendar routes
+	mux.HandleFunc("/api/calendar.ics", s.handleCalendarFeed)
+	mux.HandleFunc("/api/calendar/imports", s.handleCalendarImport)
+	mux.HandleFunc("/api/calendar/blocks/convert", s.handleCalendarBlockConvert)
+	mux.HandleFunc("/api/calendar/blocks/", s.handleCalendarBlockByID)
+	mux.HandleFunc("/api/calendar/blocks", s.handleCalendarBlocks)
+
+	// Import routes
+	mux.HandleFunc("/api/imports/time-entries", s.handleTimeEntryImport)
+
+	// Budget routes
+	mux.HandleFunc("/api/budgets/alerts/", s.handleBudgetAlertByID)
+	mux.HandleFunc("/api/budgets/alerts", s.handleBudgetAlerts)
+	mux.HandleFunc("/api/budgets/", s.handleBudgetByID)
+	mux.HandleFunc("/api/budgets", s.handleBudgets)
+
 	// Time entry routes
 	mux.HandleFunc("/api/time-entries/start", s.handleTimeEntryStart)
 	mux.HandleFunc("/api/time-entries/active", s.handleTimeEntryActive)
+	mux.HandleFunc("/api/time-entries/merge", s.handleTimeEntryMerge)
+	mux.HandleFunc("/api/time-entries/move", s.handleTimeEntryMove)
+	mux.HandleFunc("/api/time-entries/conflicts", s.handleTimeEntryConflicts)
+	mux.HandleFunc("/api/time-entries/reviews/", s.handleTimeEntryReviewByID)
+	mux.HandleFunc("/api/time-entries/reviews", s.handleTimeEntryReviews)
 	mux.HandleFunc("/api/time-entries/", s.handleTimeEntryByID)
 	mux.HandleFunc("/api/time-entries", s.handleTimeEntries)
 
+	// Timer routes
+	mux.HandleFunc("/api/timer/pause", s.handleTimerPause)
+	mux.HandleFunc("/api/timer/resume", s.handleTimerResume)
+	mux.HandleFunc("/api/timer/heartbeat", s.handleTimerHeartbeat)
+	mux.HandleFunc("/api/timer/idle/", s.handleIdleSpanByID)
+	mux.HandleFunc("/api/timer/idle", s.handleIdleSpans)
+	mux.HandleFunc("/api/timer", s.handleTimer)
+
+	// Pomodoro routes
+	mux.HandleFunc("/api/pomodoro/start", s.handlePomodoroStart)
+	mux.HandleFunc("/api/pomodoro/events", s.handlePomodoroEvents)
+	mux.HandleFunc("/api/pomodoro/", s.handlePomodoroByTask)
+	mux.HandleFunc("/api/pomodoro", s.handlePomodoro)
+
+	// Advance pomodoro sessions in the background
+	go s.pomodoro.Run(pomodoroTickInterval, s.stop)
+
+	// Raise budget alerts for running timers in the background
+	go s.runBudgetChecks(budgetCheckInterval, s.stop)
+
 	// Apply middleware chain (order matters - outermost first)
 	handler := s.loggingMiddleware(
 		s.rateLimitMiddleware(
@@ -73,6 +150,10 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
+	close(s.stop)
+	if s.server == nil {
+		return nil // Never started
+	}
 	return s.server.Shutdown(ctx)
 }
 
@@ -119,6 +200,11 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // writeJSON writes a JSON response
 func (s *Server) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	// Record the configured timezone unless the handler already set the one it used
+	if response, ok := data.(timezoneResponse); ok {
+		data = response.WithTimezone(s.config.Location().String())
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -197,14 +283,24 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 
 // getProjects returns all projects
 func (s *Server) getProjects(w http.ResponseWriter, r *http.Request) {
-	projects, err := s.storage.GetAllProjects()
+	page, err := parsePageRequest(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	projects, err := s.storage.ListProjects(page)
 	if err != nil {
 		log.Printf("Failed to get projects: %v", err)
+		if strings.Contains(err.Error(), "invalid cursor") {
+			s.writeError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve projects")
 		return
 	}
 
-	response := types.NewAPIResponse(projects)
+	response := types.NewPaginatedAPIResponse(projects)
 	s.writeJSON(w, http.StatusOK, response)
 }
 
@@ -265,15 +361,25 @@ func (s *Server) getTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := parsePageRequest(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Get tasks from database
-	tasks, err := s.storage.GetTasksByProject(projectID)
+	tasks, err := s.storage.ListTasksByProject(projectID, page)
 	if err != nil {
 		log.Printf("Failed to get tasks for project %d: %v", projectID, err)
+		if strings.Contains(err.Error(), "invalid cursor") {
+			s.writeError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve tasks")
 		return
 	}
 
-	response := types.NewAPIResponse(tasks)
+	response := types.NewPaginatedAPIResponse(tasks)
 	s.writeJSON(w, http.StatusOK, response)
 }
 
@@ -384,6 +490,37 @@ func (s *Server) handleTaskByID(w http.ResponseWriter, r *http.Request) {
 		default:
 			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
+	} else if len(pathParts) == 2 && pathParts[1] == "tags" {
+		// /api/tasks/{id}/tags
+		switch r.Method {
+		case http.MethodGet:
+			s.getTaskTags(w, r, taskID)
+		case http.MethodPut:
+			s.setTaskTags(w, r, taskID)
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 2 && pathParts[1] == "move" {
+		// /api/tasks/{id}/move
+		if r.Method == http.MethodPost {
+			s.moveTask(w, r, taskID)
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 2 && pathParts[1] == "rate" {
+		// /api/tasks/{id}/rate
+		if r.Method == http.MethodPut {
+			s.setTaskRate(w, r, taskID)
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 2 && pathParts[1] == "estimate" {
+		// /api/tasks/{id}/estimate
+		if r.Method == http.MethodPut {
+			s.setTaskEstimate(w, r, taskID)
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
 	} else {
 		s.writeError(w, http.StatusNotFound, "Endpoint not found")
 	}
@@ -468,6 +605,14 @@ func (s *Server) updateTaskStatus(w http.ResponseWriter, r *http.Request, taskID
 	task, err := s.storage.UpdateTaskStatus(taskID, req.Status)
 	if err != nil {
 		log.Printf("Failed to update task status %d: %v", taskID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		if strings.Contains(err.Error(), "WIP limit") {
+			s.writeErrorWithCode(w, http.StatusConflict, err.Error(), "wip_limit_reached")
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, "Failed to update task status")
 		return
 	}
@@ -531,6 +676,23 @@ func (s *Server) handleTimeEntryByID(w http.ResponseWriter, r *http.Request) {
 		} else {
 			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
+	} else if len(pathParts) == 2 && pathParts[1] == "split" {
+		// /api/time-entries/{id}/split
+		if r.Method == http.MethodPost {
+			s.splitTimeEntry(w, r, timeEntryID)
+		} else {
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	} else if len(pathParts) == 2 && pathParts[1] == "billing" {
+		// /api/time-entries/{id}/billing
+		switch r.Method {
+		case http.MethodGet:
+			s.getTimeEntryBilling(w, r, timeEntryID)
+		case http.MethodPut:
+			s.setTimeEntryBillable(w, r, timeEntryID)
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
 	} else {
 		s.writeError(w, http.StatusNotFound, "Endpoint not found")
 	}
@@ -554,28 +716,48 @@ func (s *Server) handleTimeEntryActive(w http.ResponseWriter, r *http.Request) {
 	s.getActiveTimeEntry(w, r)
 }
 
-// getTimeEntries returns time entries for a task
+// getTimeEntries returns time entries for a task or a project
 func (s *Server) getTimeEntries(w http.ResponseWriter, r *http.Request) {
 	taskIDStr := r.URL.Query().Get("task_id")
-	if taskIDStr == "" {
-		s.writeError(w, http.StatusBadRequest, "task_id parameter is required")
+	projectIDStr := r.URL.Query().Get("project_id")
+	if taskIDStr == "" && projectIDStr == "" {
+		s.writeError(w, http.StatusBadRequest, "task_id or project_id parameter is required")
 		return
 	}
 
-	taskID, err := strconv.Atoi(taskIDStr)
-	if err != nil || taskID <= 0 {
-		s.writeError(w, http.StatusBadRequest, "task_id must be a positive integer")
+	page, err := parsePageRequest(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	entries, err := s.storage.GetTimeEntriesByTask(taskID)
+	var entries types.Page[types.TimeEntry]
+	if taskIDStr != "" {
+		taskID, parseErr := strconv.Atoi(taskIDStr)
+		if parseErr != nil || taskID <= 0 {
+			s.writeError(w, http.StatusBadRequest, "task_id must be a positive integer")
+			return
+		}
+		entries, err = s.storage.ListTimeEntriesByTask(taskID, page)
+	} else {
+		projectID, parseErr := strconv.Atoi(projectIDStr)
+		if parseErr != nil || projectID <= 0 {
+			s.writeError(w, http.StatusBadRequest, "project_id must be a positive integer")
+			return
+		}
+		entries, err = s.storage.ListTimeEntriesByProject(projectID, page)
+	}
 	if err != nil {
-		log.Printf("Failed to get time entries for task %d: %v", taskID, err)
+		log.Printf("Failed to get time entries: %v", err)
+		if strings.Contains(err.Error(), "invalid cursor") {
+			s.writeError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve time entries")
 		return
 	}
 
-	response := types.NewAPIResponse(entries)
+	response := types.NewPaginatedAPIResponse(entries)
 	s.writeJSON(w, http.StatusOK, response)
 }
 
@@ -597,10 +779,17 @@ func (s *Server) createTimeEntry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.checkRuleOverride(w, req.Override) {
+		return
+	}
+
 	// Create time entry in database
 	entry, err := s.storage.CreateTimeEntry(req)
 	if err != nil {
 		log.Printf("Failed to create time entry: %v", err)
+		if s.writeTimeEntryRuleError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "task not found") {
 			s.writeError(w, http.StatusNotFound, "Task not found")
 			return
@@ -609,6 +798,11 @@ func (s *Server) createTimeEntry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A running entry displaces the pomodoro sessions of other tasks like starting a timer does
+	if entry.EndTime == nil {
+		s.pomodoro.Displace(entry.TaskID)
+	}
+
 	response := types.NewAPIResponseWithMessage(*entry, "Time entry created successfully")
 	s.writeJSON(w, http.StatusCreated, response)
 }
@@ -646,6 +840,10 @@ func (s *Server) updateTimeEntry(w http.ResponseWriter, r *http.Request, timeEnt
 		return
 	}
 
+	if !s.checkRuleOverride(w, req.Override) {
+		return
+	}
+
 	// Get the existing time entry to merge updates
 	existing, err := s.storage.GetTimeEntry(timeEntryID)
 	if err != nil {
@@ -660,6 +858,7 @@ func (s *Server) updateTimeEntry(w http.ResponseWriter, r *http.Request, timeEnt
 		StartTime:   existing.StartTime,
 		EndTime:     existing.EndTime,
 		Description: existing.Description,
+		Override:    req.Override,
 	}
 
 	// Apply updates if provided
@@ -677,10 +876,17 @@ func (s *Server) updateTimeEntry(w http.ResponseWriter, r *http.Request, timeEnt
 	entry, err := s.storage.UpdateTimeEntry(timeEntryID, updateReq)
 	if err != nil {
 		log.Printf("Failed to update time entry %d: %v", timeEntryID, err)
+		if s.writeTimeEntryRuleError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			s.writeError(w, http.StatusNotFound, "Time entry not found")
 			return
 		}
+		if strings.Contains(err.Error(), "is invoiced") {
+			s.writeError(w, http.StatusConflict, "Time entry is invoiced and cannot be changed")
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, "Failed to update time entry")
 		return
 	}
@@ -698,6 +904,10 @@ func (s *Server) deleteTimeEntry(w http.ResponseWriter, r *http.Request, timeEnt
 			s.writeError(w, http.StatusNotFound, "Time entry not found")
 			return
 		}
+		if strings.Contains(err.Error(), "is invoiced") {
+			s.writeError(w, http.StatusConflict, "Time entry is invoiced and cannot be changed")
+			return
+		}
 		s.writeError(w, http.StatusInternalServerError, "Failed to delete time entry")
 		return
 	}
@@ -728,6 +938,9 @@ func (s *Server) startTimeEntry(w http.ResponseWriter, r *http.Request) {
 	entry, err := s.storage.StartTimeEntry(req)
 	if err != nil {
 		log.Printf("Failed to start time entry: %v", err)
+		if s.writeTimeEntryRuleError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "task not found") {
 			s.writeError(w, http.StatusNotFound, "Task not found")
 			return
@@ -740,7 +953,14 @@ func (s *Server) startTimeEntry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := types.NewAPIResponseWithMessage(*entry, "Time tracking started successfully")
+	// In global timer mode the entries of other tasks were stopped and their pomodoro sessions with them
+	s.pomodoro.Displace(entry.TaskID)
+
+	message := "Time tracking started successfully"
+	if len(entry.BudgetWarnings) > 0 {
+		message = "Time tracking started, but a budget for this task is exhausted"
+	}
+	response := types.NewAPIResponseWithMessage(*entry, message)
 	s.writeJSON(w, http.StatusCreated, response)
 }
 
@@ -775,6 +995,9 @@ func (s *Server) stopTimeEntry(w http.ResponseWriter, r *http.Request, timeEntry
 		return
 	}
 
+	// Stopping the entry by hand ends the task's pomodoro session, which would otherwise track it again
+	s.pomodoro.Release(entry.TaskID)
+
 	response := types.NewAPIResponseWithMessage(*entry, "Time tracking stopped successfully")
 	s.writeJSON(w, http.StatusOK, response)
 }
@@ -36,20 +36,13 @@ func setupTestServer(t *testing.T) (*Server, func()) {
 
 	// Create server config
 	cfg := &config.Config{
-		Port:           8080,
-		DatabasePath:   tmpfile.Name(),
-		LogLevel:       "error", // Reduce log noise in tests
-		RateLimitRPS:   1000,    // High limit for tests
-		MaxRequestSize: 1024 * 1024,
+		Port:         8080,
+		DatabasePath: tmpfile.Name(),
+		LogLevel:     "error", // Reduce log noise in tests
 	}
 
 	// Create server
-	server, err := New(cfg, store)
-	if err != nil {
-		store.Close()
-		os.Remove(tmpfile.Name())
-		t.Fatalf("Failed to create server: %v", err)
-	}
+	server := NewServer(cfg, store)
 
 	// Return cleanup function
 	cleanup := func() {
@@ -361,6 +354,30 @@ func TestTasksAPI(t *testing.T) {
 		}
 	})
 
+	t.Run("POST create task with out of range priority", func(t *testing.T) {
+		req := types.CreateTaskRequest{
+			ProjectID:   project.ID,
+			Title:       "Invalid Priority Task",
+			Description: "A test task",
+			Priority:    11, // Invalid: priorities run from 0 to 10
+		}
+
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/tasks", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+
+		server.handleTasks(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
 	t.Run("unsupported method", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest("PUT", "/api/tasks", nil)
@@ -384,9 +401,9 @@ func TestTasksReorderAPI(t *testing.T) {
 
 	t.Run("POST reorder tasks", func(t *testing.T) {
 		req := types.ReorderTasksRequest{
-			Tasks: []types.ReorderTaskRequest{
-				{ID: task2.ID, Position: 1},
-				{ID: task1.ID, Position: 2},
+			Tasks: []types.TaskOrder{
+				{TaskID: task2.ID, Priority: 1},
+				{TaskID: task1.ID, Priority: 2},
 			},
 		}
 
@@ -408,8 +425,8 @@ func TestTasksReorderAPI(t *testing.T) {
 
 	t.Run("POST reorder with invalid data", func(t *testing.T) {
 		req := types.ReorderTasksRequest{
-			Tasks: []types.ReorderTaskRequest{
-				{ID: 99999, Position: 1}, // Invalid: nonexistent task
+			Tasks: []types.TaskOrder{
+				{TaskID: 99999, Priority: 1}, // Invalid: nonexistent task
 			},
 		}
 
@@ -475,7 +492,7 @@ func TestMiddlewares(t *testing.T) {
 
 	t.Run("Request size limit", func(t *testing.T) {
 		// Create a request with large body
-		largeBody := strings.Repeat("x", 2*1024*1024) // 2MB
+		largeBody := strings.Repeat("x", 11*1024*1024) // 11MB
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest("POST", "/api/projects", strings.NewReader(largeBody))
@@ -588,4 +605,4 @@ func TestInputSanitization(t *testing.T) {
 			t.Errorf("Expected script tags to be sanitized, got '%s'", response.Data.Name)
 		}
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// getTaskTags returns the tags of a task
+func (s *Server) getTaskTags(w http.ResponseWriter, r *http.Request, taskID int) {
+	tags, err := s.storage.GetTaskTags(taskID)
+	if err != nil {
+		log.Printf("Failed to get tags for task %d: %v", taskID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve task tags")
+		return
+	}
+
+	response := types.NewAPIResponse(tags)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// setTaskTags replaces the tags of a task
+func (s *Server) setTaskTags(w http.ResponseWriter, r *http.Request, taskID int) {
+	var req types.SetTaskTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Sanitize input fields
+	for i, tag := range req.Tags {
+		req.Tags[i] = sanitizeInput(tag)
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	tags, err := s.storage.SetTaskTags(taskID, req.Tags)
+	if err != nil {
+		log.Printf("Failed to set tags for task %d: %v", taskID, err)
+		if strings.Contains(err.Error(), "not found") {
+			s.writeError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, "Failed to update task tags")
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(tags, "Task tags updated successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
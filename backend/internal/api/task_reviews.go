@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleTaskReviews handles the stale task review queue and reviewing tasks in batch
+func (s *Server) handleTaskReviews(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getReviewQueue(w, r)
+	case http.MethodPost:
+		s.reviewTasks(w, r)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// getReviewQueue returns the open tasks without activity for days, the configured stale task days by
+// default, optionally only those of project_id
+func (s *Server) getReviewQueue(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	days := 0
+	if daysStr := params.Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > 365 {
+			s.writeError(w, http.StatusBadRequest, "days must be between 1 and 365")
+			return
+		}
+		days = parsed
+	}
+
+	var projectID *int
+	if projectIDStr := params.Get("project_id"); projectIDStr != "" {
+		id, err := strconv.Atoi(projectIDStr)
+		if err != nil || id <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		projectID = &id
+	}
+
+	queue, err := s.storage.GetReviewQueue(days, projectID, time.Now())
+	if err != nil {
+		log.Printf("Failed to get review queue: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve review queue")
+		return
+	}
+
+	response := types.NewAPIResponse(*queue)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// reviewTasks marks tasks reviewed, defers, cancels or bumps them in batch
+func (s *Server) reviewTasks(w http.ResponseWriter, r *http.Request) {
+	var req types.ReviewTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := s.storage.ReviewTasks(req, time.Now())
+	if err != nil {
+		log.Printf("Failed to review tasks: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "does not exist"):
+			s.writeError(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "defer_until"), strings.Contains(err.Error(), "cannot be reviewed"):
+			s.writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			s.writeError(w, http.StatusInternalServerError, "Failed to review tasks")
+		}
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*result, "Tasks reviewed successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTaskReviewSummary summarises a week of task reviews
+func (s *Server) handleTaskReviewSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	start, ok := s.requestWeek(w, r, now, loc)
+	if !ok {
+		return
+	}
+
+	summary, err := s.storage.GetReviewSummary(start, now)
+	if err != nil {
+		log.Printf("Failed to get review summary: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to summarise task reviews")
+		return
+	}
+
+	response := types.NewAPIResponse(*summary).WithTimezone(summary.Timezone)
+	s.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"focused-todo/backend/internal/storage"
+	"focused-todo/backend/pkg/types"
+)
+
+// handleTimeline returns a week of tracked time split per day and project
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	start, ok := s.requestWeek(w, r, now, loc)
+	if !ok {
+		return
+	}
+
+	timeline, err := s.storage.GetWeekTimeline(start, now)
+	if err != nil {
+		log.Printf("Failed to get timeline: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve timeline")
+		return
+	}
+
+	response := types.NewAPIResponse(*timeline).WithTimezone(timeline.Timezone)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// requestWeek returns the start of the week given by the week query parameter, an ISO week such as
+// 2024-W05, or of the week containing now. It writes an error and returns false when the week is invalid.
+func (s *Server) requestWeek(w http.ResponseWriter, r *http.Request, now time.Time, loc *time.Location) (time.Time, bool) {
+	firstDay := s.config.FirstDayOfWeek()
+	week := r.URL.Query().Get("week")
+	if week == "" {
+		return storage.StartOfWeek(now, loc, firstDay), true
+	}
+
+	monday, err := parseISOWeek(week, loc)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "week must be an ISO week such as 2024-W05")
+		return time.Time{}, false
+	}
+	// Weeks starting on another day begin before the ISO week's Monday
+	return storage.StartOfWeek(monday, loc, firstDay), true
+}
+
+// parseISOWeek returns the Monday starting an ISO week written as YYYY-Www
+func parseISOWeek(week string, loc *time.Location) (time.Time, error) {
+	var year, number int
+	if _, err := fmt.Sscanf(week, "%4d-W%2d", &year, &number); err != nil || len(week) != 8 {
+		return time.Time{}, fmt.Errorf("invalid ISO week %q", week)
+	}
+
+	// January 4th always falls in the first ISO week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7+(number-1)*7)
+
+	if y, n := monday.ISOWeek(); y != year || n != number {
+		return time.Time{}, fmt.Errorf("invalid ISO week %q", week)
+	}
+
+	return monday, nil
+}
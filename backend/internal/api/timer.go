@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleTimer returns the single running timer with its task and project
+func (s *Server) handleTimer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	timer, err := s.storage.GetRunningTimer()
+	if err != nil {
+		log.Printf("Failed to get running timer: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve running timer")
+		return
+	}
+
+	// Data is null when no timer is running
+	response := types.NewAPIResponse(timer)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTimerPause pauses the running timer
+func (s *Server) handleTimerPause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	timer, err := s.storage.PauseTimer()
+	if err != nil {
+		s.writeTimerError(w, "pause", err)
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*timer, "Timer paused successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTimerResume resumes the paused timer
+func (s *Server) handleTimerResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	timer, err := s.storage.ResumeTimer()
+	if err != nil {
+		s.writeTimerError(w, "resume", err)
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*timer, "Timer resumed successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleTimerHeartbeat records a client heartbeat with the user's idle time
+func (s *Server) handleTimerHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	timer, err := s.storage.RecordHeartbeat(req, s.config.IdleThreshold())
+	if err != nil {
+		s.writeTimerError(w, "heartbeat", err)
+		return
+	}
+
+	response := types.NewAPIResponse(*timer)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleIdleSpans lists the unresolved idle spans of all time entries
+func (s *Server) handleIdleSpans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	spans, err := s.storage.GetIdleSpans()
+	if err != nil {
+		log.Printf("Failed to get idle spans: %v", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve idle spans")
+		return
+	}
+
+	response := types.NewAPIResponse(spans)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleIdleSpanByID handles /api/timer/idle/{id}/resolve
+func (s *Server) handleIdleSpanByID(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/timer/idle/"), "/")
+	if len(pathParts) != 2 || pathParts[1] != "resolve" {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found")
+		return
+	}
+
+	spanID, err := strconv.Atoi(pathParts[0])
+	if err != nil || spanID <= 0 {
+		s.writeError(w, http.StatusBadRequest, "Invalid idle span ID")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req types.ResolveIdleSpanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate the request
+	if validationErrors := s.validateRequest(req); validationErrors != nil {
+		response := types.NewErrorResponseWithDetails("Validation failed", "validation_error", validationErrors)
+		s.writeJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	resolution, err := s.storage.ResolveIdleSpan(spanID, req.Resolution)
+	if err != nil {
+		log.Printf("Failed to resolve idle span: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			s.writeError(w, http.StatusNotFound, "Idle span not found")
+		case strings.Contains(err.Error(), "already resolved"):
+			s.writeError(w, http.StatusConflict, err.Error())
+		case strings.Contains(err.Error(), "invoiced"):
+			s.writeError(w, http.StatusConflict, "Time entry is invoiced and cannot be changed")
+		default:
+			s.writeError(w, http.StatusInternalServerError, "Failed to resolve idle span")
+		}
+		return
+	}
+
+	response := types.NewAPIResponseWithMessage(*resolution, "Idle span resolved successfully")
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// writeTimerError maps timer state errors to HTTP responses
+func (s *Server) writeTimerError(w http.ResponseWriter, action string, err error) {
+	log.Printf("Failed to %s timer: %v", action, err)
+	switch {
+	case strings.Contains(err.Error(), "no running timer"):
+		s.writeError(w, http.StatusNotFound, "No running timer")
+	case strings.Contains(err.Error(), "already paused"), strings.Contains(err.Error(), "not paused"):
+		s.writeError(w, http.StatusConflict, err.Error())
+	default:
+		s.writeError(w, http.StatusInternalServerError, "Failed to "+action+" timer")
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// timezoneResponse is implemented by API responses that can record the timezone used to build them
+type timezoneResponse interface {
+	WithTimezone(timezone string) interface{}
+}
+
+// requestLocation returns the timezone named by the tz query parameter, or the configured timezone.
+// It writes an error response and returns false when the parameter is invalid.
+func (s *Server) requestLocation(w http.ResponseWriter, r *http.Request) (*time.Location, bool) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return s.config.Location(), true
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid timezone")
+		return nil, false
+	}
+	return loc, true
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// handleViews serves the built-in smart views at /api/views/{name}
+func (s *Server) handleViews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	view := types.SmartView(strings.TrimPrefix(r.URL.Path, "/api/views/"))
+	switch view {
+	case types.SmartViewOverdue, types.SmartViewToday, types.SmartViewUpcoming,
+		types.SmartViewInProgress, types.SmartViewCompleted:
+	default:
+		s.writeError(w, http.StatusNotFound, "View not found")
+		return
+	}
+
+	// Window in days for the upcoming and completed views
+	days := 7
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 || parsed > 365 {
+			s.writeError(w, http.StatusBadRequest, "days must be an integer between 1 and 365")
+			return
+		}
+		days = parsed
+	}
+
+	loc, ok := s.requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	tasks, err := s.storage.GetSmartView(view, time.Now(), loc, days)
+	if err != nil {
+		log.Printf("Failed to get %s view: %v", view, err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to retrieve view")
+		return
+	}
+
+	response := types.NewAPIResponse(tasks).WithTimezone(loc.String())
+	s.writeJSON(w, http.StatusOK, response)
+}
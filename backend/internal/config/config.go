@@ -5,6 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
 )
 
 // Config holds the application configuration
@@ -12,13 +16,89 @@ type Config struct {
 	Port         int    `json:"port"`
 	DatabasePath string `json:"database_path"`
 	LogLevel     string `json:"log_level"`
+	Timezone     string `json:"timezone"`     // IANA zone name used for day boundaries
+	WeekStart    string `json:"week_start"`   // First day of the week, e.g. monday or sunday
+	GlobalTimer  bool   `json:"global_timer"` // Starting a timer stops any other running timer
+	NoOverlap    bool   `json:"no_overlap"`   // Time entries may not overlap entries on other tasks
+
+	// Pomodoro defaults
+	PomodoroWorkMinutes       int  `json:"pomodoro_work_minutes"`
+	PomodoroShortBreakMinutes int  `json:"pomodoro_short_break_minutes"`
+	PomodoroLongBreakMinutes  int  `json:"pomodoro_long_break_minutes"`
+	PomodoroLongBreakEvery    int  `json:"pomodoro_long_break_every"`
+	PomodoroAutoStart         bool `json:"pomodoro_auto_start"`
+
+	// Reconciliation of time entries left running by a crash
+	DanglingPolicy         string `json:"dangling_policy"`          // heartbeat, cap or review
+	DanglingThresholdHours int    `json:"dangling_threshold_hours"` // Hours without activity before an entry is dangling
+	DanglingCapHours       int    `json:"dangling_cap_hours"`       // Maximum duration used by the cap policy
+
+	IdleThresholdMinutes int `json:"idle_threshold_minutes"` // Idle time reported by a heartbeat that marks an idle span
+
+	// Default time entry rules, which projects may override. Zero disables the back-dating and maximum duration limits.
+	MaxBackdateDays   int  `json:"max_backdate_days"`
+	MaxEntryMinutes   int  `json:"max_entry_minutes"`
+	MinEntrySeconds   int  `json:"min_entry_seconds"`
+	ClockSkewSeconds  int  `json:"clock_skew_seconds"`
+	AllowRuleOverride bool `json:"allow_rule_override"` // Allow requests to skip the time entry rules, e.g. for bulk imports
+
+	BudgetThresholds []int `json:"budget_thresholds"` // Percentages of a budget that raise alerts unless the budget sets its own
+
+	CalendarToken string `json:"-"` // Secret that calendar clients pass to read the ICS feed; empty disables the feed
+
+	DailyPlanSlots int `json:"daily_plan_slots"` // Most tasks a daily plan may hold
+
+	// Scheduling
+	WorkingHours       string `json:"working_hours"`        // E.g. "mon-fri 09:00-12:00,13:00-17:00; sat 10:00-14:00"
+	DefaultTaskMinutes int    `json:"default_task_minutes"` // Time planned for tasks without an estimate
+
+	// Eisenhower matrix
+	ImportantPriority int  `json:"important_priority"` // Unclassified tasks of at least this priority are important, 11 for none
+	DeriveUrgency     bool `json:"derive_urgency"`     // Unclassified tasks are urgent when due soon
+	UrgentWithinDays  int  `json:"urgent_within_days"` // Days ahead of today a due date makes a task urgent
+
+	// Reviews
+	StaleTaskDays int `json:"stale_task_days"` // Days without changes or tracked time after which open tasks need review
 }
 
 // Load reads configuration from environment variables and returns a Config
 func Load() (*Config, error) {
 	cfg := &Config{
-		Port:     8080, // Default port
-		LogLevel: "info",
+		Port:        8080, // Default port
+		LogLevel:    "info",
+		Timezone:    "Local",
+		WeekStart:   "monday",
+		GlobalTimer: true,
+
+		PomodoroWorkMinutes:       25,
+		PomodoroShortBreakMinutes: 5,
+		PomodoroLongBreakMinutes:  15,
+		PomodoroLongBreakEvery:    4,
+		PomodoroAutoStart:         true,
+
+		DanglingPolicy:         string(types.DanglingPolicyReview),
+		DanglingThresholdHours: 12,
+		DanglingCapHours:       8,
+
+		IdleThresholdMinutes: 5,
+
+		MaxBackdateDays:  30,
+		MaxEntryMinutes:  24 * 60,
+		MinEntrySeconds:  60,
+		ClockSkewSeconds: 5 * 60,
+
+		BudgetThresholds: []int{80, 100},
+
+		DailyPlanSlots: 5,
+
+		WorkingHours:       "mon-fri 09:00-17:00",
+		DefaultTaskMinutes: 60,
+
+		ImportantPriority: 7,
+		DeriveUrgency:     true,
+		UrgentWithinDays:  2,
+
+		StaleTaskDays: 14,
 	}
 
 	// Read port from environment
@@ -35,6 +115,194 @@ func Load() (*Config, error) {
 		cfg.LogLevel = logLevel
 	}
 
+	// Read timezone from environment
+	if tz := os.Getenv("FOCUSED_TODO_TIMEZONE"); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		cfg.Timezone = tz
+	}
+
+	// Read first day of the week from environment
+	if weekStart := os.Getenv("FOCUSED_TODO_WEEK_START"); weekStart != "" {
+		if _, ok := parseWeekday(weekStart); !ok {
+			return nil, fmt.Errorf("invalid week start %q: must be a day of the week", weekStart)
+		}
+		cfg.WeekStart = strings.ToLower(weekStart)
+	}
+
+	// Read global timer mode from environment
+	if globalTimer := os.Getenv("FOCUSED_TODO_GLOBAL_TIMER"); globalTimer != "" {
+		enabled, err := strconv.ParseBool(globalTimer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid global timer setting: %w", err)
+		}
+		cfg.GlobalTimer = enabled
+	}
+
+	// Read global no-overlap policy from environment
+	if noOverlap := os.Getenv("FOCUSED_TODO_NO_OVERLAP"); noOverlap != "" {
+		enabled, err := strconv.ParseBool(noOverlap)
+		if err != nil {
+			return nil, fmt.Errorf("invalid no overlap setting: %w", err)
+		}
+		cfg.NoOverlap = enabled
+	}
+
+	// Read pomodoro defaults from environment
+	pomodoroMinutes := map[string]*int{
+		"FOCUSED_TODO_POMODORO_WORK_MINUTES":        &cfg.PomodoroWorkMinutes,
+		"FOCUSED_TODO_POMODORO_SHORT_BREAK_MINUTES": &cfg.PomodoroShortBreakMinutes,
+		"FOCUSED_TODO_POMODORO_LONG_BREAK_MINUTES":  &cfg.PomodoroLongBreakMinutes,
+		"FOCUSED_TODO_POMODORO_LONG_BREAK_EVERY":    &cfg.PomodoroLongBreakEvery,
+	}
+	for name, target := range pomodoroMinutes {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid value for %s: must be a positive integer", name)
+			}
+			*target = parsed
+		}
+	}
+
+	if autoStart := os.Getenv("FOCUSED_TODO_POMODORO_AUTO_START"); autoStart != "" {
+		enabled, err := strconv.ParseBool(autoStart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pomodoro auto start setting: %w", err)
+		}
+		cfg.PomodoroAutoStart = enabled
+	}
+
+	// Read dangling time entry reconciliation settings from environment
+	if policy := os.Getenv("FOCUSED_TODO_DANGLING_POLICY"); policy != "" {
+		switch types.DanglingPolicy(policy) {
+		case types.DanglingPolicyHeartbeat, types.DanglingPolicyCap, types.DanglingPolicyReview:
+			cfg.DanglingPolicy = policy
+		default:
+			return nil, fmt.Errorf("invalid dangling policy %q: must be heartbeat, cap or review", policy)
+		}
+	}
+
+	danglingHours := map[string]*int{
+		"FOCUSED_TODO_DANGLING_THRESHOLD_HOURS": &cfg.DanglingThresholdHours,
+		"FOCUSED_TODO_DANGLING_CAP_HOURS":       &cfg.DanglingCapHours,
+	}
+	for name, target := range danglingHours {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid value for %s: must be a positive integer", name)
+			}
+			*target = parsed
+		}
+	}
+
+	if idleStr := os.Getenv("FOCUSED_TODO_IDLE_THRESHOLD_MINUTES"); idleStr != "" {
+		idle, err := strconv.Atoi(idleStr)
+		if err != nil || idle <= 0 {
+			return nil, fmt.Errorf("invalid idle threshold: must be a positive number of minutes")
+		}
+		cfg.IdleThresholdMinutes = idle
+	}
+
+	// Read time entry rules from environment
+	entryRules := map[string]*int{
+		"FOCUSED_TODO_MAX_BACKDATE_DAYS":  &cfg.MaxBackdateDays,
+		"FOCUSED_TODO_MAX_ENTRY_MINUTES":  &cfg.MaxEntryMinutes,
+		"FOCUSED_TODO_MIN_ENTRY_SECONDS":  &cfg.MinEntrySeconds,
+		"FOCUSED_TODO_CLOCK_SKEW_SECONDS": &cfg.ClockSkewSeconds,
+	}
+	for name, target := range entryRules {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("invalid value for %s: must be a non-negative integer", name)
+			}
+			*target = parsed
+		}
+	}
+
+	if allowOverride := os.Getenv("FOCUSED_TODO_ALLOW_RULE_OVERRIDE"); allowOverride != "" {
+		enabled, err := strconv.ParseBool(allowOverride)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule override setting: %w", err)
+		}
+		cfg.AllowRuleOverride = enabled
+	}
+
+	// Read budget alert thresholds as a comma separated list of percentages
+	if thresholds := os.Getenv("FOCUSED_TODO_BUDGET_THRESHOLDS"); thresholds != "" {
+		cfg.BudgetThresholds = nil
+		for _, value := range strings.Split(thresholds, ",") {
+			percent, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || percent <= 0 {
+				return nil, fmt.Errorf("invalid budget threshold %q: must be a positive percentage", value)
+			}
+			cfg.BudgetThresholds = append(cfg.BudgetThresholds, percent)
+		}
+	}
+
+	cfg.CalendarToken = os.Getenv("FOCUSED_TODO_CALENDAR_TOKEN")
+
+	if slotsStr := os.Getenv("FOCUSED_TODO_DAILY_PLAN_SLOTS"); slotsStr != "" {
+		slots, err := strconv.Atoi(slotsStr)
+		if err != nil || slots <= 0 || slots > 100 {
+			return nil, fmt.Errorf("invalid daily plan slots: must be between 1 and 100")
+		}
+		cfg.DailyPlanSlots = slots
+	}
+
+	// Read scheduling settings from environment
+	if hours := os.Getenv("FOCUSED_TODO_WORKING_HOURS"); hours != "" {
+		if _, err := parseWorkingHours(hours); err != nil {
+			return nil, fmt.Errorf("invalid working hours: %w", err)
+		}
+		cfg.WorkingHours = hours
+	}
+
+	if minutesStr := os.Getenv("FOCUSED_TODO_DEFAULT_TASK_MINUTES"); minutesStr != "" {
+		minutes, err := strconv.Atoi(minutesStr)
+		if err != nil || minutes <= 0 {
+			return nil, fmt.Errorf("invalid default task minutes: must be a positive integer")
+		}
+		cfg.DefaultTaskMinutes = minutes
+	}
+
+	// Read Eisenhower matrix rules from environment
+	if priorityStr := os.Getenv("FOCUSED_TODO_IMPORTANT_PRIORITY"); priorityStr != "" {
+		priority, err := strconv.Atoi(priorityStr)
+		if err != nil || priority < 0 || priority > 11 {
+			return nil, fmt.Errorf("invalid important priority: must be between 0 and 11")
+		}
+		cfg.ImportantPriority = priority
+	}
+
+	if deriveUrgency := os.Getenv("FOCUSED_TODO_DERIVE_URGENCY"); deriveUrgency != "" {
+		enabled, err := strconv.ParseBool(deriveUrgency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derive urgency setting: %w", err)
+		}
+		cfg.DeriveUrgency = enabled
+	}
+
+	if daysStr := os.Getenv("FOCUSED_TODO_URGENT_WITHIN_DAYS"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 || days > 365 {
+			return nil, fmt.Errorf("invalid urgent within days: must be between 0 and 365")
+		}
+		cfg.UrgentWithinDays = days
+	}
+
+	// Read stale task review setting from environment
+	if daysStr := os.Getenv("FOCUSED_TODO_STALE_TASK_DAYS"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > 365 {
+			return nil, fmt.Errorf("invalid stale task days: must be between 1 and 365")
+		}
+		cfg.StaleTaskDays = days
+	}
+
 	// Set up database path
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -51,3 +319,151 @@ func Load() (*Config, error) {
 
 	return cfg, nil
 }
+
+// Location returns the configured timezone, falling back to the server's local zone
+func (c *Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// FirstDayOfWeek returns the configured first day of the week, falling back to Monday
+func (c *Config) FirstDayOfWeek() time.Weekday {
+	if day, ok := parseWeekday(c.WeekStart); ok {
+		return day
+	}
+	return time.Monday
+}
+
+// parseWeekday parses an English day name such as "sunday", ignoring case
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// MatrixRules returns how tasks without an explicit classification are placed in the Eisenhower matrix
+func (c *Config) MatrixRules() types.MatrixRules {
+	return types.MatrixRules{
+		ImportantPriority: c.ImportantPriority,
+		DeriveUrgency:     c.DeriveUrgency,
+		UrgentWithinDays:  c.UrgentWithinDays,
+	}
+}
+
+// WorkingSchedule returns the configured working periods per weekday, falling back to weekdays from nine to five
+func (c *Config) WorkingSchedule() types.WorkingHours {
+	hours, err := parseWorkingHours(c.WorkingHours)
+	if err != nil {
+		hours, _ = parseWorkingHours("mon-fri 09:00-17:00")
+	}
+	return hours
+}
+
+// parseWorkingHours parses rules separated by semicolons, each a day or day range followed by comma
+// separated periods, e.g. "mon-fri 09:00-12:00,13:00-17:00; sat 10:00-14:00". Days without a rule are off.
+func parseWorkingHours(value string) (types.WorkingHours, error) {
+	var hours types.WorkingHours
+	for _, rule := range strings.Split(value, ";") {
+		fields := strings.Fields(rule)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return hours, fmt.Errorf("rule %q must be days followed by periods", strings.TrimSpace(rule))
+		}
+
+		first, last, found := strings.Cut(fields[0], "-")
+		if !found {
+			last = first
+		}
+		from, ok := parseDayName(first)
+		to, ok2 := parseDayName(last)
+		if !ok || !ok2 {
+			return hours, fmt.Errorf("invalid days %q", fields[0])
+		}
+
+		var periods []types.WorkPeriod
+		for _, period := range strings.Split(fields[1], ",") {
+			startStr, endStr, _ := strings.Cut(period, "-")
+			start, err := parseClock(startStr)
+			if err != nil {
+				return hours, err
+			}
+			end, err := parseClock(endStr)
+			if err != nil {
+				return hours, err
+			}
+			if end <= start {
+				return hours, fmt.Errorf("period %q must end after it starts", period)
+			}
+			periods = append(periods, types.WorkPeriod{Start: start, End: end})
+		}
+
+		// Ranges may wrap around the week, e.g. sat-sun
+		for day := from; ; day = (day + 1) % 7 {
+			hours[day] = append(hours[day], periods...)
+			if day == to {
+				break
+			}
+		}
+	}
+	return hours, nil
+}
+
+// parseDayName parses a full or three letter English day name, ignoring case
+func parseDayName(name string) (time.Weekday, bool) {
+	if day, ok := parseWeekday(name); ok {
+		return day, true
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()[:3]) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// parseClock parses a time of day such as 09:30 or 24:00 as minutes after midnight
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || len(value) != 5 ||
+		hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// IdleThreshold returns the idle time after which a heartbeat marks an idle span
+func (c *Config) IdleThreshold() time.Duration {
+	return time.Duration(c.IdleThresholdMinutes) * time.Minute
+}
+
+// ReconcileOptions returns the settings for reconciling dangling time entries on startup
+func (c *Config) ReconcileOptions() types.ReconcileOptions {
+	return types.ReconcileOptions{
+		Policy:    types.DanglingPolicy(c.DanglingPolicy),
+		Threshold: time.Duration(c.DanglingThresholdHours) * time.Hour,
+		Cap:       time.Duration(c.DanglingCapHours) * time.Hour,
+	}
+}
+
+// TimeEntryRules returns the default rules time entries must satisfy
+func (c *Config) TimeEntryRules() types.TimeEntryRules {
+	return types.TimeEntryRules{
+		MaxBackdateDays:    c.MaxBackdateDays,
+		MaxDurationMinutes: c.MaxEntryMinutes,
+		MinDurationSeconds: c.MinEntrySeconds,
+		ClockSkewSeconds:   c.ClockSkewSeconds,
+	}
+}
@@ -0,0 +1,123 @@
+// Package ical reads and writes the parts of iCalendar (RFC 5545) used for calendar feeds and imports
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineOctets is the longest content line allowed before it must be folded
+const maxLineOctets = 75
+
+// Property is a content line such as "DTSTART;VALUE=DATE:20240513". Params holds the parameters
+// between the name and the value, e.g. "VALUE=DATE", without the leading semicolon.
+type Property struct {
+	Name   string
+	Params string
+	Value  string
+}
+
+// Component is a calendar component such as VCALENDAR, VEVENT or VTODO
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []Component
+}
+
+// Add appends a property with an already encoded value
+func (c *Component) Add(name, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: value})
+}
+
+// AddWithParams appends a property with parameters and an already encoded value
+func (c *Component) AddWithParams(name, params, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Params: params, Value: value})
+}
+
+// AddText appends a property whose value is escaped as TEXT
+func (c *Component) AddText(name, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+// Encode writes the component and its children with CRLF line endings and folded long lines
+func (c Component) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	c.encode(bw)
+	return bw.Flush()
+}
+
+// encode writes the component to a buffered writer, whose first error is reported by Flush
+func (c Component) encode(w *bufio.Writer) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		line := p.Name
+		if p.Params != "" {
+			line += ";" + p.Params
+		}
+		writeLine(w, line+":"+p.Value)
+	}
+	for _, child := range c.Components {
+		child.encode(w)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// writeLine writes a content line, folding it so no line exceeds 75 octets without splitting a UTF-8 sequence
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards their length
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// isRuneStart reports whether b starts a UTF-8 sequence rather than continuing one
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// EscapeText escapes a TEXT value's backslashes, semicolons, commas and newlines
+func EscapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(value)
+}
+
+// UnescapeText reverses EscapeText
+func UnescapeText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// DateTime formats a time as a UTC DATE-TIME value, e.g. 20240513T070000Z
+func DateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Date formats the calendar date of t as a DATE value, e.g. 20240513
+func Date(t time.Time) string {
+	return t.Format("20060102")
+}
@@ -0,0 +1,54 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	event := Component{Name: "VEVENT"}
+	event.AddText("SUMMARY", strings.Repeat("é", 60))
+	event.AddText("DESCRIPTION", "Notes; with, commas\nand a second line")
+
+	var b strings.Builder
+	if err := event.Encode(&b); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	out := b.String()
+
+	if !strings.HasPrefix(out, "BEGIN:VEVENT\r\n") || !strings.HasSuffix(out, "END:VEVENT\r\n") {
+		t.Errorf("Expected CRLF delimited component, got %q", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Line exceeds %d octets: %q", maxLineOctets, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("Line splits a UTF-8 sequence: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("é", 60)+"\r\n") {
+		t.Errorf("Expected the folded summary to unfold to the original")
+	}
+	if !strings.Contains(unfolded, `DESCRIPTION:Notes\; with\, commas\nand a second line`) {
+		t.Errorf("Expected escaped description, got %q", unfolded)
+	}
+}
+
+func TestTextEscaping(t *testing.T) {
+	value := "a\\b;c,d\ne"
+	if got := UnescapeText(EscapeText(value)); got != value {
+		t.Errorf("Expected %q after round trip, got %q", value, got)
+	}
+}
+
+func TestDateTimeIsUTC(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	if got := DateTime(time.Date(2024, 5, 13, 9, 0, 0, 0, loc)); got != "20240513T070000Z" {
+		t.Errorf("Expected 20240513T070000Z, got %s", got)
+	}
+}
@@ -0,0 +1,249 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Parse reads an iCalendar stream and returns its top-level component, normally a VCALENDAR
+func Parse(r io.Reader) (Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Component{}, err
+	}
+
+	var stack []Component
+	var root *Component
+	for number, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		p, err := parseLine(line)
+		if err != nil {
+			return Component{}, fmt.Errorf("line %d: %w", number+1, err)
+		}
+
+		switch strings.ToUpper(p.Name) {
+		case "BEGIN":
+			stack = append(stack, Component{Name: strings.ToUpper(p.Value)})
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return Component{}, fmt.Errorf("line %d: unexpected END:%s", number+1, p.Value)
+			}
+			done := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				if root == nil {
+					root = &done
+				}
+				continue
+			}
+			parent := &stack[len(stack)-1]
+			parent.Components = append(parent.Components, done)
+		default:
+			if len(stack) == 0 {
+				return Component{}, fmt.Errorf("line %d: property outside of a component", number+1)
+			}
+			current := &stack[len(stack)-1]
+			current.Properties = append(current.Properties, p)
+		}
+	}
+
+	if len(stack) > 0 {
+		return Component{}, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	if root == nil {
+		return Component{}, fmt.Errorf("no calendar found")
+	}
+	return *root, nil
+}
+
+// unfold splits a stream into content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line into its name, parameters and value. Quoted parameter values may contain colons.
+func parseLine(line string) (Property, error) {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if quoted {
+				continue
+			}
+			head := line[:i]
+			p := Property{Name: strings.ToUpper(head), Value: line[i+1:]}
+			if semi := strings.IndexByte(head, ';'); semi >= 0 {
+				p.Name, p.Params = strings.ToUpper(head[:semi]), head[semi+1:]
+			}
+			return p, nil
+		}
+	}
+	return Property{}, fmt.Errorf("missing ':' in %q", line)
+}
+
+// Param returns the value of the named parameter without quotes, or "" when it is absent
+func (p Property) Param(name string) string {
+	quoted := false
+	start := 0
+	params := p.Params
+	for i := 0; i <= len(params); i++ {
+		if i < len(params) && params[i] == '"' {
+			quoted = !quoted
+		}
+		if i < len(params) && (params[i] != ';' || quoted) {
+			continue
+		}
+		key, value, _ := strings.Cut(params[start:i], "=")
+		if strings.EqualFold(key, name) {
+			return strings.Trim(value, `"`)
+		}
+		start = i + 1
+	}
+	return ""
+}
+
+// Get returns the first property with the given name, or nil
+func (c Component) Get(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// GetAll returns every property with the given name
+func (c Component) GetAll(name string) []Property {
+	var properties []Property
+	for _, p := range c.Properties {
+		if p.Name == name {
+			properties = append(properties, p)
+		}
+	}
+	return properties
+}
+
+// Text returns the unescaped value of the first property with the given name, or ""
+func (c Component) Text(name string) string {
+	if p := c.Get(name); p != nil {
+		return UnescapeText(p.Value)
+	}
+	return ""
+}
+
+// ParseTimes reads a DATE or DATE-TIME property, which may hold a comma separated list as EXDATE does.
+// UTC values keep their zone, values with a TZID use that zone when it is known, and floating values
+// and dates use loc. allDay reports whether the values are dates.
+func ParseTimes(p Property, loc *time.Location) (times []time.Time, allDay bool, err error) {
+	if tzid := p.Param("TZID"); tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+
+	for _, value := range strings.Split(p.Value, ",") {
+		value = strings.TrimSpace(value)
+		var t time.Time
+		switch {
+		case strings.EqualFold(p.Param("VALUE"), "DATE") || len(value) == 8:
+			t, err = time.ParseInLocation("20060102", value, loc)
+			allDay = true
+		case strings.HasSuffix(value, "Z"):
+			t, err = time.Parse("20060102T150405Z", value)
+		default:
+			t, err = time.ParseInLocation("20060102T150405", value, loc)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s value %q", p.Name, value)
+		}
+		times = append(times, t)
+	}
+	return times, allDay, nil
+}
+
+// ParseTime reads a property holding a single DATE or DATE-TIME value
+func ParseTime(p Property, loc *time.Location) (time.Time, bool, error) {
+	times, allDay, err := ParseTimes(p, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return times[0], allDay, nil
+}
+
+// ParseDuration reads a DURATION value such as PT1H30M, P1D or P2W
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	}
+	s = strings.TrimPrefix(s, "+")
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var total time.Duration
+	inTime := false
+	number := 0
+	digits := 0
+	for _, r := range s[1:] {
+		if r >= '0' && r <= '9' {
+			number = number*10 + int(r-'0')
+			digits++
+			continue
+		}
+
+		unit := time.Duration(0)
+		switch {
+		case r == 'T' && !inTime && digits == 0:
+			inTime = true
+			continue
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		}
+		if unit == 0 || digits == 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		total += time.Duration(number) * unit
+		number, digits = 0, 0
+	}
+	if digits > 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return sign * total, nil
+}
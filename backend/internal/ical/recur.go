@@ -0,0 +1,446 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences bounds the instances generated for one recurring event
+const maxOccurrences = 5000
+
+// maxPeriods bounds the days, weeks, months or years scanned for one recurring event
+const maxPeriods = 50000
+
+// Occurrence is one instance of an event
+type Occurrence struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+}
+
+// rule is a parsed RRULE limited to the parts meetings use
+type rule struct {
+	freq       string
+	interval   int
+	count      int
+	until      *time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []int
+	weekStart  time.Weekday
+}
+
+// weekdayNum is a BYDAY entry such as MO, 2TU or -1FR. A zero n means every such day in the period.
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+// weekdays maps iCalendar day codes to weekdays
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// ExpandEvents returns the instances of a calendar's events that overlap [from, until), sorted by start.
+// Recurring events are expanded with their RRULE, minus EXDATEs and instances replaced by a
+// RECURRENCE-ID override. Cancelled events and events marked as free time are left out.
+// Values without a zone are read in loc.
+func ExpandEvents(calendar Component, from, until time.Time, loc *time.Location) ([]Occurrence, error) {
+	var events []Component
+	for _, c := range calendar.Components {
+		if c.Name == "VEVENT" {
+			events = append(events, c)
+		}
+	}
+
+	// Overrides replace the instance of their series that started at RECURRENCE-ID
+	replaced := make(map[string]map[int64]bool)
+	for _, event := range events {
+		p := event.Get("RECURRENCE-ID")
+		if p == nil {
+			continue
+		}
+		t, _, err := ParseTime(*p, loc)
+		if err != nil {
+			return nil, err
+		}
+		uid := event.Text("UID")
+		if replaced[uid] == nil {
+			replaced[uid] = make(map[int64]bool)
+		}
+		replaced[uid][t.Unix()] = true
+	}
+
+	var occurrences []Occurrence
+	for _, event := range events {
+		if strings.EqualFold(event.Text("STATUS"), "CANCELLED") || strings.EqualFold(event.Text("TRANSP"), "TRANSPARENT") {
+			continue
+		}
+
+		instances, err := expandEvent(event, from, until, loc)
+		if err != nil {
+			return nil, fmt.Errorf("event %q: %w", event.Text("SUMMARY"), err)
+		}
+
+		isOverride := event.Get("RECURRENCE-ID") != nil
+		for _, instance := range instances {
+			if !isOverride && replaced[instance.UID][instance.Start.Unix()] {
+				continue
+			}
+			occurrences = append(occurrences, instance)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences, nil
+}
+
+// expandEvent returns the instances of one event that overlap [from, until)
+func expandEvent(event Component, from, until time.Time, loc *time.Location) ([]Occurrence, error) {
+	startProp := event.Get("DTSTART")
+	if startProp == nil {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	start, allDay, err := ParseTime(*startProp, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Events last until DTEND, for DURATION, or for a day when all-day and otherwise not at all
+	end := start
+	if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if p := event.Get("DTEND"); p != nil {
+		if end, _, err = ParseTime(*p, loc); err != nil {
+			return nil, err
+		}
+	} else if p := event.Get("DURATION"); p != nil {
+		d, err := ParseDuration(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		end = start.Add(d)
+	}
+	length := end.Sub(start)
+	if length < 0 {
+		return nil, fmt.Errorf("ends before it starts")
+	}
+
+	excluded := make(map[int64]bool)
+	for _, p := range event.GetAll("EXDATE") {
+		times, _, err := ParseTimes(p, start.Location())
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range times {
+			excluded[t.Unix()] = true
+		}
+	}
+
+	var starts []time.Time
+	if p := event.Get("RRULE"); p != nil && event.Get("RECURRENCE-ID") == nil {
+		r, err := parseRule(p.Value, start.Location())
+		if err != nil {
+			return nil, err
+		}
+		// Instances starting up to one length before from still overlap it, and all-day ones can
+		// last an hour longer across a daylight saving change
+		earliest := from.Add(-length)
+		if allDay {
+			earliest = earliest.Add(-time.Hour)
+		}
+		starts = r.expand(start, earliest, until)
+	} else {
+		starts = []time.Time{start}
+	}
+
+	occurrences := []Occurrence{}
+	for _, s := range starts {
+		if excluded[s.Unix()] {
+			continue
+		}
+		e := s.Add(length)
+		if allDay {
+			// Keep all-day events on date boundaries across daylight saving changes
+			e = s.AddDate(0, 0, int(length.Round(24*time.Hour)/(24*time.Hour)))
+		}
+		if !s.Before(until) || !e.After(from) {
+			continue
+		}
+		occurrences = append(occurrences, Occurrence{
+			UID:     event.Text("UID"),
+			Summary: event.Text("SUMMARY"),
+			Start:   s,
+			End:     e,
+			AllDay:  allDay,
+		})
+	}
+	return occurrences, nil
+}
+
+// parseRule parses an RRULE value such as FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20240630T000000Z
+func parseRule(value string, loc *time.Location) (*rule, error) {
+	r := &rule{interval: 1, weekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		key, val = strings.ToUpper(strings.TrimSpace(key)), strings.ToUpper(strings.TrimSpace(val))
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.freq = val
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+		case "UNTIL":
+			var t time.Time
+			t, _, err = ParseTime(Property{Name: "UNTIL", Value: val}, loc)
+			r.until = &t
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, ok := weekdays[code[max(len(code)-2, 0):]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", code)
+				}
+				n := 0
+				if prefix := code[:len(code)-2]; prefix != "" {
+					if n, err = strconv.Atoi(prefix); err != nil {
+						break
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, day: day})
+			}
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(val)
+		case "BYMONTH":
+			r.byMonth, err = parseInts(val)
+		case "WKST":
+			day, ok := weekdays[val]
+			if !ok {
+				err = fmt.Errorf("unknown day")
+			}
+			r.weekStart = day
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %s: %v", key, err)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported RRULE frequency %q", r.freq)
+	}
+	return r, nil
+}
+
+// expand returns the rule's instance starts from from up to but excluding until. COUNT applies to every
+// instance from dtstart on, while maxOccurrences only bounds the instances returned.
+// Instances keep dtstart's wall clock time in its location, following daylight saving changes.
+func (r *rule) expand(dtstart, from, until time.Time) []time.Time {
+	// Without COUNT nothing before from is kept or counted, so those periods are skipped
+	first := 0
+	if r.count == 0 {
+		first = r.periodsBefore(dtstart, from)
+	}
+
+	var starts []time.Time
+	emitted := 0
+	for period := first; period < first+maxPeriods; period++ {
+		candidates := r.periodCandidates(dtstart, period)
+		if len(candidates) > 0 && candidates[0].After(until) && (r.until == nil || candidates[0].After(*r.until)) {
+			break
+		}
+
+		for _, c := range candidates {
+			if c.Before(dtstart) {
+				continue
+			}
+			if r.until != nil && c.After(*r.until) {
+				return starts
+			}
+			if r.count > 0 && emitted >= r.count {
+				return starts
+			}
+			emitted++
+			if !c.Before(until) {
+				return starts
+			}
+			if c.Before(from) {
+				continue
+			}
+			starts = append(starts, c)
+			if len(starts) >= maxOccurrences {
+				return starts
+			}
+		}
+	}
+	return starts
+}
+
+// periodsBefore returns a number of whole periods after dtstart's period that all end before from.
+// It errs on the low side, so no instance at or after from is skipped.
+func (r *rule) periodsBefore(dtstart, from time.Time) int {
+	if !from.After(dtstart) {
+		return 0
+	}
+
+	var n int
+	switch r.freq {
+	case "DAILY":
+		n = int(from.Sub(dtstart) / (24 * time.Hour))
+	case "WEEKLY":
+		n = int(from.Sub(dtstart) / (7 * 24 * time.Hour))
+	case "MONTHLY":
+		n = (from.Year()-dtstart.Year())*12 + int(from.Month()) - int(dtstart.Month())
+	case "YEARLY":
+		n = from.Year() - dtstart.Year()
+	}
+	return max(n/r.interval-1, 0)
+}
+
+// periodCandidates returns the sorted instance starts within the rule's nth period after dtstart's period
+func (r *rule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+
+	var candidates []time.Time
+	switch r.freq {
+	case "DAILY":
+		day := dtstart.AddDate(0, 0, n*r.interval)
+		if r.matchesDay(day) {
+			candidates = append(candidates, day)
+		}
+	case "WEEKLY":
+		offset := (int(dtstart.Weekday()) - int(r.weekStart) + 7) % 7
+		weekStart := dtstart.AddDate(0, 0, -offset+n*7*r.interval)
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && day.Weekday() == dtstart.Weekday() || r.hasWeekday(day.Weekday()) {
+				candidates = append(candidates, day)
+			}
+		}
+	case "MONTHLY":
+		first := at(dtstart.Year(), dtstart.Month(), 1).AddDate(0, n*r.interval, 0)
+		if len(r.byMonth) == 0 || containsInt(r.byMonth, int(first.Month())) {
+			candidates = r.monthCandidates(first, dtstart)
+		}
+	case "YEARLY":
+		year := dtstart.Year() + n*r.interval
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []int{int(dtstart.Month())}
+		}
+		for _, month := range months {
+			first := at(year, time.Month(month), 1)
+			if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+				if day := at(year, time.Month(month), dtstart.Day()); day.Month() == time.Month(month) {
+					candidates = append(candidates, day)
+				}
+				continue
+			}
+			candidates = append(candidates, r.monthCandidates(first, dtstart)...)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return candidates
+}
+
+// monthCandidates returns the instance starts within the month beginning at first
+func (r *rule) monthCandidates(first, dtstart time.Time) []time.Time {
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	var candidates []time.Time
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, d := range r.byMonthDay {
+			if d < 0 {
+				d = daysInMonth + d + 1
+			}
+			if d >= 1 && d <= daysInMonth {
+				candidates = append(candidates, first.AddDate(0, 0, d-1))
+			}
+		}
+	case len(r.byDay) > 0:
+		for _, wd := range r.byDay {
+			var matches []time.Time
+			for d := 0; d < daysInMonth; d++ {
+				if day := first.AddDate(0, 0, d); day.Weekday() == wd.day {
+					matches = append(matches, day)
+				}
+			}
+			switch {
+			case wd.n == 0:
+				candidates = append(candidates, matches...)
+			case wd.n > 0 && wd.n <= len(matches):
+				candidates = append(candidates, matches[wd.n-1])
+			case wd.n < 0 && -wd.n <= len(matches):
+				candidates = append(candidates, matches[len(matches)+wd.n])
+			}
+		}
+	default:
+		if dtstart.Day() <= daysInMonth {
+			candidates = append(candidates, first.AddDate(0, 0, dtstart.Day()-1))
+		}
+	}
+	return candidates
+}
+
+// matchesDay reports whether a daily instance falls on one of the BYDAY and BYMONTH values, if any
+func (r *rule) matchesDay(day time.Time) bool {
+	if len(r.byDay) > 0 && !r.hasWeekday(day.Weekday()) {
+		return false
+	}
+	if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(day.Month())) {
+		return false
+	}
+	return true
+}
+
+// hasWeekday reports whether BYDAY lists the weekday
+func (r *rule) hasWeekday(day time.Weekday) bool {
+	for _, wd := range r.byDay {
+		if wd.day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseInts parses a comma separated list of integers
+func parseInts(value string) ([]int, error) {
+	var ints []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+// containsInt reports whether values holds n
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
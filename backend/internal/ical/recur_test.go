@@ -0,0 +1,156 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const recurringCalendar = "\ufeffBEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"SUMMARY:Stand \r\n" +
+	" up\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240318T093000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=8\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20240320T093000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"SUMMARY:Stand up (moved)\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20240322T093000\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240322T110000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20240322T111500\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:lunch@example.com\r\n" +
+	"SUMMARY:Lunch\r\n" +
+	"DTSTART:20240319T120000Z\r\n" +
+	"DTEND:20240319T130000Z\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestExpandEventsRecurring(t *testing.T) {
+	calendar, err := Parse(strings.NewReader(recurringCalendar))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	occurrences, err := ExpandEvents(calendar, from, until, time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to expand events: %v", err)
+	}
+
+	// Eight instances, minus the excluded Wednesday, with Friday's replaced by the override
+	if len(occurrences) != 7 {
+		t.Fatalf("Expected 7 occurrences, got %d: %+v", len(occurrences), occurrences)
+	}
+	if occurrences[0].Summary != "Stand up" || occurrences[0].UID != "standup@example.com" {
+		t.Errorf("Expected the unfolded summary and UID, got %+v", occurrences[0])
+	}
+	if !occurrences[0].Start.Equal(time.Date(2024, 3, 18, 9, 30, 0, 0, berlin)) || occurrences[0].End.Sub(occurrences[0].Start) != 15*time.Minute {
+		t.Errorf("Unexpected first occurrence %v - %v", occurrences[0].Start, occurrences[0].End)
+	}
+	if occurrences[1].Summary != "Stand up (moved)" || !occurrences[1].Start.Equal(time.Date(2024, 3, 22, 11, 0, 0, 0, berlin)) {
+		t.Errorf("Expected the moved Friday instance second, got %+v", occurrences[1])
+	}
+
+	// The wall clock time holds across the switch to summer time on March 31st
+	last := occurrences[len(occurrences)-1]
+	if !last.Start.Equal(time.Date(2024, 4, 3, 9, 30, 0, 0, berlin)) {
+		t.Errorf("Expected the last instance on April 3rd at 09:30 in Berlin, got %v", last.Start.In(berlin))
+	}
+}
+
+func TestRuleExpand(t *testing.T) {
+	start := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule     string
+		expected []string
+	}{
+		{"FREQ=DAILY;INTERVAL=2;COUNT=3", []string{"2024-01-31", "2024-02-02", "2024-02-04"}},
+		{"FREQ=MONTHLY;COUNT=4", []string{"2024-01-31", "2024-03-31", "2024-05-31", "2024-07-31"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=2", []string{"2024-02-23", "2024-03-29"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15;UNTIL=20240315T100000Z", []string{"2024-02-01", "2024-02-15", "2024-03-01", "2024-03-15"}},
+		// Every other week counts from the week of DTSTART, whose Tuesday came before it
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=2", []string{"2024-02-13", "2024-02-27"}},
+		{"FREQ=YEARLY;BYMONTH=3;BYDAY=2MO", []string{"2024-03-11"}},
+	}
+
+	for _, tt := range tests {
+		r, err := parseRule(tt.rule, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", tt.rule, err)
+		}
+		var got []string
+		for _, s := range r.expand(start, start, until) {
+			got = append(got, s.Format("2006-01-02"))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.rule, tt.expected, got)
+		}
+	}
+
+	if _, err := parseRule("FREQ=HOURLY", time.UTC); err == nil {
+		t.Error("Expected an error for an unsupported frequency")
+	}
+}
+
+func TestRuleExpandOldSeries(t *testing.T) {
+	// A daily meeting started long ago has more instances before the window than the occurrence limit
+	start := time.Date(1995, 1, 2, 9, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 0, 7)
+
+	r, err := parseRule("FREQ=DAILY", time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	starts := r.expand(start, from, until)
+	if len(starts) != 7 || !starts[0].Equal(from.Add(9*time.Hour)) {
+		t.Errorf("Expected 7 instances from %v, got %v", from.Add(9*time.Hour), starts)
+	}
+
+	// COUNT still counts the instances before the window
+	r, err = parseRule("FREQ=WEEKLY;BYDAY=MO;COUNT=10", time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if starts := r.expand(start, from, until); len(starts) != 0 {
+		t.Errorf("Expected the series to have ended before the window, got %v", starts)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1D":     24 * time.Hour,
+		"P2W":     14 * 24 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"P1DT2S":  24*time.Hour + 2*time.Second,
+	}
+	for value, expected := range tests {
+		got, err := ParseDuration(value)
+		if err != nil || got != expected {
+			t.Errorf("ParseDuration(%q) = %v, %v; expected %v", value, got, err, expected)
+		}
+	}
+
+	for _, value := range []string{"", "P", "1H", "PT", "PT5", "P1H"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// dateLayouts are the date formats Toggl and Clockify use in detailed reports, depending on account settings
+var dateLayouts = []string{"2006-01-02", "01/02/2006", "02.01.2006", "2006/01/02"}
+
+// timeLayouts are the time of day formats used in detailed reports
+var timeLayouts = []string{"15:04:05", "15:04", "03:04:05 PM", "3:04:05 PM", "03:04 PM", "3:04 PM"}
+
+// knownColumns are the header names we read, which both trackers share apart from case
+var knownColumns = map[string]bool{
+	"client": true, "project": true, "task": true, "description": true, "billable": true,
+	"start date": true, "start time": true, "end date": true, "end time": true,
+}
+
+// requiredColumns must be present for an export to be read
+var requiredColumns = []string{"start date", "start time", "end date", "end time"}
+
+// ParseCSV reads a Toggl or Clockify detailed report export. Times without a zone are read in loc.
+// Lines that cannot be read are returned with Error set so they can be reported alongside the rest.
+func ParseCSV(r io.Reader, source types.ImportSource, loc *time.Location) ([]types.ImportRecord, error) {
+	if source != types.ImportSourceToggl && source != types.ImportSourceClockify {
+		return nil, fmt.Errorf("unsupported import source %q", source)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("export is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export header: %w", err)
+	}
+
+	// Exports may start with a byte order mark
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, seen := index[name]; knownColumns[name] && !seen {
+			index[name] = i
+		}
+	}
+	for _, column := range requiredColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("export is missing the %q column; expected a detailed report", column)
+		}
+	}
+
+	var records []types.ImportRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read export line %d: %w", line, err)
+		}
+
+		value := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		record := types.ImportRecord{
+			Line:        line,
+			Client:      value("client"),
+			Project:     value("project"),
+			Task:        value("task"),
+			Description: value("description"),
+		}
+
+		if billable := value("billable"); billable != "" {
+			isBillable := strings.EqualFold(billable, "yes") || strings.EqualFold(billable, "true")
+			record.Billable = &isBillable
+		}
+
+		record.StartTime, err = parseDateTime(value("start date"), value("start time"), loc)
+		if err != nil {
+			record.Error = fmt.Sprintf("invalid start: %v", err)
+		} else if record.EndTime, err = parseDateTime(value("end date"), value("end time"), loc); err != nil {
+			record.Error = fmt.Sprintf("invalid end: %v", err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseDateTime combines a date and a time of day written in any of the known layouts
+func parseDateTime(date, clock string, loc *time.Location) (time.Time, error) {
+	for _, dateLayout := range dateLayouts {
+		for _, timeLayout := range timeLayouts {
+			if parsed, err := time.ParseInLocation(dateLayout+" "+timeLayout, date+" "+clock, loc); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date and time %q", strings.TrimSpace(date+" "+clock))
+}
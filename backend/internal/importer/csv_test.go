@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestParseCSVToggl(t *testing.T) {
+	export := "\ufeffUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n" +
+		"Ann,ann@example.com,Acme,Website,,Fix header,Yes,2024-05-13,09:00:00,2024-05-13,10:30:00,01:30:00,\n" +
+		"Ann,ann@example.com,,Internal,,Planning,No,2024-05-13,11:00:00,2024-05-13,bad,00:30:00,\n"
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	records, err := ParseCSV(strings.NewReader(export), types.ImportSourceToggl, loc)
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.Line != 2 || first.Client != "Acme" || first.Project != "Website" || first.Description != "Fix header" {
+		t.Errorf("Unexpected first record %+v", first)
+	}
+	if !first.StartTime.Equal(time.Date(2024, 5, 13, 7, 0, 0, 0, time.UTC)) || first.EndTime.Sub(first.StartTime) != 90*time.Minute {
+		t.Errorf("Expected 09:00-10:30 in the given zone, got %v-%v", first.StartTime, first.EndTime)
+	}
+	if first.Billable == nil || !*first.Billable {
+		t.Errorf("Expected the first record to be billable")
+	}
+
+	if records[1].Error == "" || records[1].Billable == nil || *records[1].Billable {
+		t.Errorf("Expected a non-billable record with an invalid end, got %+v", records[1])
+	}
+}
+
+func TestParseCSVClockify(t *testing.T) {
+	export := `Project,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal)
+Website,Acme,Review,Design,Bob,,bob@example.com,,Yes,05/13/2024,11:45:00 PM,05/14/2024,12:15:00 AM,00:30:00,0.50
+`
+
+	records, err := ParseCSV(strings.NewReader(export), types.ImportSourceClockify, time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if len(records) != 1 || records[0].Error != "" {
+		t.Fatalf("Expected one valid record, got %+v", records)
+	}
+	if !records[0].StartTime.Equal(time.Date(2024, 5, 13, 23, 45, 0, 0, time.UTC)) || !records[0].EndTime.Equal(time.Date(2024, 5, 14, 0, 15, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected times %v-%v", records[0].StartTime, records[0].EndTime)
+	}
+	if records[0].Task != "Design" {
+		t.Errorf("Expected task Design, got %s", records[0].Task)
+	}
+}
+
+func TestParseCSVRejectsOtherReports(t *testing.T) {
+	if _, err := ParseCSV(strings.NewReader("Project,Duration\nWebsite,01:00:00\n"), types.ImportSourceToggl, time.UTC); err == nil {
+		t.Error("Expected a summary report without start and end columns to be rejected")
+	}
+	if _, err := ParseCSV(strings.NewReader(""), "harvest", time.UTC); err == nil {
+		t.Error("Expected an unsupported source to be rejected")
+	}
+}
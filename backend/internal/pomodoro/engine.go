@@ -0,0 +1,428 @@
+package pomodoro
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// maxEvents is the number of transitions kept for polling clients
+const maxEvents = 500
+
+// Clock abstracts the current time so sessions can be driven deterministically in tests
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock
+type SystemClock struct{}
+
+// Now returns the current wall clock time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Recorder persists work intervals as time entries
+type Recorder interface {
+	StartWork(taskID int, at time.Time) error
+	StopWork(taskID int, at time.Time) error
+}
+
+// session holds the mutable state of a task's pomodoro session
+type session struct {
+	taskID         int
+	phase          types.PomodoroPhase
+	running        bool
+	phaseStartedAt time.Time
+	phaseEndsAt    time.Time
+	completed      int
+	settings       types.PomodoroSettings
+}
+
+// Engine drives pomodoro sessions for any number of tasks, or for one task at a time in single session mode
+type Engine struct {
+	mu       sync.Mutex
+	clock    Clock
+	recorder Recorder
+	defaults types.PomodoroSettings
+	single   bool // Starting a session ends the sessions on other tasks
+	sessions map[int]*session
+	events   []types.PomodoroEvent
+	seq      int
+}
+
+// DefaultSettings are the classic pomodoro interval lengths
+var DefaultSettings = types.PomodoroSettings{
+	WorkMinutes:       25,
+	ShortBreakMinutes: 5,
+	LongBreakMinutes:  15,
+	LongBreakEvery:    4,
+}
+
+// NewEngine creates a new Engine using the given clock, recorder and default settings.
+// Zero interval lengths and counts in the defaults are replaced with DefaultSettings.
+func NewEngine(clock Clock, recorder Recorder, defaults types.PomodoroSettings) *Engine {
+	return &Engine{
+		clock:    clock,
+		recorder: recorder,
+		defaults: fillSettings(defaults, DefaultSettings),
+		sessions: make(map[int]*session),
+	}
+}
+
+// fillSettings replaces the non-positive lengths and counts of settings with those of fallback, so that
+// no phase can have a zero duration and the long break cycle never divides by zero
+func fillSettings(settings, fallback types.PomodoroSettings) types.PomodoroSettings {
+	if settings.WorkMinutes <= 0 {
+		settings.WorkMinutes = fallback.WorkMinutes
+	}
+	if settings.ShortBreakMinutes <= 0 {
+		settings.ShortBreakMinutes = fallback.ShortBreakMinutes
+	}
+	if settings.LongBreakMinutes <= 0 {
+		settings.LongBreakMinutes = fallback.LongBreakMinutes
+	}
+	if settings.LongBreakEvery <= 0 {
+		settings.LongBreakEvery = fallback.LongBreakEvery
+	}
+	return settings
+}
+
+// SetSingleSession enables or disables single session mode. It matches global timer mode, in which
+// starting a time entry stops the entries of every other task and so those tasks' sessions.
+func (e *Engine) SetSingleSession(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.single = enabled
+}
+
+// Start begins a pomodoro session on a task with a work interval.
+// A nil settings value uses the engine defaults. In single session mode the sessions on
+// other tasks are stopped first.
+func (e *Engine) Start(taskID int, settings *types.PomodoroSettings) (*types.PomodoroSession, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.advance(now)
+
+	if _, exists := e.sessions[taskID]; exists {
+		return nil, fmt.Errorf("task %d already has a pomodoro session", taskID)
+	}
+
+	if e.single {
+		for _, other := range e.sessions {
+			e.end(other, now)
+		}
+	}
+
+	s := &session{taskID: taskID, phase: types.PomodoroPhaseWork, settings: e.defaults}
+	if settings != nil {
+		s.settings = fillSettings(*settings, e.defaults)
+	}
+
+	if err := e.beginPhase(s, now); err != nil {
+		return nil, err
+	}
+
+	e.sessions[taskID] = s
+	return s.snapshot(now), nil
+}
+
+// Stop ends a task's pomodoro session, closing the running work interval
+func (e *Engine) Stop(taskID int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.advance(now)
+
+	s, exists := e.sessions[taskID]
+	if !exists {
+		return fmt.Errorf("no pomodoro session found for task %d", taskID)
+	}
+
+	e.end(s, now)
+	return nil
+}
+
+// Release ends a task's session after its time entry was stopped outside the engine, e.g. by hand.
+// The session is not advanced first, so a finished break cannot start tracking the task again.
+func (e *Engine) Release(taskID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if s, exists := e.sessions[taskID]; exists {
+		e.remove(s, e.clock.Now())
+	}
+}
+
+// Displace ends the sessions on other tasks after a time entry was started on a task outside the engine.
+// Only single session mode stops the other tasks' entries, so otherwise the sessions are kept.
+func (e *Engine) Displace(taskID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.single {
+		return
+	}
+
+	now := e.clock.Now()
+	for _, s := range e.sessions {
+		if s.taskID != taskID {
+			e.remove(s, now)
+		}
+	}
+}
+
+// Skip ends the current break early and starts the next work interval
+func (e *Engine) Skip(taskID int) (*types.PomodoroSession, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.advance(now)
+
+	s, exists := e.sessions[taskID]
+	if !exists {
+		return nil, fmt.Errorf("no pomodoro session found for task %d", taskID)
+	}
+	if s.phase == types.PomodoroPhaseWork {
+		return nil, fmt.Errorf("only breaks can be skipped")
+	}
+
+	e.emit(taskID, types.PomodoroEventBreakSkipped, s.phase, now)
+	s.phase = types.PomodoroPhaseWork
+	if err := e.beginPhase(s, now); err != nil {
+		return nil, err
+	}
+
+	return s.snapshot(now), nil
+}
+
+// StartNext starts the interval that is waiting for a manual start when auto-start is off
+func (e *Engine) StartNext(taskID int) (*types.PomodoroSession, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.advance(now)
+
+	s, exists := e.sessions[taskID]
+	if !exists {
+		return nil, fmt.Errorf("no pomodoro session found for task %d", taskID)
+	}
+	if s.running {
+		return nil, fmt.Errorf("pomodoro interval is already running")
+	}
+
+	if err := e.beginPhase(s, now); err != nil {
+		return nil, err
+	}
+
+	return s.snapshot(now), nil
+}
+
+// Get returns the current state of a task's pomodoro session
+func (e *Engine) Get(taskID int) (*types.PomodoroSession, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.advance(now)
+
+	s, exists := e.sessions[taskID]
+	if !exists {
+		return nil, fmt.Errorf("no pomodoro session found for task %d", taskID)
+	}
+
+	return s.snapshot(now), nil
+}
+
+// Sessions returns the state of every pomodoro session ordered by task ID
+func (e *Engine) Sessions() []types.PomodoroSession {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.advance(now)
+
+	sessions := []types.PomodoroSession{}
+	for _, s := range e.sessions {
+		sessions = append(sessions, *s.snapshot(now))
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].TaskID < sessions[j].TaskID })
+
+	return sessions
+}
+
+// Events returns the retained transitions with a sequence number greater than since
+func (e *Engine) Events(since int) []types.PomodoroEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.advance(e.clock.Now())
+
+	events := []types.PomodoroEvent{}
+	for _, event := range e.events {
+		if event.Seq > since {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// Tick advances every session to the current time, completing any intervals that have ended
+func (e *Engine) Tick() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.advance(e.clock.Now())
+}
+
+// Run ticks the engine at the given interval until stop is closed
+func (e *Engine) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.Tick()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// advance completes every interval that ended at or before now.
+// Transitions happen at the exact interval boundary so late ticks do not skew recorded time.
+func (e *Engine) advance(now time.Time) {
+	for _, s := range e.sessions {
+		for s.running && !now.Before(s.phaseEndsAt) {
+			endedAt := s.phaseEndsAt
+			e.completePhase(s, endedAt)
+
+			if !s.settings.AutoStart {
+				s.running = false
+				break
+			}
+
+			if err := e.beginPhase(s, endedAt); err != nil {
+				log.Printf("Failed to start pomodoro interval for task %d: %v", s.taskID, err)
+				s.running = false
+			}
+		}
+	}
+}
+
+// end removes a session, closing its running work interval
+func (e *Engine) end(s *session, now time.Time) {
+	if s.running && s.phase == types.PomodoroPhaseWork {
+		e.stopWork(s, now)
+	}
+
+	e.remove(s, now)
+}
+
+// remove drops a session without touching its time entry
+func (e *Engine) remove(s *session, now time.Time) {
+	delete(e.sessions, s.taskID)
+	e.emit(s.taskID, types.PomodoroEventSessionStopped, s.phase, now)
+}
+
+// completePhase finishes the running interval and selects the next one
+func (e *Engine) completePhase(s *session, at time.Time) {
+	e.emit(s.taskID, types.PomodoroEventPhaseCompleted, s.phase, at)
+
+	if s.phase != types.PomodoroPhaseWork {
+		s.phase = types.PomodoroPhaseWork
+		return
+	}
+
+	e.stopWork(s, at)
+	s.completed++
+
+	if s.completed%s.settings.LongBreakEvery == 0 {
+		s.phase = types.PomodoroPhaseLongBreak
+	} else {
+		s.phase = types.PomodoroPhaseShortBreak
+	}
+}
+
+// beginPhase starts the session's current phase at the given time
+func (e *Engine) beginPhase(s *session, at time.Time) error {
+	if s.phase == types.PomodoroPhaseWork {
+		if err := e.recorder.StartWork(s.taskID, at); err != nil {
+			return fmt.Errorf("failed to record work interval: %w", err)
+		}
+	}
+
+	s.running = true
+	s.phaseStartedAt = at
+	s.phaseEndsAt = at.Add(s.phaseLength())
+	e.emit(s.taskID, types.PomodoroEventPhaseStarted, s.phase, at)
+	return nil
+}
+
+// stopWork closes the time entry of a work interval.
+// The entry may already have been stopped elsewhere, so failures are only logged.
+func (e *Engine) stopWork(s *session, at time.Time) {
+	if err := e.recorder.StopWork(s.taskID, at); err != nil {
+		log.Printf("Failed to stop pomodoro work interval for task %d: %v", s.taskID, err)
+	}
+}
+
+// emit appends a transition to the retained event log
+func (e *Engine) emit(taskID int, eventType types.PomodoroEventType, phase types.PomodoroPhase, at time.Time) {
+	e.seq++
+	e.events = append(e.events, types.PomodoroEvent{
+		Seq:    e.seq,
+		TaskID: taskID,
+		Type:   eventType,
+		Phase:  phase,
+		At:     at,
+	})
+
+	if len(e.events) > maxEvents {
+		e.events = e.events[len(e.events)-maxEvents:]
+	}
+}
+
+// phaseLength returns the configured length of the session's current phase
+func (s *session) phaseLength() time.Duration {
+	switch s.phase {
+	case types.PomodoroPhaseShortBreak:
+		return time.Duration(s.settings.ShortBreakMinutes) * time.Minute
+	case types.PomodoroPhaseLongBreak:
+		return time.Duration(s.settings.LongBreakMinutes) * time.Minute
+	default:
+		return time.Duration(s.settings.WorkMinutes) * time.Minute
+	}
+}
+
+// snapshot returns the externally visible state of the session as of now
+func (s *session) snapshot(now time.Time) *types.PomodoroSession {
+	snapshot := &types.PomodoroSession{
+		TaskID:             s.taskID,
+		Phase:              s.phase,
+		Running:            s.running,
+		CompletedPomodoros: s.completed,
+		Settings:           s.settings,
+	}
+
+	if s.running {
+		startedAt, endsAt := s.phaseStartedAt, s.phaseEndsAt
+		snapshot.PhaseStartedAt = &startedAt
+		snapshot.PhaseEndsAt = &endsAt
+		snapshot.Remaining = int(endsAt.Sub(now).Seconds())
+	}
+
+	return snapshot
+}
@@ -0,0 +1,331 @@
+package pomodoro
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// interval is a recorded work interval
+type interval struct {
+	taskID int
+	start  time.Time
+	end    *time.Time
+}
+
+// fakeRecorder keeps work intervals in memory
+type fakeRecorder struct {
+	intervals []interval
+}
+
+func (r *fakeRecorder) StartWork(taskID int, at time.Time) error {
+	r.intervals = append(r.intervals, interval{taskID: taskID, start: at})
+	return nil
+}
+
+func (r *fakeRecorder) StopWork(taskID int, at time.Time) error {
+	for i := range r.intervals {
+		if r.intervals[i].taskID == taskID && r.intervals[i].end == nil {
+			end := at
+			r.intervals[i].end = &end
+		}
+	}
+	return nil
+}
+
+func testSettings(autoStart bool) types.PomodoroSettings {
+	return types.PomodoroSettings{
+		WorkMinutes:       25,
+		ShortBreakMinutes: 5,
+		LongBreakMinutes:  15,
+		LongBreakEvery:    4,
+		AutoStart:         autoStart,
+	}
+}
+
+func setupTestEngine(autoStart bool) (*Engine, *fakeClock, *fakeRecorder) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)}
+	recorder := &fakeRecorder{}
+	return NewEngine(clock, recorder, testSettings(autoStart)), clock, recorder
+}
+
+func TestEngineFullCycle(t *testing.T) {
+	engine, clock, recorder := setupTestEngine(true)
+	start := clock.Now()
+
+	session, err := engine.Start(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if session.Phase != types.PomodoroPhaseWork || session.Remaining != 25*60 {
+		t.Errorf("Expected a 25 minute work interval, got %s with %d seconds", session.Phase, session.Remaining)
+	}
+
+	// Starting a second session on the same task fails
+	if _, err := engine.Start(1, nil); err == nil || !strings.Contains(err.Error(), "already has a pomodoro session") {
+		t.Errorf("Expected duplicate session error, got %v", err)
+	}
+
+	// Four work intervals with three short breaks bring the session to the long break
+	clock.Advance(4*25*time.Minute + 3*5*time.Minute)
+	engine.Tick()
+
+	session, err = engine.Get(1)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if session.Phase != types.PomodoroPhaseLongBreak {
+		t.Errorf("Expected long break, got %s", session.Phase)
+	}
+	if session.CompletedPomodoros != 4 {
+		t.Errorf("Expected 4 completed pomodoros, got %d", session.CompletedPomodoros)
+	}
+	if session.Remaining != 15*60 {
+		t.Errorf("Expected 15 minutes remaining, got %d seconds", session.Remaining)
+	}
+
+	// Each work interval is recorded exactly on its boundaries
+	if len(recorder.intervals) != 4 {
+		t.Fatalf("Expected 4 recorded intervals, got %d", len(recorder.intervals))
+	}
+	for i, recorded := range recorder.intervals {
+		expectedStart := start.Add(time.Duration(i) * 30 * time.Minute)
+		if !recorded.start.Equal(expectedStart) {
+			t.Errorf("Interval %d: expected start %v, got %v", i, expectedStart, recorded.start)
+		}
+		if recorded.end == nil || !recorded.end.Equal(expectedStart.Add(25*time.Minute)) {
+			t.Errorf("Interval %d: expected end after 25 minutes, got %v", i, recorded.end)
+		}
+	}
+
+	// After the long break a new work interval starts
+	clock.Advance(15 * time.Minute)
+	session, err = engine.Get(1)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if session.Phase != types.PomodoroPhaseWork || len(recorder.intervals) != 5 {
+		t.Errorf("Expected a fifth work interval, got %s with %d intervals", session.Phase, len(recorder.intervals))
+	}
+}
+
+func TestEngineManualStart(t *testing.T) {
+	engine, clock, recorder := setupTestEngine(false)
+
+	if _, err := engine.Start(1, nil); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	// Without auto-start the session waits at the break
+	clock.Advance(40 * time.Minute)
+	session, err := engine.Get(1)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if session.Running || session.Phase != types.PomodoroPhaseShortBreak {
+		t.Errorf("Expected a paused short break, got %s running=%v", session.Phase, session.Running)
+	}
+	if recorder.intervals[0].end == nil {
+		t.Errorf("Expected the work interval to be stopped")
+	}
+
+	session, err = engine.StartNext(1)
+	if err != nil {
+		t.Fatalf("Failed to start next interval: %v", err)
+	}
+	if !session.Running || session.Remaining != 5*60 {
+		t.Errorf("Expected a running 5 minute break, got %d seconds", session.Remaining)
+	}
+
+	if _, err := engine.StartNext(1); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("Expected already running error, got %v", err)
+	}
+}
+
+func TestEngineSkipBreak(t *testing.T) {
+	engine, clock, recorder := setupTestEngine(true)
+
+	if _, err := engine.Start(1, nil); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	// Work intervals cannot be skipped
+	if _, err := engine.Skip(1); err == nil || !strings.Contains(err.Error(), "only breaks") {
+		t.Errorf("Expected only breaks error, got %v", err)
+	}
+
+	clock.Advance(26 * time.Minute)
+	session, err := engine.Skip(1)
+	if err != nil {
+		t.Fatalf("Failed to skip break: %v", err)
+	}
+	if session.Phase != types.PomodoroPhaseWork || session.Remaining != 25*60 {
+		t.Errorf("Expected a fresh work interval, got %s with %d seconds", session.Phase, session.Remaining)
+	}
+	if len(recorder.intervals) != 2 || !recorder.intervals[1].start.Equal(clock.Now()) {
+		t.Errorf("Expected the second work interval to start when the break was skipped")
+	}
+
+	var skipped bool
+	for _, event := range engine.Events(0) {
+		if event.Type == types.PomodoroEventBreakSkipped {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Errorf("Expected a break skipped event")
+	}
+}
+
+func TestEngineStopAndEvents(t *testing.T) {
+	engine, clock, recorder := setupTestEngine(true)
+
+	custom := testSettings(true)
+	custom.WorkMinutes = 50
+	session, err := engine.Start(2, &custom)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if session.Remaining != 50*60 {
+		t.Errorf("Expected custom settings to apply, got %d seconds", session.Remaining)
+	}
+
+	events := engine.Events(0)
+	if len(events) != 1 || events[0].Type != types.PomodoroEventPhaseStarted {
+		t.Fatalf("Expected a single phase started event, got %v", events)
+	}
+	lastSeq := events[0].Seq
+
+	clock.Advance(10 * time.Minute)
+	if err := engine.Stop(2); err != nil {
+		t.Fatalf("Failed to stop session: %v", err)
+	}
+	if recorder.intervals[0].end == nil || !recorder.intervals[0].end.Equal(clock.Now()) {
+		t.Errorf("Expected the work interval to stop with the session")
+	}
+
+	events = engine.Events(lastSeq)
+	if len(events) != 1 || events[0].Type != types.PomodoroEventSessionStopped {
+		t.Errorf("Expected only the session stopped event after seq %d, got %v", lastSeq, events)
+	}
+
+	if _, err := engine.Get(2); err == nil || !strings.Contains(err.Error(), "no pomodoro session") {
+		t.Errorf("Expected no session error, got %v", err)
+	}
+	if len(engine.Sessions()) != 0 {
+		t.Errorf("Expected no sessions after stop")
+	}
+}
+
+func TestEngineSingleSession(t *testing.T) {
+	engine, clock, recorder := setupTestEngine(true)
+	engine.SetSingleSession(true)
+
+	if _, err := engine.Start(1, nil); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	clock.Advance(10 * time.Minute)
+
+	// Starting on another task ends the first session and its work interval
+	if _, err := engine.Start(2, nil); err != nil {
+		t.Fatalf("Failed to start second session: %v", err)
+	}
+	sessions := engine.Sessions()
+	if len(sessions) != 1 || sessions[0].TaskID != 2 {
+		t.Fatalf("Expected only the second session, got %+v", sessions)
+	}
+	if recorder.intervals[0].end == nil || !recorder.intervals[0].end.Equal(clock.Now()) {
+		t.Errorf("Expected the first work interval to stop when the second session starts")
+	}
+
+	stopped := false
+	for _, event := range engine.Events(0) {
+		if event.TaskID == 1 && event.Type == types.PomodoroEventSessionStopped {
+			stopped = true
+		}
+	}
+	if !stopped {
+		t.Errorf("Expected a session stopped event for the displaced session")
+	}
+}
+
+func TestEngineReleaseAndDisplace(t *testing.T) {
+	engine, clock, recorder := setupTestEngine(true)
+
+	if _, err := engine.Start(1, nil); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	clock.Advance(10 * time.Minute)
+
+	// Outside single session mode a timer started elsewhere leaves the session alone
+	engine.Displace(2)
+	if len(engine.Sessions()) != 1 {
+		t.Fatalf("Expected the session to be kept outside single session mode")
+	}
+
+	// A session whose entry was stopped by hand ends without recording anything more
+	engine.Release(1)
+	clock.Advance(time.Hour)
+	engine.Tick()
+	if len(engine.Sessions()) != 0 {
+		t.Errorf("Expected no sessions after release")
+	}
+	if len(recorder.intervals) != 1 || recorder.intervals[0].end != nil {
+		t.Errorf("Expected the released session to leave its interval to the caller, got %+v", recorder.intervals)
+	}
+
+	// In single session mode a timer started on another task ends the session during its break,
+	// so the next work interval does not take over the timer
+	engine.SetSingleSession(true)
+	if _, err := engine.Start(1, nil); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	clock.Advance(27 * time.Minute)
+	engine.Tick()
+	recorded := len(recorder.intervals)
+
+	engine.Displace(2)
+	clock.Advance(5 * time.Minute)
+	engine.Tick()
+	if len(engine.Sessions()) != 0 {
+		t.Errorf("Expected the displaced session to end")
+	}
+	if len(recorder.intervals) != recorded {
+		t.Errorf("Expected no work interval after the session was displaced, got %+v", recorder.intervals[recorded:])
+	}
+}
+
+func TestEngineZeroSettingsUseDefaults(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)}
+	engine := NewEngine(clock, &fakeRecorder{}, types.PomodoroSettings{AutoStart: true})
+
+	session, err := engine.Start(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if session.Settings != (types.PomodoroSettings{WorkMinutes: 25, ShortBreakMinutes: 5, LongBreakMinutes: 15, LongBreakEvery: 4, AutoStart: true}) {
+		t.Errorf("Expected zero settings to fall back to the defaults, got %+v", session.Settings)
+	}
+
+	// Completing work intervals reaches the long break without dividing by zero
+	clock.Advance(24 * time.Hour)
+	engine.Tick()
+	if _, err := engine.Get(1); err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+}
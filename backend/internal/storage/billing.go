@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// billingRates holds the configured hourly rates keyed by project and task ID
+type billingRates struct {
+	projects map[int]int64
+	tasks    map[int]int64
+}
+
+// SetProjectRate sets a project's hourly rate, or clears it when rate is nil
+func (s *Storage) SetProjectRate(projectID int, rate *int64) (*types.HourlyRate, error) {
+	projectExists, err := s.projectExists(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+	}
+	if !projectExists {
+		return nil, fmt.Errorf("project with id %d does not exist", projectID)
+	}
+
+	if err := setRate(s.db, "project_rates", "project_id", projectID, rate); err != nil {
+		return nil, err
+	}
+
+	return &types.HourlyRate{ProjectID: &projectID, HourlyRate: rate}, nil
+}
+
+// SetTaskRate sets a task's hourly rate, overriding its project's rate, or clears it when rate is nil
+func (s *Storage) SetTaskRate(taskID int, rate *int64) (*types.HourlyRate, error) {
+	exists, err := taskExists(s.db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify task existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("task with id %d does not exist", taskID)
+	}
+
+	if err := setRate(s.db, "task_rates", "task_id", taskID, rate); err != nil {
+		return nil, err
+	}
+
+	return &types.HourlyRate{TaskID: &taskID, HourlyRate: rate}, nil
+}
+
+// setRate upserts or deletes a row in one of the rate tables
+func setRate(q querier, table, column string, id int, rate *int64) error {
+	if rate == nil {
+		query := `DELETE FROM ` + table + ` WHERE ` + column + ` = ?`
+		if _, err := q.Exec(query, id); err != nil {
+			return fmt.Errorf("failed to clear hourly rate: %w", err)
+		}
+		return nil
+	}
+
+	query := `INSERT INTO ` + table + ` (` + column + `, hourly_rate) VALUES (?, ?)
+			  ON CONFLICT(` + column + `) DO UPDATE SET hourly_rate = excluded.hourly_rate`
+	if _, err := q.Exec(query, id, *rate); err != nil {
+		return fmt.Errorf("failed to set hourly rate: %w", err)
+	}
+	return nil
+}
+
+// getBillingRates loads every configured project and task rate
+func getBillingRates(q querier) (*billingRates, error) {
+	rates := &billingRates{projects: make(map[int]int64), tasks: make(map[int]int64)}
+
+	if err := loadRates(q, `SELECT project_id, hourly_rate FROM project_rates`, rates.projects); err != nil {
+		return nil, err
+	}
+	if err := loadRates(q, `SELECT task_id, hourly_rate FROM task_rates`, rates.tasks); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// loadRates reads ID and rate pairs from a rate query into target
+func loadRates(q querier, query string, target map[int]int64) error {
+	rows, err := q.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to query hourly rates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var rate int64
+		if err := rows.Scan(&id, &rate); err != nil {
+			return fmt.Errorf("failed to scan hourly rate: %w", err)
+		}
+		target[id] = rate
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading hourly rate rows: %w", err)
+	}
+
+	return nil
+}
+
+// rateFor returns the hourly rate that applies to a task, falling back to its project's rate
+func (r *billingRates) rateFor(taskID, projectID int) int64 {
+	if rate, ok := r.tasks[taskID]; ok {
+		return rate
+	}
+	return r.projects[projectID]
+}
+
+// GetTimeEntryBilling returns the billing state of a time entry
+func (s *Storage) GetTimeEntryBilling(id int) (*types.TimeEntryBilling, error) {
+	return getTimeEntryBilling(s.db, id)
+}
+
+// SetTimeEntryBillable marks a time entry as billable or not. Invoiced entries cannot change.
+func (s *Storage) SetTimeEntryBillable(id int, billable bool) (*types.TimeEntryBilling, error) {
+	billing, err := getTimeEntryBilling(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if billing.InvoiceID != nil {
+		return nil, fmt.Errorf("time entry %d is invoiced and cannot be changed", id)
+	}
+
+	if _, err := s.db.Exec(`UPDATE time_entries SET billable = ? WHERE id = ?`, billable, id); err != nil {
+		return nil, fmt.Errorf("failed to update billable flag: %w", err)
+	}
+
+	billing.Billable = billable
+	return billing, nil
+}
+
+// getTimeEntryBilling loads the billing state of a time entry using the given querier
+func getTimeEntryBilling(q querier, id int) (*types.TimeEntryBilling, error) {
+	billing := &types.TimeEntryBilling{TimeEntryID: id}
+	err := q.QueryRow(`SELECT billable, invoice_id FROM time_entries WHERE id = ?`, id).Scan(&billing.Billable, &billing.InvoiceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("time entry with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get time entry billing: %w", err)
+	}
+	return billing, nil
+}
+
+// roundDuration rounds seconds to the rule's increment. A zero increment leaves the duration as it is.
+func roundDuration(seconds int, rule types.RoundingRule) int {
+	increment := rule.IncrementMinutes * 60
+	if increment <= 0 {
+		return seconds
+	}
+
+	switch rule.Mode {
+	case types.RoundingModeUp:
+		return (seconds + increment - 1) / increment * increment
+	case types.RoundingModeDown:
+		return seconds / increment * increment
+	default:
+		return (seconds + increment/2) / increment * increment
+	}
+}
+
+// billedAmount returns the amount for a duration at an hourly rate, rounded to the nearest minor unit
+func billedAmount(seconds int, hourlyRate int64) int64 {
+	return (int64(seconds)*hourlyRate + 1800) / 3600
+}
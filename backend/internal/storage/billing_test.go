@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestRoundDuration(t *testing.T) {
+	tests := []struct {
+		seconds  int
+		rule     types.RoundingRule
+		expected int
+	}{
+		{seconds: 7*60 + 29, rule: types.RoundingRule{}, expected: 7*60 + 29},
+		{seconds: 7 * 60, rule: types.RoundingRule{IncrementMinutes: 15}, expected: 0},
+		{seconds: 8 * 60, rule: types.RoundingRule{IncrementMinutes: 15}, expected: 15 * 60},
+		{seconds: 61, rule: types.RoundingRule{IncrementMinutes: 6, Mode: types.RoundingModeUp}, expected: 6 * 60},
+		{seconds: 29 * 60, rule: types.RoundingRule{IncrementMinutes: 15, Mode: types.RoundingModeDown}, expected: 15 * 60},
+		{seconds: 30 * 60, rule: types.RoundingRule{IncrementMinutes: 30, Mode: types.RoundingModeUp}, expected: 30 * 60},
+	}
+
+	for _, tt := range tests {
+		if got := roundDuration(tt.seconds, tt.rule); got != tt.expected {
+			t.Errorf("roundDuration(%d, %+v) = %d, expected %d", tt.seconds, tt.rule, got, tt.expected)
+		}
+	}
+
+	// 90 minutes at 100.00 per hour
+	if amount := billedAmount(90*60, 10000); amount != 15000 {
+		t.Errorf("Expected an amount of 15000, got %d", amount)
+	}
+}
+
+func TestBillingRates(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	other := createTestTask(t, s, project.ID)
+
+	projectRate := int64(8000)
+	if _, err := s.SetProjectRate(project.ID, &projectRate); err != nil {
+		t.Fatalf("Failed to set project rate: %v", err)
+	}
+	taskRate := int64(12000)
+	if _, err := s.SetTaskRate(task.ID, &taskRate); err != nil {
+		t.Fatalf("Failed to set task rate: %v", err)
+	}
+
+	rates, err := getBillingRates(s.db)
+	if err != nil {
+		t.Fatalf("Failed to load rates: %v", err)
+	}
+	if rate := rates.rateFor(task.ID, project.ID); rate != taskRate {
+		t.Errorf("Expected the task rate %d to win, got %d", taskRate, rate)
+	}
+	if rate := rates.rateFor(other.ID, project.ID); rate != projectRate {
+		t.Errorf("Expected the project rate %d as fallback, got %d", projectRate, rate)
+	}
+
+	// Clearing the task rate falls back to the project rate
+	if _, err := s.SetTaskRate(task.ID, nil); err != nil {
+		t.Fatalf("Failed to clear task rate: %v", err)
+	}
+	rates, err = getBillingRates(s.db)
+	if err != nil {
+		t.Fatalf("Failed to load rates: %v", err)
+	}
+	if rate := rates.rateFor(task.ID, project.ID); rate != projectRate {
+		t.Errorf("Expected the project rate after clearing, got %d", rate)
+	}
+
+	// Test nonexistent project and task
+	if _, err := s.SetProjectRate(99999, &projectRate); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("Expected does not exist error, got %v", err)
+	}
+	if _, err := s.SetTaskRate(99999, &taskRate); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("Expected does not exist error, got %v", err)
+	}
+}
+
+func TestTimeReportBilling(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	rate := int64(6000)
+	if _, err := s.SetProjectRate(project.ID, &rate); err != nil {
+		t.Fatalf("Failed to set project rate: %v", err)
+	}
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	billable := createStoppedEntry(t, s, task.ID, start, 50*time.Minute)
+	internal := createStoppedEntry(t, s, task.ID, start.Add(time.Hour), 20*time.Minute)
+
+	billing, err := s.SetTimeEntryBillable(internal.ID, false)
+	if err != nil {
+		t.Fatalf("Failed to clear billable flag: %v", err)
+	}
+	if billing.Billable {
+		t.Error("Expected the entry to be non-billable")
+	}
+
+	billing, err = s.GetTimeEntryBilling(billable.ID)
+	if err != nil {
+		t.Fatalf("Failed to get billing: %v", err)
+	}
+	if !billing.Billable || billing.InvoiceID != nil {
+		t.Errorf("Expected a billable, uninvoiced entry by default, got %+v", billing)
+	}
+
+	query := types.TimeReportQuery{
+		From:     start.Add(-time.Hour),
+		To:       time.Now().Add(time.Hour),
+		GroupBy:  types.ReportGroupByProject,
+		Rounding: types.RoundingRule{IncrementMinutes: 15, Mode: types.RoundingModeUp},
+	}
+	report, err := s.GetTimeReport(query)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if report.TotalDuration != 70*60 {
+		t.Errorf("Expected 70 tracked minutes, got %d seconds", report.TotalDuration)
+	}
+	// Only the 50 minute entry is billable, rounded up to an hour at 60.00
+	if report.TotalBillableDuration != 60*60 || report.TotalAmount != 6000 {
+		t.Errorf("Expected 60 billable minutes for 6000, got %d seconds for %d", report.TotalBillableDuration, report.TotalAmount)
+	}
+	if len(report.Rows) != 1 || report.Rows[0].Amount != 6000 {
+		t.Errorf("Expected a single row with an amount of 6000, got %v", report.Rows)
+	}
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// boardStatuses lists the board columns in display order
+var boardStatuses = []types.TaskStatus{
+	types.TaskStatusPending,
+	types.TaskStatusInProgress,
+	types.TaskStatusCompleted,
+	types.TaskStatusCancelled,
+}
+
+// GetBoard retrieves a project's tasks grouped into status columns in board order
+func (s *Storage) GetBoard(projectID int) (*types.Board, error) {
+	// First verify that the project exists
+	projectExists, err := s.projectExists(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+	}
+	if !projectExists {
+		return nil, fmt.Errorf("project with id %d does not exist", projectID)
+	}
+
+	limits, err := s.getWIPLimits(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &types.Board{ProjectID: projectID}
+	columnIndex := make(map[types.TaskStatus]int)
+	for i, status := range boardStatuses {
+		column := types.BoardColumn{Status: status, Tasks: []types.Task{}}
+		if limit, ok := limits[status]; ok {
+			column.WIPLimit = &limit
+		}
+		board.Columns = append(board.Columns, column)
+		columnIndex[status] = i
+	}
+
+	// Tasks that have never been placed on the board fall back to list order
+	query := `SELECT t.id, t.project_id, t.parent_id, t.title, t.description, t.status, t.priority, t.due_date, t.completed_at, t.created_at, t.updated_at
+			  FROM tasks t
+			  LEFT JOIN board_positions bp ON bp.task_id = t.id
+			  WHERE t.project_id = ?
+			  ORDER BY bp.position IS NULL, bp.position, t.priority DESC, t.created_at ASC`
+
+	rows, err := s.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query board tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task types.Task
+		err := rows.Scan(
+			&task.ID,
+			&task.ProjectID,
+			&task.ParentID,
+			&task.Title,
+			&task.Description,
+			&task.Status,
+			&task.Priority,
+			&task.DueDate,
+			&task.CompletedAt,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board task: %w", err)
+		}
+
+		i, ok := columnIndex[task.Status]
+		if !ok {
+			continue // Unknown statuses have no column
+		}
+		board.Columns[i].Tasks = append(board.Columns[i].Tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading board task rows: %w", err)
+	}
+
+	return board, nil
+}
+
+// MoveTask atomically changes a task's status and its position within the target column
+func (s *Storage) MoveTask(taskID int, req types.MoveTaskRequest) (*types.Task, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	task, err := moveTask(tx, taskID, req.Status, req.Position, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit move transaction: %w", err)
+	}
+
+	return task, nil
+}
+
+// moveTask places a task at position in the board column of status, changing its status when the column
+// differs and closing the gap it leaves behind. A negative position appends to the column.
+func moveTask(q querier, taskID int, status types.TaskStatus, position int, now time.Time) (*types.Task, error) {
+	var projectID int
+	var currentStatus types.TaskStatus
+	err := q.QueryRow(`SELECT project_id, status FROM tasks WHERE id = ?`, taskID).Scan(&projectID, &currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task with id %d not found", taskID)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	column, err := boardColumnTaskIDs(q, projectID, status, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// WIP limits only apply to tasks entering a column
+	if status != currentStatus {
+		var limit int
+		err := q.QueryRow(`SELECT wip_limit FROM board_wip_limits WHERE project_id = ? AND status = ?`,
+			projectID, status).Scan(&limit)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get WIP limit: %w", err)
+		}
+		if err == nil && len(column) >= limit {
+			return nil, fmt.Errorf("WIP limit of %d reached for column %s", limit, status)
+		}
+	}
+
+	if position < 0 || position > len(column) {
+		position = len(column)
+	}
+	column = append(column[:position], append([]int{taskID}, column[position:]...)...)
+
+	if err := setBoardPositions(q, column); err != nil {
+		return nil, err
+	}
+
+	if status == currentStatus {
+		return getTask(q, taskID)
+	}
+
+	task, err := updateTaskStatus(q, taskID, status, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Close the gap left in the source column
+	source, err := boardColumnTaskIDs(q, projectID, currentStatus, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := setBoardPositions(q, source); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// SetWIPLimit sets or clears the WIP limit of a project's board column
+func (s *Storage) SetWIPLimit(projectID int, status types.TaskStatus, limit *int) error {
+	// First verify that the project exists
+	projectExists, err := s.projectExists(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to verify project existence: %w", err)
+	}
+	if !projectExists {
+		return fmt.Errorf("project with id %d does not exist", projectID)
+	}
+
+	if limit == nil {
+		_, err = s.db.Exec(`DELETE FROM board_wip_limits WHERE project_id = ? AND status = ?`, projectID, status)
+	} else {
+		_, err = s.db.Exec(`INSERT INTO board_wip_limits (project_id, status, wip_limit) VALUES (?, ?, ?)
+				  ON CONFLICT(project_id, status) DO UPDATE SET wip_limit = excluded.wip_limit`,
+			projectID, status, *limit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set WIP limit: %w", err)
+	}
+
+	return nil
+}
+
+// getWIPLimits returns the configured WIP limits of a project keyed by status
+func (s *Storage) getWIPLimits(projectID int) (map[types.TaskStatus]int, error) {
+	rows, err := s.db.Query(`SELECT status, wip_limit FROM board_wip_limits WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query WIP limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := make(map[types.TaskStatus]int)
+	for rows.Next() {
+		var status types.TaskStatus
+		var limit int
+		if err := rows.Scan(&status, &limit); err != nil {
+			return nil, fmt.Errorf("failed to scan WIP limit: %w", err)
+		}
+		limits[status] = limit
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading WIP limit rows: %w", err)
+	}
+
+	return limits, nil
+}
+
+// boardColumnTaskIDs returns the ordered task IDs of a board column, leaving out excludeTaskID
+func boardColumnTaskIDs(q querier, projectID int, status types.TaskStatus, excludeTaskID int) ([]int, error) {
+	query := `SELECT t.id
+			  FROM tasks t
+			  LEFT JOIN board_positions bp ON bp.task_id = t.id
+			  WHERE t.project_id = ? AND t.status = ? AND t.id != ?
+			  ORDER BY bp.position IS NULL, bp.position, t.priority DESC, t.created_at ASC`
+
+	rows, err := q.Query(query, projectID, status, excludeTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query board column: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan board column task: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading board column rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// setBoardPositions stores dense positions for the given ordered task IDs
+func setBoardPositions(q querier, taskIDs []int) error {
+	query := `INSERT INTO board_positions (task_id, position) VALUES (?, ?)
+			  ON CONFLICT(task_id) DO UPDATE SET position = excluded.position`
+
+	for position, taskID := range taskIDs {
+		if _, err := q.Exec(query, taskID, position); err != nil {
+			return fmt.Errorf("failed to set board position of task %d: %w", taskID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"testing"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestGetBoard(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	pending := createTestTask(t, s, project.ID)
+	active := createTestTask(t, s, project.ID)
+	if _, err := s.UpdateTaskStatus(active.ID, types.TaskStatusInProgress); err != nil {
+		t.Fatalf("Failed to update task status: %v", err)
+	}
+
+	board, err := s.GetBoard(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get board: %v", err)
+	}
+
+	if len(board.Columns) != 4 {
+		t.Fatalf("Expected 4 columns, got %d", len(board.Columns))
+	}
+
+	if len(board.Columns[0].Tasks) != 1 || board.Columns[0].Tasks[0].ID != pending.ID {
+		t.Errorf("Expected pending column to hold task %d", pending.ID)
+	}
+	if len(board.Columns[1].Tasks) != 1 || board.Columns[1].Tasks[0].ID != active.ID {
+		t.Errorf("Expected in_progress column to hold task %d", active.ID)
+	}
+
+	// Test nonexistent project
+	_, err = s.GetBoard(99999)
+	if err == nil {
+		t.Errorf("Expected error for nonexistent project")
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+	third := createTestTask(t, s, project.ID)
+
+	// Reorder within the pending column
+	if _, err := s.MoveTask(third.ID, types.MoveTaskRequest{Status: types.TaskStatusPending, Position: 0}); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+
+	board, err := s.GetBoard(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get board: %v", err)
+	}
+
+	expected := []int{third.ID, first.ID, second.ID}
+	for i, id := range expected {
+		if board.Columns[0].Tasks[i].ID != id {
+			t.Errorf("Expected task %d at position %d, got %d", id, i, board.Columns[0].Tasks[i].ID)
+		}
+	}
+
+	// Moving to completed applies the same side effects as a status update
+	moved, err := s.MoveTask(first.ID, types.MoveTaskRequest{Status: types.TaskStatusCompleted, Position: 5})
+	if err != nil {
+		t.Fatalf("Failed to move task to completed: %v", err)
+	}
+	if moved.Status != types.TaskStatusCompleted {
+		t.Errorf("Expected status completed, got %s", moved.Status)
+	}
+	if moved.CompletedAt == nil {
+		t.Errorf("Expected completed_at to be set")
+	}
+
+	// WIP limits are enforced on entering a column
+	limit := 1
+	if err := s.SetWIPLimit(project.ID, types.TaskStatusInProgress, &limit); err != nil {
+		t.Fatalf("Failed to set WIP limit: %v", err)
+	}
+	if _, err := s.MoveTask(second.ID, types.MoveTaskRequest{Status: types.TaskStatusInProgress}); err != nil {
+		t.Fatalf("Failed to move task within WIP limit: %v", err)
+	}
+	_, err = s.MoveTask(third.ID, types.MoveTaskRequest{Status: types.TaskStatusInProgress})
+	if err == nil || !contains(err.Error(), "WIP limit") {
+		t.Errorf("Expected WIP limit error, got %v", err)
+	}
+
+	// The rejected move must leave the task untouched
+	unchanged, err := s.GetTask(third.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if unchanged.Status != types.TaskStatusPending {
+		t.Errorf("Expected rejected move to keep status pending, got %s", unchanged.Status)
+	}
+
+	// Clearing the limit allows the move
+	if err := s.SetWIPLimit(project.ID, types.TaskStatusInProgress, nil); err != nil {
+		t.Fatalf("Failed to clear WIP limit: %v", err)
+	}
+	if _, err := s.MoveTask(third.ID, types.MoveTaskRequest{Status: types.TaskStatusInProgress}); err != nil {
+		t.Errorf("Expected move to succeed after clearing WIP limit: %v", err)
+	}
+
+	// Test nonexistent task
+	_, err = s.MoveTask(99999, types.MoveTaskRequest{Status: types.TaskStatusPending})
+	if err == nil {
+		t.Errorf("Expected error when moving nonexistent task")
+	}
+}
+
+func TestUpdateTaskStatusKeepsBoardPositions(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+	third := createTestTask(t, s, project.ID)
+	if _, err := s.MoveTask(first.ID, types.MoveTaskRequest{Status: types.TaskStatusInProgress}); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+	if _, err := s.MoveTask(third.ID, types.MoveTaskRequest{Status: types.TaskStatusPending, Position: 0}); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+
+	// A status change appends the task to its new column and closes the gap in the old one
+	if _, err := s.UpdateTaskStatus(third.ID, types.TaskStatusInProgress); err != nil {
+		t.Fatalf("Failed to update task status: %v", err)
+	}
+
+	positions := make(map[int]int)
+	rows, err := s.db.Query(`SELECT task_id, position FROM board_positions`)
+	if err != nil {
+		t.Fatalf("Failed to query board positions: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var taskID, position int
+		if err := rows.Scan(&taskID, &position); err != nil {
+			t.Fatalf("Failed to scan board position: %v", err)
+		}
+		positions[taskID] = position
+	}
+
+	expected := map[int]int{first.ID: 0, third.ID: 1, second.ID: 0}
+	for id, position := range expected {
+		if positions[id] != position {
+			t.Errorf("Expected task %d at position %d, got %d", id, position, positions[id])
+		}
+	}
+
+	// Status changes respect WIP limits like board moves
+	limit := 2
+	if err := s.SetWIPLimit(project.ID, types.TaskStatusInProgress, &limit); err != nil {
+		t.Fatalf("Failed to set WIP limit: %v", err)
+	}
+	if _, err := s.UpdateTaskStatus(second.ID, types.TaskStatusInProgress); err == nil || !contains(err.Error(), "WIP limit") {
+		t.Errorf("Expected WIP limit error, got %v", err)
+	}
+}
@@ -0,0 +1,495 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// defaultBudgetThresholds are the alert thresholds used until SetBudgetThresholds is called
+var defaultBudgetThresholds = []int{80, 100}
+
+// CreateBudget creates a budget on a project or a task
+func (s *Storage) CreateBudget(req types.CreateBudgetRequest) (*types.Budget, error) {
+	if (req.ProjectID == nil) == (req.TaskID == nil) {
+		return nil, fmt.Errorf("a budget needs either a project or a task")
+	}
+
+	if req.ProjectID != nil {
+		projectExists, err := s.projectExists(*req.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify project existence: %w", err)
+		}
+		if !projectExists {
+			return nil, fmt.Errorf("project with id %d does not exist", *req.ProjectID)
+		}
+	} else {
+		exists, err := taskExists(s.db, *req.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify task existence: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("task with id %d does not exist", *req.TaskID)
+		}
+	}
+
+	limit, err := budgetLimitSeconds(req.Hours)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var id int
+	err = s.db.QueryRow(`INSERT INTO budgets (project_id, task_id, period, limit_seconds, thresholds, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)
+			  RETURNING id`,
+		req.ProjectID, req.TaskID, req.Period, limit, formatThresholds(req.Thresholds), now, now).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return s.getBudget(id)
+}
+
+// GetBudget retrieves a budget with its consumption in the current period
+func (s *Storage) GetBudget(id int) (*types.BudgetStatus, error) {
+	budget, err := s.getBudget(id)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.evaluateBudgets(s.db, []types.Budget{*budget}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &statuses[0], nil
+}
+
+// GetBudgets retrieves budgets with their consumption, optionally limited to one project's or task's budgets
+func (s *Storage) GetBudgets(projectID, taskID *int) ([]types.BudgetStatus, error) {
+	condition, args := "1 = 1", []interface{}{}
+	if projectID != nil {
+		condition, args = "project_id = ?", []interface{}{*projectID}
+	} else if taskID != nil {
+		condition, args = "task_id = ?", []interface{}{*taskID}
+	}
+
+	budgets, err := s.getBudgets(s.db, condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluateBudgets(s.db, budgets, time.Now())
+}
+
+// UpdateBudget changes a budget's period, hours and thresholds
+func (s *Storage) UpdateBudget(id int, req types.UpdateBudgetRequest) (*types.Budget, error) {
+	limit, err := budgetLimitSeconds(req.Hours)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`UPDATE budgets SET period = ?, limit_seconds = ?, thresholds = ?, updated_at = ? WHERE id = ?`,
+		req.Period, limit, formatThresholds(req.Thresholds), time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("budget with id %d not found", id)
+	}
+
+	return s.getBudget(id)
+}
+
+// DeleteBudget deletes a budget and its alerts
+func (s *Storage) DeleteBudget(id int) error {
+	result, err := s.db.Exec(`DELETE FROM budgets WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("budget with id %d not found", id)
+	}
+
+	return nil
+}
+
+// GetBudgetAlerts returns the alerts raised when recorded or running time crossed a budget's thresholds, newest first.
+// Acknowledged alerts are left out unless includeAcknowledged is set.
+func (s *Storage) GetBudgetAlerts(includeAcknowledged bool) ([]types.BudgetAlert, error) {
+	condition := "a.acknowledged_at IS NULL"
+	if includeAcknowledged {
+		condition = "1 = 1"
+	}
+	return s.getBudgetAlerts(condition)
+}
+
+// AcknowledgeBudgetAlert marks an alert as seen
+func (s *Storage) AcknowledgeBudgetAlert(id int) (*types.BudgetAlert, error) {
+	_, err := s.db.Exec(`UPDATE budget_alerts SET acknowledged_at = COALESCE(acknowledged_at, ?) WHERE id = ?`, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge budget alert: %w", err)
+	}
+
+	alerts, err := s.getBudgetAlerts("a.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, fmt.Errorf("budget alert with id %d not found", id)
+	}
+	return &alerts[0], nil
+}
+
+// budgetWarningsForTask returns a warning for every exhausted budget that time on a task counts against
+func (s *Storage) budgetWarningsForTask(q querier, taskID int, now time.Time) ([]types.BudgetWarning, error) {
+	budgets, err := s.budgetsForTask(q, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.evaluateBudgets(q, budgets, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []types.BudgetWarning
+	for _, status := range statuses {
+		if status.Exhausted {
+			warnings = append(warnings, types.BudgetWarning{BudgetID: status.ID, Message: describeExhaustedBudget(status)})
+		}
+	}
+	return warnings, nil
+}
+
+// RaiseRunningBudgetAlerts records the alerts for thresholds that running time entries have crossed by now,
+// so a budget does not go past a threshold unnoticed until its timer is stopped
+func (s *Storage) RaiseRunningBudgetAlerts(now time.Time) error {
+	running, err := getRunningTimeEntries(s.db)
+	if err != nil {
+		return err
+	}
+	if len(running) == 0 {
+		return nil
+	}
+
+	taskIDs := make([]int, 0, len(running))
+	for _, entry := range running {
+		taskIDs = append(taskIDs, entry.TaskID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.raiseBudgetAlerts(tx, now, taskIDs...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// raiseBudgetAlerts records an alert for every threshold crossed by the budgets that time on the given
+// tasks counts against and not recorded for the period yet. It runs in the transaction recording the time.
+func (s *Storage) raiseBudgetAlerts(q querier, now time.Time, taskIDs ...int) error {
+	seen := make(map[int]bool)
+	var budgets []types.Budget
+	for _, taskID := range taskIDs {
+		taskBudgets, err := s.budgetsForTask(q, taskID)
+		if err != nil {
+			return err
+		}
+		for _, budget := range taskBudgets {
+			if !seen[budget.ID] {
+				seen[budget.ID] = true
+				budgets = append(budgets, budget)
+			}
+		}
+	}
+
+	statuses, err := s.evaluateBudgets(q, budgets, now)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		for _, threshold := range status.Thresholds {
+			if status.Consumed*100 < threshold*status.Limit {
+				continue
+			}
+			_, err := q.Exec(`INSERT OR IGNORE INTO budget_alerts (budget_id, threshold, period, consumed, limit_seconds, created_at)
+					  VALUES (?, ?, ?, ?, ?, ?)`,
+				status.ID, threshold, status.PeriodLabel, status.Consumed, status.Limit, now)
+			if err != nil {
+				return fmt.Errorf("failed to record budget alert: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// budgetsForTask loads the budgets that time on a task counts against: its project's budgets and
+// those of the task and its parents. UNION stops the walk up the parents at cycles.
+func (s *Storage) budgetsForTask(q querier, taskID int) ([]types.Budget, error) {
+	condition := `project_id = (SELECT project_id FROM tasks WHERE id = ?)
+				  OR task_id IN (WITH RECURSIVE ancestors(id) AS (
+				                     SELECT ?
+				                     UNION
+				                     SELECT t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.id WHERE t.parent_id IS NOT NULL
+				                 )
+				                 SELECT id FROM ancestors)`
+	return s.getBudgets(q, condition, taskID, taskID)
+}
+
+// evaluateBudgets computes each budget's consumption in its current period
+func (s *Storage) evaluateBudgets(q querier, budgets []types.Budget, now time.Time) ([]types.BudgetStatus, error) {
+	statuses := make([]types.BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		status := types.BudgetStatus{Budget: budget, Limit: int(math.Round(budget.Hours * 3600))}
+		status.PeriodLabel, status.PeriodStart, status.PeriodEnd = budgetPeriod(budget.Period, now, s.loc, s.weekStart)
+
+		consumed, err := budgetConsumption(q, budget, status.PeriodStart, status.PeriodEnd, now)
+		if err != nil {
+			return nil, err
+		}
+		status.Consumed = consumed
+		status.Remaining = status.Limit - status.Consumed
+		status.UsedPercent = math.Round(float64(status.Consumed)*1000/float64(status.Limit)) / 10
+		status.Exhausted = status.Consumed >= status.Limit
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// budgetConsumption sums the time tracked against a budget within [from, until) in one query, counting
+// running entries up to now and leaving out paused time. Nil bounds leave the period open.
+// Task budgets include the time tracked on subtasks; UNION stops the walk down at cycles.
+func budgetConsumption(q querier, budget types.Budget, from, until *time.Time, now time.Time) (int, error) {
+	lower, upper := time.Unix(0, 0), now
+	if from != nil {
+		lower = *from
+	}
+	if until != nil {
+		upper = *until
+	}
+
+	condition, scopeID := "t.project_id = ?", 0
+	if budget.ProjectID != nil {
+		scopeID = *budget.ProjectID
+	} else if budget.TaskID != nil {
+		condition = `te.task_id IN (WITH RECURSIVE subtasks(id) AS (
+				                        SELECT ?
+				                        UNION
+				                        SELECT sub.id FROM tasks sub JOIN subtasks s ON sub.parent_id = s.id
+				                    )
+				                    SELECT id FROM subtasks)`
+		scopeID = *budget.TaskID
+	}
+
+	tracked, err := trackedSecondsByTask(q, lower, upper, now, condition, scopeID)
+	if err != nil {
+		return 0, err
+	}
+
+	consumed := 0
+	for _, seconds := range tracked {
+		consumed += seconds
+	}
+	return consumed, nil
+}
+
+// budgetPeriod returns the label and bounds of the period containing now. Total budgets have no bounds.
+func budgetPeriod(period types.BudgetPeriod, now time.Time, loc *time.Location, weekStart time.Weekday) (string, *time.Time, *time.Time) {
+	switch period {
+	case types.BudgetPeriodWeek:
+		start := StartOfWeek(now, loc, weekStart)
+		end := start.AddDate(0, 0, 7)
+		return start.Format("2006-01-02"), &start, &end
+	case types.BudgetPeriodMonth:
+		local := now.In(loc)
+		start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		end := start.AddDate(0, 1, 0)
+		return start.Format("2006-01"), &start, &end
+	default:
+		return string(types.BudgetPeriodTotal), nil, nil
+	}
+}
+
+// describeExhaustedBudget explains which budget is used up, e.g. "Weekly project budget of 40 hours is exhausted (41.5 hours used)"
+func describeExhaustedBudget(status types.BudgetStatus) string {
+	scope := "project"
+	if status.TaskID != nil {
+		scope = "task"
+	}
+
+	switch status.Period {
+	case types.BudgetPeriodWeek:
+		scope = "Weekly " + scope
+	case types.BudgetPeriodMonth:
+		scope = "Monthly " + scope
+	default:
+		scope = strings.ToUpper(scope[:1]) + scope[1:]
+	}
+
+	return fmt.Sprintf("%s budget of %s hours is exhausted (%s hours used)", scope,
+		formatBudgetHours(status.Limit), formatBudgetHours(status.Consumed))
+}
+
+// formatBudgetHours formats seconds as hours with at most two decimal places
+func formatBudgetHours(seconds int) string {
+	return strconv.FormatFloat(math.Round(float64(seconds)/36)/100, 'f', -1, 64)
+}
+
+// budgetLimitSeconds converts a budget's hours to whole seconds
+func budgetLimitSeconds(hours float64) (int, error) {
+	limit := int(math.Round(hours * 3600))
+	if limit <= 0 {
+		return 0, fmt.Errorf("budget hours must be greater than zero")
+	}
+	return limit, nil
+}
+
+// getBudget loads a budget by ID
+func (s *Storage) getBudget(id int) (*types.Budget, error) {
+	budgets, err := s.getBudgets(s.db, "id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, fmt.Errorf("budget with id %d not found", id)
+	}
+	return &budgets[0], nil
+}
+
+// getBudgets loads the budgets matching the given condition
+func (s *Storage) getBudgets(q querier, condition string, args ...interface{}) ([]types.Budget, error) {
+	query := `SELECT id, project_id, task_id, period, limit_seconds, thresholds, created_at, updated_at
+			  FROM budgets
+			  WHERE ` + condition + `
+			  ORDER BY id ASC`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := []types.Budget{}
+	for rows.Next() {
+		var budget types.Budget
+		var limit int
+		var thresholds string
+		err := rows.Scan(&budget.ID, &budget.ProjectID, &budget.TaskID, &budget.Period, &limit, &thresholds,
+			&budget.CreatedAt, &budget.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+
+		budget.Hours = float64(limit) / 3600
+		budget.Thresholds = parseThresholds(thresholds)
+		if len(budget.Thresholds) == 0 {
+			budget.Thresholds = s.thresholds
+		}
+		budgets = append(budgets, budget)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading budget rows: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// getBudgetAlerts loads the alerts matching the given condition, newest first
+func (s *Storage) getBudgetAlerts(condition string, args ...interface{}) ([]types.BudgetAlert, error) {
+	query := `SELECT a.id, a.budget_id, b.project_id, b.task_id, a.threshold, a.period, a.consumed, a.limit_seconds,
+			         a.created_at, a.acknowledged_at
+			  FROM budget_alerts a
+			  JOIN budgets b ON a.budget_id = b.id
+			  WHERE ` + condition + `
+			  ORDER BY a.created_at DESC, a.id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []types.BudgetAlert{}
+	for rows.Next() {
+		var alert types.BudgetAlert
+		var acknowledgedAt sql.NullTime
+		err := rows.Scan(&alert.ID, &alert.BudgetID, &alert.ProjectID, &alert.TaskID, &alert.Threshold, &alert.PeriodLabel,
+			&alert.Consumed, &alert.Limit, &alert.CreatedAt, &acknowledgedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget alert: %w", err)
+		}
+		if acknowledgedAt.Valid {
+			alert.AcknowledgedAt = &acknowledgedAt.Time
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading budget alert rows: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// normalizeThresholds sorts thresholds and drops duplicates
+func normalizeThresholds(thresholds []int) []int {
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+
+	normalized := []int{}
+	for i, threshold := range sorted {
+		if i == 0 || threshold != sorted[i-1] {
+			normalized = append(normalized, threshold)
+		}
+	}
+	return normalized
+}
+
+// formatThresholds stores thresholds as a comma separated list, empty meaning the configured defaults
+func formatThresholds(thresholds []int) string {
+	values := make([]string, 0, len(thresholds))
+	for _, threshold := range normalizeThresholds(thresholds) {
+		values = append(values, strconv.Itoa(threshold))
+	}
+	return strings.Join(values, ",")
+}
+
+// parseThresholds reads a comma separated threshold list, skipping malformed values
+func parseThresholds(value string) []int {
+	var thresholds []int
+	for _, part := range strings.Split(value, ",") {
+		if threshold, err := strconv.Atoi(part); err == nil {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	return thresholds
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestBudgetConsumptionAndAlerts(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	parent := createTestTask(t, s, project.ID)
+	subtask, err := s.CreateTask(types.CreateTaskRequest{ProjectID: project.ID, ParentID: &parent.ID, Title: "Subtask", Priority: 3})
+	if err != nil {
+		t.Fatalf("Failed to create subtask: %v", err)
+	}
+	other := createTestTask(t, s, project.ID)
+
+	taskBudget, err := s.CreateBudget(types.CreateBudgetRequest{TaskID: &parent.ID, Period: types.BudgetPeriodTotal, Hours: 2})
+	if err != nil {
+		t.Fatalf("Failed to create budget: %v", err)
+	}
+	if len(taskBudget.Thresholds) != 2 || taskBudget.Thresholds[0] != 80 {
+		t.Errorf("Expected the default thresholds, got %v", taskBudget.Thresholds)
+	}
+	projectBudget, err := s.CreateBudget(types.CreateBudgetRequest{ProjectID: &project.ID, Period: types.BudgetPeriodTotal, Hours: 10, Thresholds: []int{50, 50}})
+	if err != nil {
+		t.Fatalf("Failed to create budget: %v", err)
+	}
+	if len(projectBudget.Thresholds) != 1 {
+		t.Errorf("Expected duplicate thresholds to be dropped, got %v", projectBudget.Thresholds)
+	}
+
+	// Time on the subtask counts against its parent's budget, time on other tasks does not
+	start := time.Now().Add(-4 * time.Hour).Truncate(time.Second)
+	createStoppedEntry(t, s, parent.ID, start, time.Hour)
+	createStoppedEntry(t, s, subtask.ID, start.Add(time.Hour), 45*time.Minute)
+	createStoppedEntry(t, s, other.ID, start.Add(2*time.Hour), time.Hour)
+
+	status, err := s.GetBudget(taskBudget.ID)
+	if err != nil {
+		t.Fatalf("Failed to get budget: %v", err)
+	}
+	if status.Consumed != 105*60 || status.Remaining != 15*60 || status.Exhausted {
+		t.Errorf("Expected 105 minutes used and 15 remaining, got %+v", status)
+	}
+	if status.UsedPercent != 87.5 {
+		t.Errorf("Expected 87.5%% used, got %v", status.UsedPercent)
+	}
+
+	alerts, err := s.GetBudgetAlerts(false)
+	if err != nil {
+		t.Fatalf("Failed to get alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].BudgetID != taskBudget.ID || alerts[0].Threshold != 80 {
+		t.Fatalf("Expected one 80%% alert for the task budget, got %+v", alerts)
+	}
+
+	// Alerts are raised once per threshold and period
+	if alerts, _ = s.GetBudgetAlerts(false); len(alerts) != 1 {
+		t.Errorf("Expected the alert not to be raised again, got %d alerts", len(alerts))
+	}
+
+	if _, err := s.AcknowledgeBudgetAlert(alerts[0].ID); err != nil {
+		t.Fatalf("Failed to acknowledge alert: %v", err)
+	}
+
+	createStoppedEntry(t, s, subtask.ID, start.Add(3*time.Hour), 30*time.Minute)
+
+	// Starting a timer on the exhausted budget warns but still starts
+	started, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: subtask.ID})
+	if err != nil {
+		t.Fatalf("Failed to start time entry: %v", err)
+	}
+	if len(started.BudgetWarnings) != 1 || started.BudgetWarnings[0].BudgetID != taskBudget.ID {
+		t.Fatalf("Expected a warning for the task budget, got %+v", started.BudgetWarnings)
+	}
+	if !strings.Contains(started.BudgetWarnings[0].Message, "budget of 2 hours is exhausted") {
+		t.Errorf("Unexpected warning message: %s", started.BudgetWarnings[0].Message)
+	}
+
+	alerts, err = s.GetBudgetAlerts(false)
+	if err != nil {
+		t.Fatalf("Failed to get alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Threshold != 100 {
+		t.Errorf("Expected only the unacknowledged 100%% alert, got %+v", alerts)
+	}
+	if alerts, _ = s.GetBudgetAlerts(true); len(alerts) != 2 {
+		t.Errorf("Expected 2 alerts including acknowledged ones, got %d", len(alerts))
+	}
+}
+
+func TestBudgetAlertsRaisedWhenTimeIsRecorded(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	budget, err := s.CreateBudget(types.CreateBudgetRequest{TaskID: &task.ID, Period: types.BudgetPeriodTotal, Hours: 1.25})
+	if err != nil {
+		t.Fatalf("Failed to create budget: %v", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	started, err := s.StartTimeEntryAt(types.StartTimeEntryRequest{TaskID: task.ID}, now.Add(-90*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to start time entry: %v", err)
+	}
+
+	// Reading budgets never records alerts, even while a running timer is past a threshold
+	if _, err := s.GetBudgets(nil, nil); err != nil {
+		t.Fatalf("Failed to get budgets: %v", err)
+	}
+	if alerts, _ := s.GetBudgetAlerts(true); len(alerts) != 0 {
+		t.Fatalf("Expected no alerts before time is recorded, got %+v", alerts)
+	}
+
+	// Paused time does not count against the budget
+	_, err = s.db.Exec(`INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at) VALUES (?, ?, ?)`,
+		started.ID, now.Add(-60*time.Minute), now.Add(-40*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create pause: %v", err)
+	}
+	if _, err := s.StopTimeEntryAt(task.ID, types.StopTimeEntryRequest{}, now); err != nil {
+		t.Fatalf("Failed to stop time entry: %v", err)
+	}
+
+	alerts, err := s.GetBudgetAlerts(true)
+	if err != nil {
+		t.Fatalf("Failed to get alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].BudgetID != budget.ID || alerts[0].Threshold != 80 || alerts[0].Consumed != 70*60 {
+		t.Errorf("Expected one 80%% alert for 70 minutes, got %+v", alerts)
+	}
+}
+
+func TestBudgetPeriod(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, loc) // Wednesday
+
+	label, start, end := budgetPeriod(types.BudgetPeriodWeek, now, loc, time.Monday)
+	if label != "2024-05-13" || !start.Equal(time.Date(2024, time.May, 13, 0, 0, 0, 0, loc)) || !end.Equal(start.AddDate(0, 0, 7)) {
+		t.Errorf("Unexpected week period %s from %v to %v", label, start, end)
+	}
+
+	label, start, end = budgetPeriod(types.BudgetPeriodMonth, now, loc, time.Monday)
+	if label != "2024-05" || !start.Equal(time.Date(2024, time.May, 1, 0, 0, 0, 0, loc)) || !end.Equal(time.Date(2024, time.June, 1, 0, 0, 0, 0, loc)) {
+		t.Errorf("Unexpected month period %s from %v to %v", label, start, end)
+	}
+
+	if label, start, end = budgetPeriod(types.BudgetPeriodTotal, now, loc, time.Monday); label != "total" || start != nil || end != nil {
+		t.Errorf("Expected an open total period, got %s", label)
+	}
+}
+
+func TestRunningBudgetAlerts(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	budget, err := s.CreateBudget(types.CreateBudgetRequest{TaskID: &task.ID, Period: types.BudgetPeriodTotal, Hours: 1})
+	if err != nil {
+		t.Fatalf("Failed to create budget: %v", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if _, err := s.StartTimeEntryAt(types.StartTimeEntryRequest{TaskID: task.ID}, now.Add(-50*time.Minute)); err != nil {
+		t.Fatalf("Failed to start time entry: %v", err)
+	}
+
+	// A running timer raises the thresholds it has crossed by now, without being stopped
+	if err := s.RaiseRunningBudgetAlerts(now); err != nil {
+		t.Fatalf("Failed to raise running budget alerts: %v", err)
+	}
+	alerts, err := s.GetBudgetAlerts(false)
+	if err != nil {
+		t.Fatalf("Failed to get alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].BudgetID != budget.ID || alerts[0].Threshold != 80 || alerts[0].Consumed != 50*60 {
+		t.Fatalf("Expected one 80%% alert for 50 minutes, got %+v", alerts)
+	}
+
+	// Later checks only add the thresholds crossed since
+	if err := s.RaiseRunningBudgetAlerts(now.Add(5 * time.Minute)); err != nil {
+		t.Fatalf("Failed to raise running budget alerts: %v", err)
+	}
+	if alerts, _ := s.GetBudgetAlerts(false); len(alerts) != 1 {
+		t.Errorf("Expected the 80%% alert not to repeat, got %+v", alerts)
+	}
+	if err := s.RaiseRunningBudgetAlerts(now.Add(10 * time.Minute)); err != nil {
+		t.Fatalf("Failed to raise running budget alerts: %v", err)
+	}
+	if alerts, _ := s.GetBudgetAlerts(false); len(alerts) != 2 || alerts[0].Threshold != 100 {
+		t.Errorf("Expected a 100%% alert once the budget is used up, got %+v", alerts)
+	}
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// GetCalendarFeed returns the tasks with a due date and the time entries stopped since the given time,
+// optionally limited to one project
+func (s *Storage) GetCalendarFeed(projectID *int, since time.Time) (*types.CalendarFeed, error) {
+	taskCondition, entryCondition := "t.due_date IS NOT NULL", "te.end_time IS NOT NULL AND te.end_time >= ?"
+	entryArgs := []interface{}{since}
+	var taskArgs []interface{}
+	if projectID != nil {
+		taskCondition += " AND t.project_id = ?"
+		taskArgs = append(taskArgs, *projectID)
+		entryCondition += " AND t.project_id = ?"
+		entryArgs = append(entryArgs, *projectID)
+	}
+
+	tasks, err := s.getTasksWithProjects(taskCondition, taskArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at,
+			         te.updated_at, te.revision, t.title, p.id, p.name
+			  FROM time_entries te
+			  JOIN tasks t ON te.task_id = t.id
+			  JOIN projects p ON t.project_id = p.id
+			  WHERE ` + entryCondition + `
+			  ORDER BY te.start_time ASC, te.id ASC`
+
+	rows, err := s.db.Query(query, entryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar time entries: %w", err)
+	}
+	defer rows.Close()
+
+	feed := &types.CalendarFeed{Tasks: tasks, TimeEntries: []types.CalendarTimeEntry{}}
+	for rows.Next() {
+		var entry types.CalendarTimeEntry
+		var updatedAt *time.Time
+		err := rows.Scan(
+			&entry.ID,
+			&entry.TaskID,
+			&entry.StartTime,
+			&entry.EndTime,
+			&entry.Duration,
+			&entry.Description,
+			&entry.CreatedAt,
+			&updatedAt,
+			&entry.Revision,
+			&entry.TaskTitle,
+			&entry.ProjectID,
+			&entry.ProjectName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar time entry: %w", err)
+		}
+
+		// Entries not changed since they were recorded have no update time of their own
+		entry.UpdatedAt = entry.CreatedAt
+		if updatedAt != nil {
+			entry.UpdatedAt = *updatedAt
+		}
+		feed.TimeEntries = append(feed.TimeEntries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading calendar time entry rows: %w", err)
+	}
+
+	return feed, nil
+}
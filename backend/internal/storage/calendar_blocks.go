@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// ImportCalendarBlocks stores the occurrences of a calendar's events within [from, until) as blocks.
+// Blocks are matched by calendar, UID and start time, so importing the calendar again updates them.
+// Blocks in the window that the calendar no longer holds are removed unless they were converted to
+// time entries.
+func (s *Storage) ImportCalendarBlocks(calendar string, blocks []types.CalendarBlock, from, until time.Time) (*types.CalendarImportResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	result := &types.CalendarImportResult{Calendar: calendar, From: from, Until: until}
+	now := time.Now()
+	kept := make(map[int]bool)
+
+	for _, block := range blocks {
+		var id int
+		var summary string
+		var endTime time.Time
+		var allDay bool
+		err := tx.QueryRow(`SELECT id, summary, end_time, all_day FROM calendar_blocks WHERE calendar = ? AND uid = ? AND start_time = ?`,
+			calendar, block.UID, block.StartTime).Scan(&id, &summary, &endTime, &allDay)
+		switch {
+		case err == sql.ErrNoRows:
+			err = tx.QueryRow(`INSERT INTO calendar_blocks (calendar, uid, summary, start_time, end_time, all_day, created_at, updated_at)
+					  VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+					  RETURNING id`,
+				calendar, block.UID, block.Summary, block.StartTime, block.EndTime, block.AllDay, now, now).Scan(&id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create calendar block: %w", err)
+			}
+			result.Created++
+		case err != nil:
+			return nil, fmt.Errorf("failed to look up calendar block: %w", err)
+		case summary != block.Summary || !endTime.Equal(block.EndTime) || allDay != block.AllDay:
+			if _, err := tx.Exec(`UPDATE calendar_blocks SET summary = ?, end_time = ?, all_day = ?, updated_at = ? WHERE id = ?`,
+				block.Summary, block.EndTime, block.AllDay, now, id); err != nil {
+				return nil, fmt.Errorf("failed to update calendar block: %w", err)
+			}
+			result.Updated++
+		}
+		kept[id] = true
+	}
+
+	existing, err := getCalendarBlocks(tx, "calendar = ? AND start_time >= ? AND start_time < ?", calendar, from, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range existing {
+		if kept[block.ID] || block.TimeEntryID != nil {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM calendar_blocks WHERE id = ?`, block.ID); err != nil {
+			return nil, fmt.Errorf("failed to remove calendar block: %w", err)
+		}
+		result.Removed++
+	}
+
+	result.Blocks, err = getCalendarBlocks(tx, "calendar = ? AND start_time >= ? AND start_time < ?", calendar, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit calendar import transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetCalendarBlocks returns the calendar blocks overlapping [from, until), ordered by start time
+func (s *Storage) GetCalendarBlocks(from, until time.Time) ([]types.CalendarBlock, error) {
+	return getCalendarBlocks(s.db, "start_time < ? AND end_time > ?", until, from)
+}
+
+// DeleteCalendarBlock deletes a calendar block. A time entry converted from it is kept.
+func (s *Storage) DeleteCalendarBlock(id int) error {
+	result, err := s.db.Exec(`DELETE FROM calendar_blocks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar block: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("calendar block with id %d not found", id)
+	}
+
+	return nil
+}
+
+// ConvertCalendarBlocks logs calendar blocks as stopped time entries on a task, described by the event's
+// summary. Blocks that are all-day, have not ended, were converted before or overlap existing time entries
+// are skipped with the reason. The configurable time entry rules are skipped, as meetings are logged afterwards.
+func (s *Storage) ConvertCalendarBlocks(blockIDs []int, taskID int) (*types.ConvertCalendarBlocksResult, error) {
+	return s.ConvertCalendarBlocksAt(blockIDs, taskID, time.Now())
+}
+
+// ConvertCalendarBlocksAt converts calendar blocks to time entries, treating blocks ending after now as not ended
+func (s *Storage) ConvertCalendarBlocksAt(blockIDs []int, taskID int, now time.Time) (*types.ConvertCalendarBlocksResult, error) {
+	if err := validateTaskTrackable(s.db, taskID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	result := &types.ConvertCalendarBlocksResult{Blocks: []types.CalendarBlockConversion{}}
+	for _, blockID := range blockIDs {
+		conversion := types.CalendarBlockConversion{BlockID: blockID}
+
+		id, err := s.convertCalendarBlock(tx, blockID, taskID, now, &conversion)
+		if err != nil {
+			return nil, err
+		}
+		if id != 0 {
+			conversion.TimeEntryID = &id
+			result.Converted++
+		} else {
+			result.Skipped++
+		}
+		result.Blocks = append(result.Blocks, conversion)
+	}
+
+	if result.Converted > 0 {
+		if err := s.raiseBudgetAlerts(tx, now, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit calendar block conversion transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// convertCalendarBlock logs one block as a time entry and returns its ID, or 0 with the reason it was skipped
+func (s *Storage) convertCalendarBlock(tx querier, blockID, taskID int, now time.Time, conversion *types.CalendarBlockConversion) (int, error) {
+	blocks, err := getCalendarBlocks(tx, "id = ?", blockID)
+	if err != nil {
+		return 0, err
+	}
+	if len(blocks) == 0 {
+		conversion.Skipped = "calendar block not found"
+		return 0, nil
+	}
+	block := blocks[0]
+
+	switch {
+	case block.TimeEntryID != nil:
+		conversion.Skipped = fmt.Sprintf("already converted to time entry %d", *block.TimeEntryID)
+		return 0, nil
+	case block.AllDay:
+		conversion.Skipped = "all-day events are not logged as time"
+		return 0, nil
+	case block.EndTime.After(now):
+		conversion.Skipped = "event has not ended yet"
+		return 0, nil
+	case !block.EndTime.After(block.StartTime):
+		conversion.Skipped = "event has no duration"
+		return 0, nil
+	}
+
+	err = checkNoOverlappingTimeEntries(tx, taskID, block.StartTime, &block.EndTime, 0)
+	if err == nil {
+		err = s.validateNoCrossTaskOverlap(tx, taskID, block.StartTime, &block.EndTime, 0)
+	}
+	var ruleErr *TimeEntryRuleError
+	if errors.As(err, &ruleErr) {
+		conversion.Skipped = ruleErr.Message
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = tx.QueryRow(`INSERT INTO time_entries (task_id, start_time, end_time, duration, description, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?)
+			  RETURNING id`,
+		taskID, block.StartTime, block.EndTime, int(block.EndTime.Sub(block.StartTime).Seconds()), truncateRunes(block.Summary, 500), now).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create time entry from calendar block: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE calendar_blocks SET time_entry_id = ?, updated_at = ? WHERE id = ?`, id, now, blockID); err != nil {
+		return 0, fmt.Errorf("failed to link calendar block to time entry: %w", err)
+	}
+
+	return id, nil
+}
+
+// getCalendarBlocks returns the calendar blocks matching a condition, ordered by start time
+func getCalendarBlocks(q querier, condition string, args ...interface{}) ([]types.CalendarBlock, error) {
+	query := `SELECT id, calendar, uid, summary, start_time, end_time, all_day, time_entry_id, created_at, updated_at
+			  FROM calendar_blocks
+			  WHERE ` + condition + `
+			  ORDER BY start_time ASC, id ASC`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []types.CalendarBlock{}
+	for rows.Next() {
+		var block types.CalendarBlock
+		err := rows.Scan(
+			&block.ID,
+			&block.Calendar,
+			&block.UID,
+			&block.Summary,
+			&block.StartTime,
+			&block.EndTime,
+			&block.AllDay,
+			&block.TimeEntryID,
+			&block.CreatedAt,
+			&block.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar block: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading calendar block rows: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// blockedSeconds returns how much of [from, until) the blocks cover, counting overlapping blocks once
+func blockedSeconds(blocks []types.CalendarBlock, from, until time.Time) int {
+	intervals := make([]interval, 0, len(blocks))
+	for _, block := range blocks {
+		intervals = append(intervals, interval{block.StartTime, block.EndTime})
+	}
+	return intervalSeconds(mergeIntervals(clipIntervals(intervals, from, until)))
+}
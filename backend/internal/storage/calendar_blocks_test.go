@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestImportCalendarBlocks(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	from := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 0, 7)
+	standup := func(day int, summary string) types.CalendarBlock {
+		start := from.AddDate(0, 0, day).Add(9 * time.Hour)
+		return types.CalendarBlock{UID: "standup", Summary: summary, StartTime: start, EndTime: start.Add(15 * time.Minute)}
+	}
+
+	result, err := s.ImportCalendarBlocks("work", []types.CalendarBlock{standup(0, "Standup"), standup(1, "Standup"), standup(2, "Standup")}, from, until)
+	if err != nil {
+		t.Fatalf("Failed to import calendar blocks: %v", err)
+	}
+	if result.Created != 3 || len(result.Blocks) != 3 {
+		t.Fatalf("Expected 3 created blocks, got %+v", result)
+	}
+
+	// Importing again updates changed events and removes the ones no longer in the calendar
+	result, err = s.ImportCalendarBlocks("work", []types.CalendarBlock{standup(0, "Standup"), standup(1, "Daily standup")}, from, until)
+	if err != nil {
+		t.Fatalf("Failed to reimport calendar blocks: %v", err)
+	}
+	if result.Created != 0 || result.Updated != 1 || result.Removed != 1 || len(result.Blocks) != 2 {
+		t.Fatalf("Expected 1 updated and 1 removed block, got %+v", result)
+	}
+	if result.Blocks[1].Summary != "Daily standup" {
+		t.Errorf("Expected the updated summary, got %q", result.Blocks[1].Summary)
+	}
+
+	// Another calendar's blocks are left alone
+	if _, err := s.ImportCalendarBlocks("personal", nil, from, until); err != nil {
+		t.Fatalf("Failed to import empty calendar: %v", err)
+	}
+	blocks, err := s.GetCalendarBlocks(from, until)
+	if err != nil {
+		t.Fatalf("Failed to get calendar blocks: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Errorf("Expected 2 blocks, got %d", len(blocks))
+	}
+}
+
+func TestConvertCalendarBlocks(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	meetings := createTestTask(t, s, project.ID)
+
+	start := time.Now().Add(-4 * time.Hour).Truncate(time.Second)
+	from := start.Add(-time.Hour)
+	until := start.Add(24 * time.Hour)
+	result, err := s.ImportCalendarBlocks("work", []types.CalendarBlock{
+		{UID: "review", Summary: "Design review", StartTime: start, EndTime: start.Add(time.Hour)},
+		{UID: "later", Summary: "Planning", StartTime: start.Add(6 * time.Hour), EndTime: start.Add(7 * time.Hour)},
+		{UID: "sync", Summary: "Sync", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(150 * time.Minute)},
+	}, from, until)
+	if err != nil {
+		t.Fatalf("Failed to import calendar blocks: %v", err)
+	}
+	review, sync, later := result.Blocks[0], result.Blocks[1], result.Blocks[2]
+
+	// The sync overlaps time already logged on the task
+	createStoppedEntry(t, s, meetings.ID, start.Add(2*time.Hour), 10*time.Minute)
+
+	conversion, err := s.ConvertCalendarBlocks([]int{review.ID, sync.ID, later.ID, 9999}, meetings.ID)
+	if err != nil {
+		t.Fatalf("Failed to convert calendar blocks: %v", err)
+	}
+	if conversion.Converted != 1 || conversion.Skipped != 3 {
+		t.Fatalf("Expected 1 converted and 3 skipped blocks, got %+v", conversion)
+	}
+	if conversion.Blocks[0].TimeEntryID == nil {
+		t.Fatal("Expected the review to be converted")
+	}
+	for _, skipped := range conversion.Blocks[1:] {
+		if skipped.Skipped == "" || skipped.TimeEntryID != nil {
+			t.Errorf("Expected block %d to be skipped with a reason, got %+v", skipped.BlockID, skipped)
+		}
+	}
+
+	entry, err := s.GetTimeEntry(*conversion.Blocks[0].TimeEntryID)
+	if err != nil {
+		t.Fatalf("Failed to get converted time entry: %v", err)
+	}
+	if entry.Description != "Design review" || entry.Duration == nil || *entry.Duration != 3600 || !entry.StartTime.Equal(start) {
+		t.Errorf("Unexpected converted time entry %+v", entry)
+	}
+
+	// Converting again is skipped, and converted blocks survive a reimport without them
+	conversion, err = s.ConvertCalendarBlocks([]int{review.ID}, meetings.ID)
+	if err != nil || conversion.Skipped != 1 {
+		t.Fatalf("Expected the converted block to be skipped, got %+v, %v", conversion, err)
+	}
+	result, err = s.ImportCalendarBlocks("work", nil, from, until)
+	if err != nil {
+		t.Fatalf("Failed to reimport calendar: %v", err)
+	}
+	if result.Removed != 2 || len(result.Blocks) != 1 || result.Blocks[0].ID != review.ID {
+		t.Errorf("Expected only the converted block to remain, got %+v", result)
+	}
+}
+
+func TestTimelineCalendarBlocks(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	weekStart := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return weekStart.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	_, err := s.ImportCalendarBlocks("work", []types.CalendarBlock{
+		{UID: "a", Summary: "Planning", StartTime: at(9, 0), EndTime: at(10, 0)},
+		{UID: "b", Summary: "Overlapping call", StartTime: at(9, 30), EndTime: at(10, 30)},
+		{UID: "c", Summary: "Late call", StartTime: at(23, 0), EndTime: at(25, 0)},
+	}, weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Failed to import calendar blocks: %v", err)
+	}
+
+	timeline, err := s.GetWeekTimeline(weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Failed to get timeline: %v", err)
+	}
+
+	// Overlapping blocks count once; the late call is split across midnight
+	monday, tuesday := timeline.Days[0], timeline.Days[1]
+	if len(monday.Blocks) != 3 || monday.Blocked != 150*60 {
+		t.Errorf("Expected 3 blocks covering 150 minutes on Monday, got %d covering %d seconds", len(monday.Blocks), monday.Blocked)
+	}
+	if len(tuesday.Blocks) != 1 || tuesday.Blocked != 3600 {
+		t.Errorf("Expected 1 block covering an hour on Tuesday, got %d covering %d seconds", len(tuesday.Blocks), tuesday.Blocked)
+	}
+	if monday.Total != 0 {
+		t.Errorf("Expected blocks not to count as tracked time, got %d", monday.Total)
+	}
+}
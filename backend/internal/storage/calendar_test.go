@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestGetCalendarFeedRevisions(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	entry := createStoppedEntry(t, s, task.ID, start, time.Hour)
+
+	feed, err := s.GetCalendarFeed(nil, start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get calendar feed: %v", err)
+	}
+	if len(feed.TimeEntries) != 1 || feed.TimeEntries[0].Revision != 0 || !feed.TimeEntries[0].UpdatedAt.Equal(entry.CreatedAt) {
+		t.Fatalf("Expected the first revision updated when created, got %+v", feed.TimeEntries)
+	}
+
+	// Correcting a stopped entry makes a new revision
+	end := start.Add(90 * time.Minute)
+	_, err = s.UpdateTimeEntry(entry.ID, types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: start, EndTime: &end, Description: "Corrected"})
+	if err != nil {
+		t.Fatalf("Failed to update time entry: %v", err)
+	}
+
+	feed, err = s.GetCalendarFeed(nil, start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get calendar feed: %v", err)
+	}
+	if feed.TimeEntries[0].Revision != 1 || !feed.TimeEntries[0].UpdatedAt.After(entry.CreatedAt) {
+		t.Errorf("Expected a second revision with a later update time, got %+v", feed.TimeEntries[0])
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// GetCapacity compares the working time available over the given number of days, starting now, with the
+// work due in them. Available time is the configured working hours outside time off, less timed calendar
+// blocks. The work on an open task is its estimate, or the default task duration, less the time tracked
+// on it, and counts on the day it is due; overdue tasks count on the first day. A day is overloaded when
+// work is due on it and more work is due by its end than time is available until then.
+func (s *Storage) GetCapacity(days int, now time.Time) (*types.Capacity, error) {
+	start := startOfDay(now, s.loc)
+	end := start.AddDate(0, 0, days)
+
+	off, err := s.timeOffDates(now, end)
+	if err != nil {
+		return nil, err
+	}
+	working := workingIntervals(now, end, s.loc, s.hours, off)
+
+	calendar, err := s.GetCalendarBlocks(now, end)
+	if err != nil {
+		return nil, err
+	}
+	var blocked []interval
+	for _, block := range calendar {
+		if !block.AllDay {
+			blocked = append(blocked, interval{block.StartTime, block.EndTime})
+		}
+	}
+	blocked = intersectIntervals(working, mergeIntervals(blocked))
+
+	// Only the open tasks due within the window count, so only their tracked time is summed
+	due := `t.due_date IS NOT NULL AND t.status IN ('pending', 'in_progress') AND t.due_date < ?`
+	tasks, err := s.getTasksWithProjects(due, end)
+	if err != nil {
+		return nil, err
+	}
+	estimates, err := getTaskEstimates(s.db)
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := trackedSecondsByTask(s.db, time.Unix(0, 0), now, now, due, end)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := &types.Capacity{
+		Timezone: s.loc.String(),
+		Start:    now.In(s.loc),
+		End:      end,
+		Days:     make([]types.CapacityDay, days),
+		Weeks:    []types.CapacityWeek{},
+	}
+
+	index := make(map[string]int)
+	for i := range capacity.Days {
+		dayStart := start.AddDate(0, 0, i)
+		dayEnd := start.AddDate(0, 0, i+1)
+		date := dayStart.Format("2006-01-02")
+		index[date] = i
+
+		dayWorking := intervalSeconds(clipIntervals(working, dayStart, dayEnd)) / 60
+		dayBlocked := intervalSeconds(clipIntervals(blocked, dayStart, dayEnd)) / 60
+		capacity.Days[i] = types.CapacityDay{
+			Date:             date,
+			TimeOff:          off[date],
+			WorkingMinutes:   dayWorking,
+			BlockedMinutes:   dayBlocked,
+			AvailableMinutes: dayWorking - dayBlocked,
+			Tasks:            []types.CapacityTask{},
+		}
+	}
+
+	for _, task := range tasks {
+		minutes, ok := estimates[task.ID]
+		if !ok {
+			minutes = s.taskMinutes
+		}
+		// Round partly worked minutes up
+		remaining := (minutes*60 - tracked[task.ID] + 59) / 60
+		if remaining <= 0 {
+			continue
+		}
+
+		i, ok := index[task.DueDate.In(s.loc).Format("2006-01-02")]
+		overdue := !ok
+		if overdue {
+			i = 0
+		}
+		capacity.Days[i].WorkloadMinutes += remaining
+		capacity.Days[i].Tasks = append(capacity.Days[i].Tasks, types.CapacityTask{
+			TaskID:    task.ID,
+			TaskTitle: task.Title,
+			ProjectID: task.ProjectID,
+			DueDate:   *task.DueDate,
+			Minutes:   remaining,
+			Overdue:   overdue,
+		})
+	}
+
+	var week *types.CapacityWeek
+	for i := range capacity.Days {
+		day := &capacity.Days[i]
+		capacity.AvailableMinutes += day.AvailableMinutes
+		capacity.WorkloadMinutes += day.WorkloadMinutes
+		day.CumulativeAvailable = capacity.AvailableMinutes
+		day.CumulativeWorkload = capacity.WorkloadMinutes
+		day.Overloaded = day.WorkloadMinutes > 0 && day.CumulativeWorkload > day.CumulativeAvailable
+		if day.Overloaded {
+			capacity.OverloadedDays++
+		}
+
+		weekStart := StartOfWeek(start.AddDate(0, 0, i), s.loc, s.weekStart)
+		if week == nil || !week.Start.Equal(weekStart) {
+			capacity.Weeks = append(capacity.Weeks, types.CapacityWeek{Week: weekLabel(weekStart), Start: weekStart})
+			week = &capacity.Weeks[len(capacity.Weeks)-1]
+		}
+		week.AvailableMinutes += day.AvailableMinutes
+		week.WorkloadMinutes += day.WorkloadMinutes
+		week.Overloaded = week.WorkloadMinutes > week.AvailableMinutes
+		if day.Overloaded {
+			week.OverloadedDays++
+		}
+	}
+
+	return capacity, nil
+}
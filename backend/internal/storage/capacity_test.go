@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestGetCapacity(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.SetCalendar(time.UTC, time.Monday)
+
+	project := createTestProject(t, s)
+	monday := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+
+	newTask := func(title string, due time.Time, minutes *int) *types.Task {
+		task, err := s.CreateTask(types.CreateTaskRequest{ProjectID: project.ID, Title: title, DueDate: &due})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if minutes != nil {
+			if _, err := s.SetTaskEstimate(task.ID, minutes); err != nil {
+				t.Fatalf("Failed to set estimate: %v", err)
+			}
+		}
+		return task
+	}
+	minutes := func(m int) *int { return &m }
+
+	big := newTask("Big", monday, minutes(600))
+	newTask("Tuesday", monday.AddDate(0, 0, 1), minutes(60))
+	wednesday := newTask("Wednesday", monday.AddDate(0, 0, 2), nil)
+	overdue := newTask("Overdue", monday.AddDate(0, 0, -6), minutes(120))
+	done := newTask("Done", monday.AddDate(0, 0, 1), minutes(60))
+	if _, err := s.UpdateTaskStatus(done.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	// Half of Wednesday's default hour is already tracked
+	createStoppedEntry(t, s, wednesday.ID, time.Now().Add(-48*time.Hour), 30*time.Minute)
+
+	if _, err := s.CreateTimeOff(types.CreateTimeOffRequest{StartDate: "2030-01-09", Note: "Holiday"}); err != nil {
+		t.Fatalf("Failed to create time off: %v", err)
+	}
+	meeting := []types.CalendarBlock{{UID: "review", Summary: "Review", StartTime: monday.Add(10 * time.Hour), EndTime: monday.Add(12 * time.Hour)}}
+	if _, err := s.ImportCalendarBlocks("work", meeting, monday, monday.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("Failed to import calendar blocks: %v", err)
+	}
+
+	capacity, err := s.GetCapacity(7, monday.Add(8*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get capacity: %v", err)
+	}
+
+	expected := []struct {
+		available, workload int
+		overloaded          bool
+	}{
+		{360, 720, true},
+		{480, 60, false},
+		{0, 30, false},
+		{480, 0, false},
+		{480, 0, false},
+		{0, 0, false},
+		{0, 0, false},
+	}
+	for i, e := range expected {
+		day := capacity.Days[i]
+		if day.AvailableMinutes != e.available || day.WorkloadMinutes != e.workload || day.Overloaded != e.overloaded {
+			t.Errorf("Day %s: expected %d available, %d due, overloaded %v, got %+v", day.Date, e.available, e.workload, e.overloaded, day)
+		}
+	}
+
+	first := capacity.Days[0]
+	if first.BlockedMinutes != 120 || len(first.Tasks) != 2 || first.Tasks[0].TaskID != big.ID {
+		t.Fatalf("Expected the meeting blocked and two tasks on Monday, got %+v", first)
+	}
+	for _, task := range first.Tasks {
+		if task.Overdue != (task.TaskID == overdue.ID) {
+			t.Errorf("Expected only the overdue task flagged, got %+v", task)
+		}
+	}
+	if !capacity.Days[2].TimeOff || capacity.OverloadedDays != 1 {
+		t.Errorf("Expected Wednesday off and one overloaded day, got %+v", capacity)
+	}
+
+	if len(capacity.Weeks) != 1 || capacity.Weeks[0].Week != "2030-W02" || capacity.Weeks[0].AvailableMinutes != 1800 ||
+		capacity.Weeks[0].WorkloadMinutes != 810 || capacity.Weeks[0].Overloaded || capacity.Weeks[0].OverloadedDays != 1 {
+		t.Errorf("Unexpected week totals %+v", capacity.Weeks)
+	}
+}
+
+func TestTimeOff(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	leave, err := s.CreateTimeOff(types.CreateTimeOffRequest{StartDate: "2030-07-01", EndDate: "2030-07-14", Note: "Summer"})
+	if err != nil {
+		t.Fatalf("Failed to create time off: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  types.CreateTimeOffRequest
+		err  string
+	}{
+		{"bad start", types.CreateTimeOffRequest{StartDate: "July 1st"}, "invalid start date"},
+		{"bad end", types.CreateTimeOffRequest{StartDate: "2030-07-01", EndDate: "2030-7-2"}, "invalid end date"},
+		{"reversed", types.CreateTimeOffRequest{StartDate: "2030-07-02", EndDate: "2030-07-01"}, "must not be before"},
+		{"too long", types.CreateTimeOffRequest{StartDate: "2030-01-01", EndDate: "2031-01-02"}, "must not be longer"},
+	}
+	for _, tt := range tests {
+		if _, err := s.CreateTimeOff(tt.req); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	entries, err := s.GetTimeOff("2030-07-14", "")
+	if err != nil || len(entries) != 1 || entries[0].ID != leave.ID {
+		t.Fatalf("Expected the leave overlapping its last day, got %+v, %v", entries, err)
+	}
+	if entries, _ := s.GetTimeOff("2030-07-15", ""); len(entries) != 0 {
+		t.Errorf("Expected no time off after the leave, got %+v", entries)
+	}
+
+	// Working time skips the days off
+	s.SetCalendar(time.UTC, time.Monday)
+	working, err := s.workingTime(time.Date(2030, 6, 24, 0, 0, 0, 0, time.UTC), time.Date(2030, 7, 22, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to get working time: %v", err)
+	}
+	if hours := intervalSeconds(working) / 3600; hours != 2*5*8 {
+		t.Errorf("Expected two working weeks around the leave, got %d hours", hours)
+	}
+
+	if err := s.DeleteTimeOff(leave.ID); err != nil {
+		t.Fatalf("Failed to delete time off: %v", err)
+	}
+	if err := s.DeleteTimeOff(leave.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected time off to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// GetTimeEntryConflicts finds pairs of time entries on different tasks that overlap within [from, to)
+// and proposes ways to resolve each one. Running entries are treated as lasting until now.
+func (s *Storage) GetTimeEntryConflicts(from, to time.Time) ([]types.TimeEntryConflict, error) {
+	return s.GetTimeEntryConflictsAt(from, to, time.Now())
+}
+
+// GetTimeEntryConflictsAt finds overlapping time entries as seen at the given time
+func (s *Storage) GetTimeEntryConflictsAt(from, to, now time.Time) ([]types.TimeEntryConflict, error) {
+	query := `SELECT te.id, te.task_id, t.title, t.project_id, te.start_time, te.end_time
+			  FROM time_entries te
+			  JOIN tasks t ON te.task_id = t.id
+			  WHERE te.start_time < ? AND (te.end_time IS NULL OR te.end_time > ?)
+			  ORDER BY te.start_time ASC, te.id ASC`
+
+	rows, err := s.db.Query(query, to, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []types.ConflictEntry
+	for rows.Next() {
+		var entry types.ConflictEntry
+		if err := rows.Scan(&entry.TimeEntryID, &entry.TaskID, &entry.TaskTitle, &entry.ProjectID, &entry.StartTime, &entry.EndTime); err != nil {
+			return nil, fmt.Errorf("failed to scan time entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading time entry rows: %w", err)
+	}
+
+	conflicts := []types.TimeEntryConflict{}
+	for i, first := range entries {
+		firstEnd := conflictEntryEnd(first, now)
+		for _, second := range entries[i+1:] {
+			if !second.StartTime.Before(firstEnd) {
+				break
+			}
+			if second.TaskID == first.TaskID {
+				continue
+			}
+			conflicts = append(conflicts, newTimeEntryConflict(first, second, now))
+		}
+	}
+
+	return conflicts, nil
+}
+
+// newTimeEntryConflict describes the overlap of two entries, the first starting no later than the second
+func newTimeEntryConflict(first, second types.ConflictEntry, now time.Time) types.TimeEntryConflict {
+	firstEnd, secondEnd := conflictEntryEnd(first, now), conflictEntryEnd(second, now)
+	overlapEnd := firstEnd
+	if secondEnd.Before(overlapEnd) {
+		overlapEnd = secondEnd
+	}
+
+	conflict := types.TimeEntryConflict{
+		First:        first,
+		Second:       second,
+		OverlapStart: second.StartTime,
+		OverlapEnd:   overlapEnd,
+		Overlap:      int(overlapEnd.Sub(second.StartTime).Seconds()),
+		Resolutions:  []types.ConflictResolution{},
+	}
+
+	// End the first entry where the second starts; this also stops a running first entry
+	if first.StartTime.Before(second.StartTime) {
+		start, end := first.StartTime, second.StartTime
+		conflict.Resolutions = append(conflict.Resolutions, types.ConflictResolution{
+			Action:      types.ConflictActionTrimFirst,
+			TimeEntryID: first.TimeEntryID,
+			StartTime:   &start,
+			EndTime:     &end,
+		})
+	}
+
+	// Start the second entry where the first ends, when the second outlasts it
+	if first.EndTime != nil && firstEnd.Before(secondEnd) {
+		start := firstEnd
+		conflict.Resolutions = append(conflict.Resolutions, types.ConflictResolution{
+			Action:      types.ConflictActionTrimSecond,
+			TimeEntryID: second.TimeEntryID,
+			StartTime:   &start,
+			EndTime:     second.EndTime,
+		})
+	}
+
+	// Drop the second entry when it lies entirely within the first
+	if second.EndTime != nil && !secondEnd.After(firstEnd) {
+		conflict.Resolutions = append(conflict.Resolutions, types.ConflictResolution{
+			Action:      types.ConflictActionDeleteSecond,
+			TimeEntryID: second.TimeEntryID,
+		})
+	}
+
+	return conflict
+}
+
+// conflictEntryEnd returns when an entry ends, treating a running entry as lasting until now
+func conflictEntryEnd(entry types.ConflictEntry, now time.Time) time.Time {
+	if entry.EndTime != nil {
+		return *entry.EndTime
+	}
+	return now
+}
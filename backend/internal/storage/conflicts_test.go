@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestNoOverlapPolicy(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	createStoppedEntry(t, s, first.ID, start, time.Hour)
+
+	// Without the policy the same hour may be logged on another task
+	entry := createStoppedEntry(t, s, second.ID, start.Add(30*time.Minute), time.Hour)
+	if err := s.DeleteTimeEntry(entry.ID); err != nil {
+		t.Fatalf("Failed to delete time entry: %v", err)
+	}
+
+	s.SetNoOverlap(true)
+
+	end := start.Add(90 * time.Minute)
+	_, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: second.ID, StartTime: start.Add(30 * time.Minute), EndTime: &end})
+	var ruleErr *TimeEntryRuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleCrossOverlap {
+		t.Fatalf("Expected a cross task overlap error, got %v", err)
+	}
+
+	// Back to back entries do not overlap
+	entry = createStoppedEntry(t, s, second.ID, start.Add(time.Hour), time.Hour)
+
+	// Updating the entry into the first one's hour is rejected as well
+	updateEnd := start.Add(time.Hour + 30*time.Minute)
+	_, err = s.UpdateTimeEntry(entry.ID, types.CreateTimeEntryRequest{TaskID: second.ID, StartTime: start.Add(30 * time.Minute), EndTime: &updateEnd})
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleCrossOverlap {
+		t.Fatalf("Expected a cross task overlap error on update, got %v", err)
+	}
+
+	// A timer running on one task blocks starting another unless the global timer stops it
+	s.SetGlobalTimer(false)
+	if _, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: first.ID}); err != nil {
+		t.Fatalf("Failed to start time entry: %v", err)
+	}
+	_, err = s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: second.ID})
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleCrossOverlap {
+		t.Fatalf("Expected a cross task overlap error on start, got %v", err)
+	}
+
+	s.SetGlobalTimer(true)
+	started, err := s.StartTimeEntryAt(types.StartTimeEntryRequest{TaskID: second.ID}, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Expected the global timer to stop the running entry, got %v", err)
+	}
+	if len(started.Stopped) != 1 {
+		t.Errorf("Expected one stopped entry, got %d", len(started.Stopped))
+	}
+}
+
+func TestGetTimeEntryConflicts(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+
+	start := time.Now().Add(-5 * time.Hour).Truncate(time.Second)
+	long := createStoppedEntry(t, s, first.ID, start, 2*time.Hour)
+	inside := createStoppedEntry(t, s, second.ID, start.Add(30*time.Minute), 30*time.Minute)
+	tail := createStoppedEntry(t, s, second.ID, start.Add(90*time.Minute), time.Hour)
+
+	// Entries on the same task are not cross task conflicts
+	createStoppedEntry(t, s, first.ID, start.Add(3*time.Hour), time.Hour)
+
+	conflicts, err := s.GetTimeEntryConflictsAt(start.Add(-time.Hour), start.Add(6*time.Hour), start.Add(5*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get conflicts: %v", err)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %d", len(conflicts))
+	}
+
+	contained := conflicts[0]
+	if contained.First.TimeEntryID != long.ID || contained.Second.TimeEntryID != inside.ID {
+		t.Errorf("Expected entries %d and %d, got %d and %d", long.ID, inside.ID, contained.First.TimeEntryID, contained.Second.TimeEntryID)
+	}
+	if contained.Overlap != 30*60 {
+		t.Errorf("Expected a 30 minute overlap, got %d seconds", contained.Overlap)
+	}
+	if len(contained.Resolutions) != 2 || contained.Resolutions[0].Action != types.ConflictActionTrimFirst ||
+		contained.Resolutions[1].Action != types.ConflictActionDeleteSecond {
+		t.Errorf("Expected trim first and delete second resolutions, got %+v", contained.Resolutions)
+	}
+
+	partial := conflicts[1]
+	if partial.Second.TimeEntryID != tail.ID || partial.Overlap != 30*60 {
+		t.Errorf("Expected a 30 minute overlap with entry %d, got %d seconds with %d", tail.ID, partial.Overlap, partial.Second.TimeEntryID)
+	}
+	if len(partial.Resolutions) != 2 || partial.Resolutions[1].Action != types.ConflictActionTrimSecond {
+		t.Fatalf("Expected trim first and trim second resolutions, got %+v", partial.Resolutions)
+	}
+	if !partial.Resolutions[1].StartTime.Equal(*long.EndTime) {
+		t.Errorf("Expected the second entry to start at %v, got %v", *long.EndTime, *partial.Resolutions[1].StartTime)
+	}
+}
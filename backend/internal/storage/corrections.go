@@ -0,0 +1,304 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// SplitTimeEntry splits a time entry in two at the given time. A running entry keeps running as the second part.
+func (s *Storage) SplitTimeEntry(id int, req types.SplitTimeEntryRequest) (*types.TimeEntryCorrection, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	entry, err := getCorrectableTimeEntry(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	end := now
+	if entry.EndTime != nil {
+		end = *entry.EndTime
+	}
+	if !req.At.After(entry.StartTime) || !req.At.Before(end) {
+		return nil, fmt.Errorf("split time must fall within time entry %d", id)
+	}
+
+	// Both parts must satisfy the rules on their own, e.g. the minimum duration
+	rules, err := s.timeEntryRulesForTask(tx, entry.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTimeEntryRules(rules, entry.StartTime, &req.At, now, req.Override); err != nil {
+		return nil, err
+	}
+	if err := checkTimeEntryRules(rules, req.At, entry.EndTime, now, req.Override); err != nil {
+		return nil, err
+	}
+
+	entries, err := splitTimeEntry(tx, entry, req.At, req.At)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range entries {
+		if err := s.validateNoCrossTaskOverlap(tx, part.TaskID, part.StartTime, part.EndTime, part.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Correcting a reconciled entry settles its review
+	if _, err := resolveTimeEntryReview(tx, id, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit split transaction: %w", err)
+	}
+
+	return &types.TimeEntryCorrection{TimeEntries: entries}, nil
+}
+
+// MergeTimeEntries merges consecutive stopped entries of one task into the earliest of them.
+// Gaps between the entries are recorded as pauses, so the merged duration is the sum of the parts.
+// The entries must agree on being billable, and the merged entry must satisfy the time entry rules.
+func (s *Storage) MergeTimeEntries(req types.MergeTimeEntriesRequest) (*types.TimeEntryCorrection, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	entries, err := getCorrectableTimeEntries(tx, req.TimeEntryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	merging := make(map[int]bool)
+	var billable *bool
+	for _, entry := range entries {
+		if entry.TaskID != entries[0].TaskID {
+			return nil, fmt.Errorf("time entries must belong to the same task to be merged")
+		}
+		if entry.EndTime == nil {
+			return nil, fmt.Errorf("time entry %d is running and cannot be merged", entry.ID)
+		}
+		merging[entry.ID] = true
+
+		billing, err := getTimeEntryBilling(tx, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		if billable != nil && *billable != billing.Billable {
+			return nil, fmt.Errorf("billable and non-billable time entries cannot be merged")
+		}
+		billable = &billing.Billable
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StartTime.Before(entries[j].StartTime)
+	})
+	first, last := entries[0], entries[len(entries)-1]
+
+	end := *first.EndTime
+	for _, entry := range entries[1:] {
+		if entry.EndTime.After(end) {
+			end = *entry.EndTime
+		}
+	}
+
+	now := time.Now()
+	rules, err := s.timeEntryRulesForTask(tx, first.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTimeEntryRules(rules, first.StartTime, &end, now, req.Override); err != nil {
+		return nil, err
+	}
+	// The gaps become part of the merged entry and may overlap entries on other tasks
+	if err := s.validateNoCrossTaskOverlap(tx, first.TaskID, first.StartTime, &end, first.ID); err != nil {
+		return nil, err
+	}
+
+	// Another entry of the task between the merged ones would end up inside the merged entry
+	rows, err := tx.Query(`SELECT id FROM time_entries WHERE task_id = ? AND start_time >= ? AND start_time <= ?`,
+		first.TaskID, first.StartTime, last.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for entries between merged entries: %w", err)
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan time entry id: %w", err)
+		}
+		if !merging[id] {
+			rows.Close()
+			return nil, fmt.Errorf("time entries are not adjacent: time entry %d lies between them", id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error reading time entry rows: %w", err)
+	}
+	rows.Close()
+
+	var descriptions []string
+	seen := make(map[string]bool)
+	var deletedIDs []int
+	gapStart := *first.EndTime
+	for i, entry := range entries {
+		if entry.Description != "" && !seen[entry.Description] {
+			seen[entry.Description] = true
+			descriptions = append(descriptions, entry.Description)
+		}
+		if i == 0 {
+			continue
+		}
+
+		if entry.StartTime.After(gapStart) {
+			gap := `INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at) VALUES (?, ?, ?)`
+			if _, err := tx.Exec(gap, first.ID, gapStart, entry.StartTime); err != nil {
+				return nil, fmt.Errorf("failed to record gap between merged entries: %w", err)
+			}
+		}
+		if entry.EndTime.After(gapStart) {
+			gapStart = *entry.EndTime
+		}
+
+		for _, table := range []string{"time_entry_pauses", "time_entry_idle_spans"} {
+			move := `UPDATE ` + table + ` SET time_entry_id = ? WHERE time_entry_id = ?`
+			if _, err := tx.Exec(move, first.ID, entry.ID); err != nil {
+				return nil, fmt.Errorf("failed to move %s to merged entry: %w", table, err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM time_entries WHERE id = ?`, entry.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete merged time entry: %w", err)
+		}
+		deletedIDs = append(deletedIDs, entry.ID)
+	}
+
+	merged, err := stopTimeEntry(tx, &first, strings.Join(descriptions, "; "), end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Correcting a reconciled entry settles its review
+	if _, err := resolveTimeEntryReview(tx, first.ID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge transaction: %w", err)
+	}
+
+	return &types.TimeEntryCorrection{TimeEntries: []types.TimeEntry{*merged}, DeletedIDs: deletedIDs}, nil
+}
+
+// MoveTimeEntries reassigns time entries to another task that still accepts time. Every entry is checked
+// against the target task's time entry rules and for overlaps on the target task, including the other
+// entries being moved, and either all entries move or none do.
+func (s *Storage) MoveTimeEntries(req types.MoveTimeEntriesRequest) (*types.TimeEntryCorrection, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	exists, err := taskExists(tx, req.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify task existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("task with id %d does not exist", req.TaskID)
+	}
+	if err := validateTaskTrackable(tx, req.TaskID); err != nil {
+		return nil, err
+	}
+
+	entries, err := getCorrectableTimeEntries(tx, req.TimeEntryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The target task's project may override the rules, e.g. with a lower maximum duration
+	rules, err := s.timeEntryRulesForTask(tx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	moved := []types.TimeEntry{}
+	for _, entry := range entries {
+		if err := checkTimeEntryRules(rules, entry.StartTime, entry.EndTime, now, req.Override); err != nil {
+			return nil, err
+		}
+
+		move := `UPDATE time_entries SET task_id = ?, updated_at = ?, revision = revision + (end_time IS NOT NULL) WHERE id = ?`
+		if _, err := tx.Exec(move, req.TaskID, now, entry.ID); err != nil {
+			return nil, fmt.Errorf("failed to move time entry: %w", err)
+		}
+
+		if err := checkNoOverlappingTimeEntries(tx, req.TaskID, entry.StartTime, entry.EndTime, entry.ID); err != nil {
+			return nil, err
+		}
+		if err := s.validateNoCrossTaskOverlap(tx, req.TaskID, entry.StartTime, entry.EndTime, entry.ID); err != nil {
+			return nil, err
+		}
+
+		if _, err := resolveTimeEntryReview(tx, entry.ID, now); err != nil {
+			return nil, err
+		}
+
+		entry.TaskID = req.TaskID
+		moved = append(moved, entry)
+	}
+
+	if err := s.raiseBudgetAlerts(tx, now, req.TaskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit move transaction: %w", err)
+	}
+
+	return &types.TimeEntryCorrection{TimeEntries: moved}, nil
+}
+
+// getCorrectableTimeEntries loads the listed time entries, rejecting duplicates and invoiced entries
+func getCorrectableTimeEntries(q querier, ids []int) ([]types.TimeEntry, error) {
+	seen := make(map[int]bool)
+	entries := make([]types.TimeEntry, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, fmt.Errorf("time entry %d is listed more than once", id)
+		}
+		seen[id] = true
+
+		entry, err := getCorrectableTimeEntry(q, id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+// getCorrectableTimeEntry loads a time entry that may still be changed because it has not been invoiced
+func getCorrectableTimeEntry(q querier, id int) (*types.TimeEntry, error) {
+	billing, err := getTimeEntryBilling(q, id)
+	if err != nil {
+		return nil, err
+	}
+	if billing.InvoiceID != nil {
+		return nil, fmt.Errorf("time entry %d is invoiced and cannot be changed", id)
+	}
+	return getTimeEntry(q, id)
+}
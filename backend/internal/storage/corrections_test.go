@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestSplitTimeEntry(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	entry := createStoppedEntry(t, s, task.ID, start, 2*time.Hour)
+
+	// A pause spanning the split point is shared between both parts
+	if _, err := s.db.Exec(`INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at) VALUES (?, ?, ?)`,
+		entry.ID, start.Add(50*time.Minute), start.Add(70*time.Minute)); err != nil {
+		t.Fatalf("Failed to record pause: %v", err)
+	}
+
+	correction, err := s.SplitTimeEntry(entry.ID, types.SplitTimeEntryRequest{At: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to split time entry: %v", err)
+	}
+	if len(correction.TimeEntries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(correction.TimeEntries))
+	}
+
+	first, second := correction.TimeEntries[0], correction.TimeEntries[1]
+	if first.ID != entry.ID || !first.EndTime.Equal(start.Add(time.Hour)) || *first.Duration != 50*60 {
+		t.Errorf("Expected the first part to end after 50 tracked minutes, got %+v", first)
+	}
+	if !second.StartTime.Equal(start.Add(time.Hour)) || !second.EndTime.Equal(start.Add(2*time.Hour)) || *second.Duration != 50*60 {
+		t.Errorf("Expected the second part to cover the last 50 tracked minutes, got %+v", second)
+	}
+
+	// Both parts must meet the minimum duration unless the rules are overridden
+	if _, err := s.SplitTimeEntry(second.ID, types.SplitTimeEntryRequest{At: second.StartTime.Add(30 * time.Second)}); err == nil || !contains(err.Error(), "at least") {
+		t.Errorf("Expected minimum duration error, got %v", err)
+	}
+	if _, err := s.SplitTimeEntry(second.ID, types.SplitTimeEntryRequest{At: second.StartTime.Add(30 * time.Second), Override: true}); err != nil {
+		t.Errorf("Expected the override to allow a short part, got %v", err)
+	}
+
+	// The split time must fall inside the entry
+	if _, err := s.SplitTimeEntry(entry.ID, types.SplitTimeEntryRequest{At: start.Add(-time.Minute)}); err == nil || !contains(err.Error(), "must fall within") {
+		t.Errorf("Expected split range error, got %v", err)
+	}
+	if _, err := s.SplitTimeEntry(99999, types.SplitTimeEntryRequest{At: start}); err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestMergeTimeEntries(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	other := createTestTask(t, s, project.ID)
+
+	start := time.Now().Add(-5 * time.Hour).Truncate(time.Second)
+	first := createStoppedEntry(t, s, task.ID, start, 30*time.Minute)
+	second := createStoppedEntry(t, s, task.ID, start.Add(45*time.Minute), 15*time.Minute)
+	third := createStoppedEntry(t, s, task.ID, start.Add(2*time.Hour), 30*time.Minute)
+	elsewhere := createStoppedEntry(t, s, other.ID, start.Add(3*time.Hour), 30*time.Minute)
+
+	// Skipping an entry in between is rejected
+	if _, err := s.MergeTimeEntries(types.MergeTimeEntriesRequest{TimeEntryIDs: []int{first.ID, third.ID}}); err == nil || !contains(err.Error(), "not adjacent") {
+		t.Errorf("Expected not adjacent error, got %v", err)
+	}
+	if _, err := s.MergeTimeEntries(types.MergeTimeEntriesRequest{TimeEntryIDs: []int{third.ID, elsewhere.ID}}); err == nil || !contains(err.Error(), "same task") {
+		t.Errorf("Expected same task error, got %v", err)
+	}
+
+	// Entries that disagree on being billable are not merged
+	if _, err := s.SetTimeEntryBillable(second.ID, false); err != nil {
+		t.Fatalf("Failed to set billable: %v", err)
+	}
+	if _, err := s.MergeTimeEntries(types.MergeTimeEntriesRequest{TimeEntryIDs: []int{second.ID, first.ID}}); err == nil || !contains(err.Error(), "billable") {
+		t.Errorf("Expected billable error, got %v", err)
+	}
+	if _, err := s.SetTimeEntryBillable(second.ID, true); err != nil {
+		t.Fatalf("Failed to set billable: %v", err)
+	}
+
+	// With the no-overlap policy the gap may not cover time on other tasks
+	s.SetNoOverlap(true)
+	createStoppedEntry(t, s, other.ID, start.Add(35*time.Minute), 5*time.Minute)
+	_, err := s.MergeTimeEntries(types.MergeTimeEntriesRequest{TimeEntryIDs: []int{second.ID, first.ID}})
+	var ruleErr *TimeEntryRuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleCrossOverlap {
+		t.Errorf("Expected a cross-task overlap error, got %v", err)
+	}
+	s.SetNoOverlap(false)
+
+	correction, err := s.MergeTimeEntries(types.MergeTimeEntriesRequest{TimeEntryIDs: []int{second.ID, first.ID}})
+	if err != nil {
+		t.Fatalf("Failed to merge time entries: %v", err)
+	}
+
+	merged := correction.TimeEntries[0]
+	if merged.ID != first.ID || !merged.EndTime.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the earliest entry to span both, got %+v", merged)
+	}
+	// The 15 minute gap is left out of the merged duration
+	if *merged.Duration != 45*60 {
+		t.Errorf("Expected 45 tracked minutes, got %d seconds", *merged.Duration)
+	}
+	if len(correction.DeletedIDs) != 1 || correction.DeletedIDs[0] != second.ID {
+		t.Errorf("Expected the second entry to be deleted, got %v", correction.DeletedIDs)
+	}
+	if _, err := s.GetTimeEntry(second.ID); err == nil {
+		t.Error("Expected the merged entry to be gone")
+	}
+}
+
+func TestMoveTimeEntries(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	target := createTestTask(t, s, project.ID)
+
+	start := time.Now().Add(-5 * time.Hour).Truncate(time.Second)
+	first := createStoppedEntry(t, s, task.ID, start, 30*time.Minute)
+	second := createStoppedEntry(t, s, task.ID, start.Add(time.Hour), 30*time.Minute)
+	createStoppedEntry(t, s, target.ID, start.Add(70*time.Minute), 30*time.Minute)
+
+	// The second entry overlaps the target's entry, so nothing moves
+	_, err := s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID, second.ID}, TaskID: target.ID})
+	var ruleErr *TimeEntryRuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleOverlap {
+		t.Fatalf("Expected an overlap error, got %v", err)
+	}
+	unchanged, err := s.GetTimeEntry(first.ID)
+	if err != nil {
+		t.Fatalf("Failed to get time entry: %v", err)
+	}
+	if unchanged.TaskID != task.ID {
+		t.Errorf("Expected the failed move to be rolled back, got task %d", unchanged.TaskID)
+	}
+
+	correction, err := s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID}, TaskID: target.ID})
+	if err != nil {
+		t.Fatalf("Failed to move time entries: %v", err)
+	}
+	if len(correction.TimeEntries) != 1 || correction.TimeEntries[0].TaskID != target.ID {
+		t.Errorf("Expected the entry on the target task, got %+v", correction.TimeEntries)
+	}
+
+	// Entries must satisfy the rules of the target task's project unless they are overridden
+	strict := createTestProject(t, s)
+	strictTask := createTestTask(t, s, strict.ID)
+	maxMinutes := 20
+	if _, err := s.SetProjectTimeEntryRules(strict.ID, types.TimeEntryRulesOverride{MaxDurationMinutes: &maxMinutes}); err != nil {
+		t.Fatalf("Failed to set project rules: %v", err)
+	}
+	_, err = s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID}, TaskID: strictTask.ID})
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleMaxDuration {
+		t.Errorf("Expected a maximum duration error, got %v", err)
+	}
+	if _, err := s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID}, TaskID: strictTask.ID, Override: true}); err != nil {
+		t.Errorf("Expected the override to allow the move, got %v", err)
+	}
+
+	// Time cannot be moved onto closed tasks
+	if _, err := s.UpdateTaskStatus(task.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if _, err := s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID}, TaskID: task.ID}); err == nil || !contains(err.Error(), "completed task") {
+		t.Errorf("Expected completed task error, got %v", err)
+	}
+
+	// Test nonexistent task and duplicate entries
+	if _, err := s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID}, TaskID: 99999}); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("Expected does not exist error, got %v", err)
+	}
+	if _, err := s.MoveTimeEntries(types.MoveTimeEntriesRequest{TimeEntryIDs: []int{first.ID, first.ID}, TaskID: target.ID}); err == nil || !contains(err.Error(), "more than once") {
+		t.Errorf("Expected duplicate error, got %v", err)
+	}
+}
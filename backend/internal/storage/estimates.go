@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// defaultTaskMinutes is how long the scheduler plans for tasks without an estimate
+const defaultTaskMinutes = 60
+
+// SetTaskEstimate sets or, when minutes is nil, clears how long a task is expected to take
+func (s *Storage) SetTaskEstimate(taskID int, minutes *int) (*types.TaskEstimate, error) {
+	exists, err := taskExists(s.db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify task existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("task with id %d does not exist", taskID)
+	}
+
+	if minutes == nil {
+		if _, err := s.db.Exec(`DELETE FROM task_estimates WHERE task_id = ?`, taskID); err != nil {
+			return nil, fmt.Errorf("failed to clear task estimate: %w", err)
+		}
+	} else {
+		_, err := s.db.Exec(`INSERT INTO task_estimates (task_id, minutes) VALUES (?, ?)
+				  ON CONFLICT(task_id) DO UPDATE SET minutes = excluded.minutes`, taskID, *minutes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set task estimate: %w", err)
+		}
+	}
+
+	return &types.TaskEstimate{TaskID: taskID, Minutes: minutes}, nil
+}
+
+// getTaskEstimates loads every task estimate in minutes by task ID
+func getTaskEstimates(q querier) (map[int]int, error) {
+	rows, err := q.Query(`SELECT task_id, minutes FROM task_estimates`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task estimates: %w", err)
+	}
+	defer rows.Close()
+
+	estimates := make(map[int]int)
+	for rows.Next() {
+		var taskID, minutes int
+		if err := rows.Scan(&taskID, &minutes); err != nil {
+			return nil, fmt.Errorf("failed to scan task estimate: %w", err)
+		}
+		estimates[taskID] = minutes
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading task estimate rows: %w", err)
+	}
+
+	return estimates, nil
+}
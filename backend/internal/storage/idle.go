@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// RecordHeartbeat records a client heartbeat for the running timer.
+// Idle time at or above the threshold is marked as an idle span for the user to resolve.
+func (s *Storage) RecordHeartbeat(req types.HeartbeatRequest, idleThreshold time.Duration) (*types.ActiveTimer, error) {
+	return s.recordHeartbeat(req, idleThreshold, time.Now())
+}
+
+// recordHeartbeat records a heartbeat received at the given time
+func (s *Storage) recordHeartbeat(req types.HeartbeatRequest, idleThreshold time.Duration, now time.Time) (*types.ActiveTimer, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	timer, err := getRunningTimer(tx, now)
+	if err != nil {
+		return nil, err
+	}
+	if timer == nil {
+		return nil, fmt.Errorf("no running timer found")
+	}
+
+	query := `UPDATE time_entries SET last_heartbeat_at = ? WHERE id = ?`
+	if _, err := tx.Exec(query, now, timer.TimeEntry.ID); err != nil {
+		return nil, fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+
+	// Time spent paused is already left out, so idleness only matters while the timer runs
+	idle := time.Duration(req.IdleSeconds) * time.Second
+	if !timer.Paused && idle >= idleThreshold {
+		idleStart := now.Add(-idle)
+		if idleStart.Before(timer.TimeEntry.StartTime) {
+			idleStart = timer.TimeEntry.StartTime
+		}
+		if err := markIdleSpan(tx, timer.TimeEntry.ID, idleStart, now); err != nil {
+			return nil, err
+		}
+	}
+
+	timer, err = getRunningTimer(tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit heartbeat transaction: %w", err)
+	}
+
+	return timer, nil
+}
+
+// markIdleSpan records an idle span, extending the latest unresolved span when the two overlap
+// so that consecutive heartbeats during one idle period produce a single span
+func markIdleSpan(q querier, timeEntryID int, startedAt, endedAt time.Time) error {
+	var span types.TimeEntryIdleSpan
+	query := `SELECT id, started_at, ended_at
+			  FROM time_entry_idle_spans
+			  WHERE time_entry_id = ? AND resolution IS NULL
+			  ORDER BY ended_at DESC
+			  LIMIT 1`
+
+	err := q.QueryRow(query, timeEntryID).Scan(&span.ID, &span.StartedAt, &span.EndedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get idle span: %w", err)
+	}
+
+	if err == nil && !startedAt.After(span.EndedAt) {
+		if span.StartedAt.Before(startedAt) {
+			startedAt = span.StartedAt
+		}
+		updateQuery := `UPDATE time_entry_idle_spans SET started_at = ?, ended_at = ? WHERE id = ?`
+		if _, err := q.Exec(updateQuery, startedAt, endedAt, span.ID); err != nil {
+			return fmt.Errorf("failed to extend idle span: %w", err)
+		}
+		return nil
+	}
+
+	insertQuery := `INSERT INTO time_entry_idle_spans (time_entry_id, started_at, ended_at) VALUES (?, ?, ?)`
+	if _, err := q.Exec(insertQuery, timeEntryID, startedAt, endedAt); err != nil {
+		return fmt.Errorf("failed to create idle span: %w", err)
+	}
+	return nil
+}
+
+// GetIdleSpans returns all unresolved idle spans, oldest first
+func (s *Storage) GetIdleSpans() ([]types.TimeEntryIdleSpan, error) {
+	return getIdleSpans(s.db, "resolution IS NULL")
+}
+
+// getIdleSpans returns the idle spans matching the given condition in chronological order
+func getIdleSpans(q querier, condition string, args ...interface{}) ([]types.TimeEntryIdleSpan, error) {
+	query := `SELECT id, time_entry_id, started_at, ended_at, resolution, resolved_at
+			  FROM time_entry_idle_spans
+			  WHERE ` + condition + `
+			  ORDER BY started_at ASC, id ASC`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query idle spans: %w", err)
+	}
+	defer rows.Close()
+
+	spans := []types.TimeEntryIdleSpan{}
+	for rows.Next() {
+		var span types.TimeEntryIdleSpan
+		err := rows.Scan(&span.ID, &span.TimeEntryID, &span.StartedAt, &span.EndedAt, &span.Resolution, &span.ResolvedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan idle span: %w", err)
+		}
+		spans = append(spans, span)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading idle span rows: %w", err)
+	}
+
+	return spans, nil
+}
+
+// ResolveIdleSpan settles an idle span by keeping the idle time, discarding it,
+// or splitting the entry around it. The changed time entries are returned.
+func (s *Storage) ResolveIdleSpan(id int, resolution types.IdleResolution) (*types.IdleSpanResolution, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	spans, err := getIdleSpans(tx, "id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("idle span with id %d not found", id)
+	}
+	span := spans[0]
+	if span.Resolution != nil {
+		return nil, fmt.Errorf("idle span %d is already resolved", id)
+	}
+
+	// Keeping the idle time leaves the entry as it is, anything else changes it and so must respect the invoice lock
+	load := getCorrectableTimeEntry
+	if resolution == types.IdleResolutionKeep {
+		load = getTimeEntry
+	}
+	entry, err := load(tx, span.TimeEntryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entries := []types.TimeEntry{}
+	switch resolution {
+	case types.IdleResolutionKeep:
+		entries = append(entries, *entry)
+	case types.IdleResolutionDiscard:
+		entry, err = discardIdleSpan(tx, entry, span)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	case types.IdleResolutionSplit:
+		entries, err = splitAtIdleSpan(tx, entry, span)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid idle resolution: %s", resolution)
+	}
+
+	query := `UPDATE time_entry_idle_spans SET resolution = ?, resolved_at = ? WHERE id = ?`
+	if _, err := tx.Exec(query, resolution, now, id); err != nil {
+		return nil, fmt.Errorf("failed to resolve idle span: %w", err)
+	}
+	span.Resolution = &resolution
+	span.ResolvedAt = &now
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit idle span transaction: %w", err)
+	}
+
+	return &types.IdleSpanResolution{IdleSpan: span, TimeEntries: entries}, nil
+}
+
+// discardIdleSpan leaves the idle span out of the entry by recording the part of it that is not
+// paused already as pauses
+func discardIdleSpan(q querier, entry *types.TimeEntry, span types.TimeEntryIdleSpan) (*types.TimeEntry, error) {
+	pauses, err := getTimeEntryPauses(q, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	idle := []interval{{span.StartedAt, span.EndedAt}}
+	query := `INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at) VALUES (?, ?, ?)`
+	for _, iv := range subtractIntervals(idle, pauseIntervals(pauses, span.EndedAt)) {
+		if _, err := q.Exec(query, entry.ID, iv.start, iv.end); err != nil {
+			return nil, fmt.Errorf("failed to record discarded idle time: %w", err)
+		}
+	}
+
+	if entry.EndTime == nil {
+		return entry, nil
+	}
+
+	// Recalculate the duration of an entry that was already stopped
+	return stopTimeEntry(q, entry, entry.Description, *entry.EndTime)
+}
+
+// splitAtIdleSpan ends the entry where the idle span starts and moves the work after it into a new entry
+func splitAtIdleSpan(q querier, entry *types.TimeEntry, span types.TimeEntryIdleSpan) ([]types.TimeEntry, error) {
+	return splitTimeEntry(q, entry, span.StartedAt, span.EndedAt)
+}
+
+// splitTimeEntry ends the entry at firstEnd and moves the work from secondStart on into a new entry
+// on the same task. A running entry keeps running as the new entry.
+func splitTimeEntry(q querier, entry *types.TimeEntry, firstEnd, secondStart time.Time) ([]types.TimeEntry, error) {
+	query := `INSERT INTO time_entries (task_id, start_time, end_time, description, created_at, last_heartbeat_at, billable)
+			  SELECT task_id, ?, end_time, description, ?, last_heartbeat_at, billable FROM time_entries WHERE id = ?
+			  RETURNING id, task_id, start_time, end_time, duration, description, created_at`
+
+	var next types.TimeEntry
+	err := q.QueryRow(query, secondStart, time.Now(), entry.ID).Scan(
+		&next.ID,
+		&next.TaskID,
+		&next.StartTime,
+		&next.EndTime,
+		&next.Duration,
+		&next.Description,
+		&next.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create split time entry: %w", err)
+	}
+
+	// Pauses taken after the split belong to the new entry, and a pause spanning the split is cut in two
+	splitPauses := `INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at)
+					SELECT ?, ?, resumed_at FROM time_entry_pauses
+					WHERE time_entry_id = ? AND paused_at < ? AND (resumed_at IS NULL OR resumed_at > ?)`
+	if _, err := q.Exec(splitPauses, next.ID, secondStart, entry.ID, secondStart, secondStart); err != nil {
+		return nil, fmt.Errorf("failed to split pauses: %w", err)
+	}
+
+	endPauses := `UPDATE time_entry_pauses SET resumed_at = ? WHERE time_entry_id = ? AND paused_at < ? AND resumed_at > ?`
+	if _, err := q.Exec(endPauses, secondStart, entry.ID, secondStart, secondStart); err != nil {
+		return nil, fmt.Errorf("failed to split pauses: %w", err)
+	}
+
+	movePauses := `UPDATE time_entry_pauses SET time_entry_id = ? WHERE time_entry_id = ? AND paused_at >= ?`
+	if _, err := q.Exec(movePauses, next.ID, entry.ID, secondStart); err != nil {
+		return nil, fmt.Errorf("failed to move pauses to split time entry: %w", err)
+	}
+
+	moveIdleSpans := `UPDATE time_entry_idle_spans SET time_entry_id = ? WHERE time_entry_id = ? AND started_at >= ?`
+	if _, err := q.Exec(moveIdleSpans, next.ID, entry.ID, secondStart); err != nil {
+		return nil, fmt.Errorf("failed to move idle spans to split time entry: %w", err)
+	}
+
+	first, err := stopTimeEntry(q, entry, entry.Description, firstEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if next.EndTime != nil {
+		second, err := stopTimeEntry(q, &next, next.Description, *next.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		next = *second
+	}
+
+	return []types.TimeEntry{*first, next}, nil
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestHeartbeatMarksIdleSpan(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	// Heartbeats without a running timer fail
+	_, err := s.RecordHeartbeat(types.HeartbeatRequest{}, 5*time.Minute)
+	if err == nil || !contains(err.Error(), "no running timer") {
+		t.Errorf("Expected no running timer error, got %v", err)
+	}
+
+	now := time.Now()
+	entry := createDanglingEntry(t, s, task.ID, time.Hour)
+
+	// Short idle periods are ignored
+	timer, err := s.recordHeartbeat(types.HeartbeatRequest{IdleSeconds: 60}, 5*time.Minute, now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+	if len(timer.IdleSpans) != 0 {
+		t.Errorf("Expected no idle spans, got %d", len(timer.IdleSpans))
+	}
+
+	// Consecutive idle heartbeats extend a single span
+	if _, err := s.recordHeartbeat(types.HeartbeatRequest{IdleSeconds: 600}, 5*time.Minute, now.Add(-20*time.Minute)); err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+	timer, err = s.recordHeartbeat(types.HeartbeatRequest{IdleSeconds: 660}, 5*time.Minute, now.Add(-19*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+	if len(timer.IdleSpans) != 1 {
+		t.Fatalf("Expected 1 idle span, got %d", len(timer.IdleSpans))
+	}
+
+	span := timer.IdleSpans[0]
+	if span.TimeEntryID != entry.ID || span.EndedAt.Sub(span.StartedAt) != 11*time.Minute {
+		t.Errorf("Expected an 11 minute idle span, got %v", span.EndedAt.Sub(span.StartedAt))
+	}
+
+	var lastHeartbeat time.Time
+	if err := s.db.QueryRow(`SELECT last_heartbeat_at FROM time_entries WHERE id = ?`, entry.ID).Scan(&lastHeartbeat); err != nil {
+		t.Fatalf("Failed to read heartbeat: %v", err)
+	}
+	if !lastHeartbeat.Equal(now.Add(-19 * time.Minute)) {
+		t.Errorf("Expected last heartbeat to be recorded, got %v", lastHeartbeat)
+	}
+
+	// Discarding the idle time leaves it out of the duration
+	resolution, err := s.ResolveIdleSpan(span.ID, types.IdleResolutionDiscard)
+	if err != nil {
+		t.Fatalf("Failed to resolve idle span: %v", err)
+	}
+	if resolution.IdleSpan.Resolution == nil || *resolution.IdleSpan.Resolution != types.IdleResolutionDiscard {
+		t.Errorf("Expected the span to be resolved as discarded")
+	}
+
+	_, err = s.ResolveIdleSpan(span.ID, types.IdleResolutionKeep)
+	if err == nil || !contains(err.Error(), "already resolved") {
+		t.Errorf("Expected already resolved error, got %v", err)
+	}
+
+	stopped, err := s.StopTimeEntry(task.ID, types.StopTimeEntryRequest{})
+	if err != nil {
+		t.Fatalf("Failed to stop entry: %v", err)
+	}
+	if *stopped.Duration < 49*60-5 || *stopped.Duration > 49*60+5 {
+		t.Errorf("Expected about 49 minutes excluding idle time, got %d seconds", *stopped.Duration)
+	}
+}
+
+func TestResolveIdleSpanSplit(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	now := time.Now()
+	entry := createDanglingEntry(t, s, task.ID, time.Hour)
+
+	timer, err := s.recordHeartbeat(types.HeartbeatRequest{IdleSeconds: 600}, 5*time.Minute, now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+	if len(timer.IdleSpans) != 1 {
+		t.Fatalf("Expected 1 idle span, got %d", len(timer.IdleSpans))
+	}
+
+	resolution, err := s.ResolveIdleSpan(timer.IdleSpans[0].ID, types.IdleResolutionSplit)
+	if err != nil {
+		t.Fatalf("Failed to split idle span: %v", err)
+	}
+	if len(resolution.TimeEntries) != 2 {
+		t.Fatalf("Expected 2 time entries, got %d", len(resolution.TimeEntries))
+	}
+
+	first, second := resolution.TimeEntries[0], resolution.TimeEntries[1]
+	if first.ID != entry.ID || first.Duration == nil {
+		t.Fatalf("Expected the original entry %d to be stopped", entry.ID)
+	}
+	if *first.Duration < 20*60-5 || *first.Duration > 20*60+5 {
+		t.Errorf("Expected the original entry to end at the idle start after about 20 minutes, got %d seconds", *first.Duration)
+	}
+	if second.EndTime != nil || !second.StartTime.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("Expected a running entry starting at the idle end, got %v", second.StartTime)
+	}
+
+	timer, err = s.GetRunningTimer()
+	if err != nil {
+		t.Fatalf("Failed to get running timer: %v", err)
+	}
+	if timer == nil || timer.TimeEntry.ID != second.ID {
+		t.Errorf("Expected the split entry to be the running timer")
+	}
+	if len(timer.IdleSpans) != 0 {
+		t.Errorf("Expected no unresolved idle spans, got %d", len(timer.IdleSpans))
+	}
+
+	spans, err := s.GetIdleSpans()
+	if err != nil {
+		t.Fatalf("Failed to get idle spans: %v", err)
+	}
+	if len(spans) != 0 {
+		t.Errorf("Expected no unresolved idle spans, got %d", len(spans))
+	}
+}
+
+func TestDiscardIdleSpanWithinPause(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	now := time.Now()
+	entry := createDanglingEntry(t, s, task.ID, time.Hour)
+
+	// A 10 minute pause overlaps the last 5 minutes of a 10 minute idle span
+	pause := `INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at) VALUES (?, ?, ?)`
+	if _, err := s.db.Exec(pause, entry.ID, now.Add(-35*time.Minute), now.Add(-25*time.Minute)); err != nil {
+		t.Fatalf("Failed to create pause: %v", err)
+	}
+	timer, err := s.recordHeartbeat(types.HeartbeatRequest{IdleSeconds: 600}, 5*time.Minute, now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+	if _, err := s.ResolveIdleSpan(timer.IdleSpans[0].ID, types.IdleResolutionDiscard); err != nil {
+		t.Fatalf("Failed to resolve idle span: %v", err)
+	}
+
+	// Time both paused and idle is only left out once
+	stopped, err := s.StopTimeEntry(task.ID, types.StopTimeEntryRequest{})
+	if err != nil {
+		t.Fatalf("Failed to stop entry: %v", err)
+	}
+	if *stopped.Duration < 45*60-5 || *stopped.Duration > 45*60+5 {
+		t.Errorf("Expected about 45 minutes excluding paused and idle time, got %d seconds", *stopped.Duration)
+	}
+}
+
+func TestResolveIdleSpanOnInvoicedEntry(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	now := time.Now()
+	entry := createDanglingEntry(t, s, task.ID, time.Hour)
+	timer, err := s.recordHeartbeat(types.HeartbeatRequest{IdleSeconds: 600}, 5*time.Minute, now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to record heartbeat: %v", err)
+	}
+	if _, err := s.StopTimeEntry(task.ID, types.StopTimeEntryRequest{}); err != nil {
+		t.Fatalf("Failed to stop entry: %v", err)
+	}
+
+	req := types.CreateInvoiceRequest{ProjectID: project.ID, PeriodStart: now.Add(-2 * time.Hour), PeriodEnd: time.Now()}
+	if _, err := s.CreateInvoice(req); err != nil {
+		t.Fatalf("Failed to create invoice: %v", err)
+	}
+
+	// Discarding or splitting would change an invoiced entry
+	spanID := timer.IdleSpans[0].ID
+	for _, resolution := range []types.IdleResolution{types.IdleResolutionDiscard, types.IdleResolutionSplit} {
+		if _, err := s.ResolveIdleSpan(spanID, resolution); err == nil || !contains(err.Error(), "is invoiced") {
+			t.Errorf("Expected invoiced error on %s, got %v", resolution, err)
+		}
+	}
+
+	unchanged, err := s.GetTimeEntry(entry.ID)
+	if err != nil {
+		t.Fatalf("Failed to get entry: %v", err)
+	}
+	if *unchanged.Duration < 60*60-5 {
+		t.Errorf("Expected the invoiced entry to keep its hour, got %d seconds", *unchanged.Duration)
+	}
+
+	// Keeping the idle time leaves the entry as it is
+	if _, err := s.ResolveIdleSpan(spanID, types.IdleResolutionKeep); err != nil {
+		t.Errorf("Failed to keep idle time on invoiced entry: %v", err)
+	}
+}
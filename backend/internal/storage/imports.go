@@ -0,0 +1,298 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// Defaults for projects created by an import
+const (
+	importProjectColor = "#6B7280"
+	importProjectIcon  = "folder"
+)
+
+// importNames caches the projects and tasks resolved by name during an import
+type importNames struct {
+	projects map[string]int
+	tasks    map[string]int
+}
+
+// ImportTimeEntries imports time entries read from another tracker's export. Projects are matched by
+// name, or "client / project" when a client is given, and tasks by their description, creating them
+// when missing. Lines imported before or repeated within the export are skipped as duplicates, and lines
+// overlapping an existing entry or matching a completed or cancelled task are reported as conflicts.
+// Lines breaking the time entry rules of their project are invalid unless override skips the rules,
+// which exports back-dated beyond the limits need. A dry run reports the same outcome without changing
+// anything.
+func (s *Storage) ImportTimeEntries(source types.ImportSource, records []types.ImportRecord, dryRun, override bool) (*types.ImportResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds; a dry run never commits
+
+	result := &types.ImportResult{
+		Source:          source,
+		DryRun:          dryRun,
+		ProjectsCreated: []string{},
+		TasksCreated:    []string{},
+		Rows:            []types.ImportRow{},
+	}
+	names := &importNames{projects: make(map[string]int), tasks: make(map[string]int)}
+	lines := make(map[string]int)
+	now := time.Now()
+
+	for _, record := range records {
+		row := types.ImportRow{
+			Line:        record.Line,
+			ProjectName: importProjectName(record),
+			TaskTitle:   importTaskTitle(record),
+			StartTime:   record.StartTime,
+			EndTime:     record.EndTime,
+		}
+
+		// A line repeated within the export is a duplicate, whatever became of its first occurrence
+		key := importKey(record)
+		var id int
+		if line, ok := lines[key]; ok && record.Error == "" {
+			row.Status, row.Message = types.ImportRowDuplicate, fmt.Sprintf("same as line %d", line)
+		} else {
+			lines[key] = record.Line
+			if id, err = s.importRecord(tx, source, record, key, &row, names, result, now, override); err != nil {
+				return nil, err
+			}
+		}
+		if id != 0 && !dryRun {
+			row.TimeEntryID = &id
+		}
+
+		switch row.Status {
+		case types.ImportRowCreated:
+			result.Created++
+		case types.ImportRowDuplicate:
+			result.Duplicates++
+		case types.ImportRowConflict:
+			result.Conflicts++
+		case types.ImportRowInvalid:
+			result.Invalid++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit import transaction: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// importRecord imports one export line, setting the row's status and returning the new entry's ID
+func (s *Storage) importRecord(tx querier, source types.ImportSource, record types.ImportRecord, key string, row *types.ImportRow,
+	names *importNames, result *types.ImportResult, now time.Time, override bool) (int, error) {
+	if record.Error != "" {
+		row.Status, row.Message = types.ImportRowInvalid, record.Error
+		return 0, nil
+	}
+
+	var existingID int
+	err := tx.QueryRow(`SELECT time_entry_id FROM time_entry_imports WHERE source = ? AND import_key = ?`, source, key).Scan(&existingID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to check for imported time entry: %w", err)
+	}
+	if err == nil {
+		row.Status, row.Message = types.ImportRowDuplicate, fmt.Sprintf("already imported as time entry %d", existingID)
+		return 0, nil
+	}
+
+	projectID, err := findImportProject(tx, names, row.ProjectName)
+	if err != nil {
+		return 0, err
+	}
+
+	// A project created by the import has no rule overrides yet
+	rules := s.rules
+	if projectID != 0 {
+		if rules, err = s.timeEntryRulesForProject(tx, projectID); err != nil {
+			return 0, err
+		}
+	}
+	if err := checkTimeEntryRules(rules, record.StartTime, &record.EndTime, now, override); err != nil {
+		row.Status, row.Message = types.ImportRowInvalid, err.Error()
+		return 0, nil
+	}
+	taskID := 0
+	if projectID != 0 {
+		if taskID, err = findImportTask(tx, names, projectID, row.TaskTitle); err != nil {
+			return 0, err
+		}
+	}
+
+	// A task that does not exist yet has no entries to overlap
+	if taskID != 0 {
+		if err := validateTaskTrackable(tx, taskID); err != nil {
+			row.Status, row.Message = types.ImportRowConflict, err.Error()
+			return 0, nil
+		}
+		err = checkNoOverlappingTimeEntries(tx, taskID, record.StartTime, &record.EndTime, 0)
+	}
+	if err == nil {
+		err = s.validateNoCrossTaskOverlap(tx, taskID, record.StartTime, &record.EndTime, 0)
+	}
+	var ruleErr *TimeEntryRuleError
+	if errors.As(err, &ruleErr) {
+		row.Status, row.Message = types.ImportRowConflict, ruleErr.Message
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if projectID == 0 {
+		err := tx.QueryRow(`INSERT INTO projects (name, description, color, icon, created_at, updated_at)
+				  VALUES (?, ?, ?, ?, ?, ?)
+				  RETURNING id`,
+			row.ProjectName, fmt.Sprintf("Imported from %s", source), importProjectColor, importProjectIcon, now, now).Scan(&projectID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create imported project: %w", err)
+		}
+		names.projects[strings.ToLower(row.ProjectName)] = projectID
+		result.ProjectsCreated = append(result.ProjectsCreated, row.ProjectName)
+	}
+	if taskID == 0 {
+		err := tx.QueryRow(`INSERT INTO tasks (project_id, title, description, status, priority, created_at, updated_at)
+				  VALUES (?, ?, '', ?, 0, ?, ?)
+				  RETURNING id`,
+			projectID, row.TaskTitle, types.TaskStatusPending, now, now).Scan(&taskID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create imported task: %w", err)
+		}
+		names.tasks[importTaskKey(projectID, row.TaskTitle)] = taskID
+		result.TasksCreated = append(result.TasksCreated, row.ProjectName+": "+row.TaskTitle)
+	}
+
+	billable := true
+	if record.Billable != nil {
+		billable = *record.Billable
+	}
+
+	var id int
+	err = tx.QueryRow(`INSERT INTO time_entries (task_id, start_time, end_time, duration, description, billable, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)
+			  RETURNING id`,
+		taskID, record.StartTime, record.EndTime, int(record.EndTime.Sub(record.StartTime).Seconds()), truncateRunes(record.Description, 500), billable, now).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create imported time entry: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO time_entry_imports (time_entry_id, source, import_key, imported_at) VALUES (?, ?, ?, ?)`,
+		id, source, key, now); err != nil {
+		return 0, fmt.Errorf("failed to record imported time entry: %w", err)
+	}
+
+	if err := s.raiseBudgetAlerts(tx, now, taskID); err != nil {
+		return 0, err
+	}
+
+	row.Status = types.ImportRowCreated
+	return id, nil
+}
+
+// findImportProject returns the ID of the project with the given name, ignoring case, or 0 when there is none
+func findImportProject(q querier, names *importNames, name string) (int, error) {
+	if id, ok := names.projects[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := q.QueryRow(`SELECT id FROM projects WHERE lower(name) = lower(?) ORDER BY id ASC LIMIT 1`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up project: %w", err)
+	}
+
+	names.projects[strings.ToLower(name)] = id
+	return id, nil
+}
+
+// findImportTask returns the ID of the project's task with the given title, ignoring case, or 0 when there is none
+func findImportTask(q querier, names *importNames, projectID int, title string) (int, error) {
+	key := importTaskKey(projectID, title)
+	if id, ok := names.tasks[key]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := q.QueryRow(`SELECT id FROM tasks WHERE project_id = ? AND lower(title) = lower(?) ORDER BY id ASC LIMIT 1`,
+		projectID, title).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up task: %w", err)
+	}
+
+	names.tasks[key] = id
+	return id, nil
+}
+
+// importTaskKey identifies a task title within a project in the import name cache
+func importTaskKey(projectID int, title string) string {
+	return fmt.Sprintf("%d:%s", projectID, strings.ToLower(title))
+}
+
+// importProjectName maps an export line's client and project to a project name
+func importProjectName(record types.ImportRecord) string {
+	name := record.Project
+	if name == "" {
+		name = "Imported"
+	}
+	if record.Client != "" {
+		name = record.Client + " / " + name
+	}
+	return truncateRunes(name, 100)
+}
+
+// importTaskTitle maps an export line's description to a task title, falling back to its task
+func importTaskTitle(record types.ImportRecord) string {
+	title := record.Description
+	if title == "" {
+		title = record.Task
+	}
+	if title == "" {
+		title = "Imported time"
+	}
+	return truncateRunes(title, 200)
+}
+
+// importKey identifies an export line so importing the same export again skips it
+func importKey(record types.ImportRecord) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		record.Client,
+		record.Project,
+		record.Task,
+		record.Description,
+		record.StartTime.UTC().Format(time.RFC3339),
+		record.EndTime.UTC().Format(time.RFC3339),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncateRunes shortens s to at most n runes
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
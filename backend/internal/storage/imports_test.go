@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestImportTimeEntries(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	done, err := s.CreateTask(types.CreateTaskRequest{ProjectID: project.ID, Title: "Shipped", Priority: 3})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if _, err := s.UpdateTaskStatus(done.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	// Imports are back-dated beyond the usual limits, so these skip the rules
+	start := time.Now().AddDate(0, -3, 0).Truncate(time.Second)
+	existing := createStoppedEntry(t, s, task.ID, time.Now().Add(-2*time.Hour).Truncate(time.Second), time.Hour)
+
+	records := []types.ImportRecord{
+		{Line: 2, Client: "Acme", Project: "Website", Description: "Fix header", StartTime: start, EndTime: start.Add(time.Hour)},
+		{Line: 3, Project: "Website", Client: "Acme", Description: "fix header", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+		{Line: 4, Project: project.Name, Description: task.Title, StartTime: existing.StartTime.Add(30 * time.Minute), EndTime: existing.StartTime.Add(90 * time.Minute)},
+		{Line: 5, Project: "Website", Description: "Broken", Error: "invalid end: unrecognised date and time"},
+		{Line: 6, Client: "Acme", Project: "Website", Description: "Fix header", StartTime: start, EndTime: start.Add(time.Hour)},
+		{Line: 7, Project: project.Name, Description: task.Title, StartTime: existing.StartTime.Add(30 * time.Minute), EndTime: existing.StartTime.Add(90 * time.Minute)},
+		{Line: 8, Project: project.Name, Description: "shipped", StartTime: start, EndTime: start.Add(time.Hour)},
+	}
+
+	dryRun, err := s.ImportTimeEntries(types.ImportSourceToggl, records, true, true)
+	if err != nil {
+		t.Fatalf("Failed to run import: %v", err)
+	}
+	if dryRun.Created != 2 || dryRun.Conflicts != 2 || dryRun.Invalid != 1 || dryRun.Duplicates != 2 {
+		t.Fatalf("Unexpected dry run counts %+v", dryRun)
+	}
+
+	// Repeated lines are duplicates even when their first occurrence conflicts
+	if dryRun.Rows[5].Status != types.ImportRowDuplicate || dryRun.Rows[5].Message != "same as line 4" {
+		t.Errorf("Expected line 7 to repeat line 4, got %+v", dryRun.Rows[5])
+	}
+	if dryRun.Rows[6].Status != types.ImportRowConflict || dryRun.Rows[6].Message != "cannot track time on completed task" {
+		t.Errorf("Expected a conflict for the completed task, got %+v", dryRun.Rows[6])
+	}
+	if len(dryRun.ProjectsCreated) != 1 || dryRun.ProjectsCreated[0] != "Acme / Website" || len(dryRun.TasksCreated) != 1 {
+		t.Errorf("Expected one project and one task to be created, got %v and %v", dryRun.ProjectsCreated, dryRun.TasksCreated)
+	}
+	if dryRun.Rows[0].TimeEntryID != nil {
+		t.Errorf("Expected no time entry IDs in a dry run")
+	}
+
+	// The dry run changes nothing
+	projects, err := s.GetProjectsWithTaskCounts()
+	if err != nil {
+		t.Fatalf("Failed to get projects: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("Expected the dry run not to create projects, got %d", len(projects))
+	}
+
+	committed, err := s.ImportTimeEntries(types.ImportSourceToggl, records, false, true)
+	if err != nil {
+		t.Fatalf("Failed to run import: %v", err)
+	}
+	if committed.Created != 2 || committed.Rows[0].TimeEntryID == nil {
+		t.Fatalf("Unexpected import result %+v", committed)
+	}
+
+	// Task titles match regardless of case
+	first, err := s.GetTimeEntry(*committed.Rows[0].TimeEntryID)
+	if err != nil {
+		t.Fatalf("Failed to get imported entry: %v", err)
+	}
+	second, err := s.GetTimeEntry(*committed.Rows[1].TimeEntryID)
+	if err != nil {
+		t.Fatalf("Failed to get imported entry: %v", err)
+	}
+	if first.TaskID != second.TaskID {
+		t.Errorf("Expected both lines on one task, got tasks %d and %d", first.TaskID, second.TaskID)
+	}
+	if first.Description != "Fix header" || second.Description != "fix header" {
+		t.Errorf("Expected the lines' descriptions on the entries, got %q and %q", first.Description, second.Description)
+	}
+
+	// Importing the same export again only finds duplicates
+	again, err := s.ImportTimeEntries(types.ImportSourceToggl, records, false, true)
+	if err != nil {
+		t.Fatalf("Failed to run import: %v", err)
+	}
+	if again.Created != 0 || again.Duplicates != 4 {
+		t.Errorf("Expected 4 duplicates on re-import, got %+v", again)
+	}
+}
+
+func TestImportTimeEntriesRules(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	maxMinutes := 30
+	if _, err := s.SetProjectTimeEntryRules(project.ID, types.TimeEntryRulesOverride{MaxDurationMinutes: &maxMinutes}); err != nil {
+		t.Fatalf("Failed to set project rules: %v", err)
+	}
+
+	recent := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	old := time.Now().AddDate(0, -3, 0).Truncate(time.Second)
+	records := []types.ImportRecord{
+		{Line: 2, Project: "Website", Description: "Old work", StartTime: old, EndTime: old.Add(time.Hour)},
+		{Line: 3, Project: project.Name, Description: "Long work", StartTime: recent, EndTime: recent.Add(time.Hour)},
+		{Line: 4, Project: "Website", Description: "Recent work", StartTime: recent, EndTime: recent.Add(time.Hour)},
+	}
+
+	// Without an override the default rules and the project's overrides apply
+	result, err := s.ImportTimeEntries(types.ImportSourceToggl, records, true, false)
+	if err != nil {
+		t.Fatalf("Failed to run import: %v", err)
+	}
+	if result.Created != 1 || result.Invalid != 2 {
+		t.Fatalf("Unexpected import counts %+v", result)
+	}
+	if !contains(result.Rows[0].Message, "days in the past") {
+		t.Errorf("Expected the back-dated line to break the backdate limit, got %+v", result.Rows[0])
+	}
+	if !contains(result.Rows[1].Message, "cannot exceed 30 minutes") {
+		t.Errorf("Expected the project's maximum duration to apply, got %+v", result.Rows[1])
+	}
+
+	result, err = s.ImportTimeEntries(types.ImportSourceToggl, records, true, true)
+	if err != nil {
+		t.Fatalf("Failed to run import: %v", err)
+	}
+	if result.Created != 3 {
+		t.Errorf("Expected the override to import every line, got %+v", result)
+	}
+}
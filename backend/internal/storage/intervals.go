@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// interval is a half-open span of time [start, end)
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// mergeIntervals returns the union of intervals as sorted, non-overlapping intervals, dropping empty ones
+func mergeIntervals(intervals []interval) []interval {
+	sorted := make([]interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if iv.end.After(iv.start) {
+			sorted = append(sorted, iv)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+
+	var merged []interval
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && !iv.start.After(merged[n-1].end) {
+			if iv.end.After(merged[n-1].end) {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// clipIntervals returns the parts of intervals falling within [from, until)
+func clipIntervals(intervals []interval, from, until time.Time) []interval {
+	var clipped []interval
+	for _, iv := range intervals {
+		if iv.start.Before(from) {
+			iv.start = from
+		}
+		if iv.end.After(until) {
+			iv.end = until
+		}
+		if iv.end.After(iv.start) {
+			clipped = append(clipped, iv)
+		}
+	}
+	return clipped
+}
+
+// intersectIntervals returns the time covered by both sets of merged intervals
+func intersectIntervals(a, b []interval) []interval {
+	var common []interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].start, a[i].end
+		if b[j].start.After(start) {
+			start = b[j].start
+		}
+		if b[j].end.Before(end) {
+			end = b[j].end
+		}
+		if end.After(start) {
+			common = append(common, interval{start, end})
+		}
+		if a[i].end.Before(b[j].end) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return common
+}
+
+// subtractIntervals returns the time covered by a but not by b, both merged
+func subtractIntervals(a, b []interval) []interval {
+	var rest []interval
+	j := 0
+	for _, iv := range a {
+		start := iv.start
+		for j < len(b) && !b[j].end.After(start) {
+			j++
+		}
+		for k := j; k < len(b) && b[k].start.Before(iv.end); k++ {
+			if b[k].start.After(start) {
+				rest = append(rest, interval{start, b[k].start})
+			}
+			if b[k].end.After(start) {
+				start = b[k].end
+			}
+		}
+		if iv.end.After(start) {
+			rest = append(rest, interval{start, iv.end})
+		}
+	}
+	return rest
+}
+
+// intervalSeconds returns the total length of intervals in seconds
+func intervalSeconds(intervals []interval) int {
+	var total time.Duration
+	for _, iv := range intervals {
+		total += iv.end.Sub(iv.start)
+	}
+	return int(total.Seconds())
+}
+
+// trackedIntervals returns the spans a time entry was tracking, running entries up to now, without its pauses
+func trackedIntervals(entry types.TimeEntry, pauses []types.TimeEntryPause, now time.Time) []interval {
+	end := now
+	if entry.EndTime != nil {
+		end = *entry.EndTime
+	}
+
+	return subtractIntervals(mergeIntervals([]interval{{entry.StartTime, end}}), pauseIntervals(pauses, end))
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// CreateInvoice drafts an invoice from a project's billable, uninvoiced entries that started in the period.
+// Each entry's duration is rounded on its own, entries are grouped into one line per task,
+// and the entries are marked as invoiced so they cannot be billed twice.
+func (s *Storage) CreateInvoice(req types.CreateInvoiceRequest) (*types.Invoice, error) {
+	if !req.PeriodEnd.After(req.PeriodStart) {
+		return nil, fmt.Errorf("invoice period end must be after its start")
+	}
+
+	projectExists, err := s.projectExists(req.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+	}
+	if !projectExists {
+		return nil, fmt.Errorf("project with id %d does not exist", req.ProjectID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	rates, err := getBillingRates(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT te.id, te.task_id, te.start_time, te.duration, t.title
+			  FROM time_entries te
+			  JOIN tasks t ON te.task_id = t.id
+			  WHERE t.project_id = ? AND te.end_time IS NOT NULL AND te.billable = 1 AND te.invoice_id IS NULL
+			  ORDER BY te.start_time ASC, te.id ASC`
+
+	rows, err := tx.Query(query, req.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unbilled time entries: %w", err)
+	}
+
+	var entryIDs []int
+	var lines []*types.InvoiceLine
+	lineByTask := make(map[int]*types.InvoiceLine)
+	for rows.Next() {
+		var id, taskID int
+		var startTime time.Time
+		var duration sql.NullInt64
+		var title string
+		if err := rows.Scan(&id, &taskID, &startTime, &duration, &title); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan unbilled time entry: %w", err)
+		}
+
+		if startTime.Before(req.PeriodStart) || !startTime.Before(req.PeriodEnd) {
+			continue
+		}
+
+		line, ok := lineByTask[taskID]
+		if !ok {
+			lineTaskID := taskID
+			line = &types.InvoiceLine{TaskID: &lineTaskID, Description: title, HourlyRate: rates.rateFor(taskID, req.ProjectID)}
+			lineByTask[taskID] = line
+			lines = append(lines, line)
+		}
+		line.Entries++
+		line.Duration += roundDuration(int(duration.Int64), req.Rounding)
+		entryIDs = append(entryIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error reading unbilled time entry rows: %w", err)
+	}
+	rows.Close()
+
+	if len(entryIDs) == 0 {
+		return nil, fmt.Errorf("no unbilled time entries found for project %d in the period", req.ProjectID)
+	}
+
+	invoice := &types.Invoice{
+		ProjectID:   req.ProjectID,
+		PeriodStart: req.PeriodStart,
+		PeriodEnd:   req.PeriodEnd,
+		Rounding:    req.Rounding,
+		Lines:       []types.InvoiceLine{},
+	}
+	for _, line := range lines {
+		line.Amount = billedAmount(line.Duration, line.HourlyRate)
+		invoice.TotalDuration += line.Duration
+		invoice.TotalAmount += line.Amount
+	}
+
+	insertInvoice := `INSERT INTO invoices (project_id, period_start, period_end, rounding_increment, rounding_mode, total_duration, total_amount, created_at)
+					  VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+					  RETURNING id, created_at`
+	err = tx.QueryRow(insertInvoice, req.ProjectID, req.PeriodStart, req.PeriodEnd, req.Rounding.IncrementMinutes,
+		req.Rounding.Mode, invoice.TotalDuration, invoice.TotalAmount, time.Now()).Scan(&invoice.ID, &invoice.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	insertLine := `INSERT INTO invoice_lines (invoice_id, task_id, description, entries, duration, hourly_rate, amount)
+				   VALUES (?, ?, ?, ?, ?, ?, ?)
+				   RETURNING id`
+	for _, line := range lines {
+		line.InvoiceID = invoice.ID
+		err := tx.QueryRow(insertLine, invoice.ID, line.TaskID, line.Description, line.Entries, line.Duration,
+			line.HourlyRate, line.Amount).Scan(&line.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create invoice line: %w", err)
+		}
+		invoice.Lines = append(invoice.Lines, *line)
+	}
+
+	for _, id := range entryIDs {
+		if _, err := tx.Exec(`UPDATE time_entries SET invoice_id = ? WHERE id = ?`, invoice.ID, id); err != nil {
+			return nil, fmt.Errorf("failed to mark time entry as invoiced: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit invoice transaction: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// GetInvoice retrieves an invoice with its lines
+func (s *Storage) GetInvoice(id int) (*types.Invoice, error) {
+	invoices, err := s.getInvoices("id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(invoices) == 0 {
+		return nil, fmt.Errorf("invoice with id %d not found", id)
+	}
+	return &invoices[0], nil
+}
+
+// GetInvoicesByProject retrieves a project's invoices, newest first
+func (s *Storage) GetInvoicesByProject(projectID int) ([]types.Invoice, error) {
+	return s.getInvoices("project_id = ?", projectID)
+}
+
+// DeleteInvoice deletes an invoice draft and releases its time entries for billing again
+func (s *Storage) DeleteInvoice(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	if _, err := tx.Exec(`UPDATE time_entries SET invoice_id = NULL WHERE invoice_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to release invoiced time entries: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM invoices WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete invoice: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invoice with id %d not found", id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit invoice deletion: %w", err)
+	}
+
+	return nil
+}
+
+// getInvoices loads the invoices matching the given condition with their lines, newest first
+func (s *Storage) getInvoices(condition string, args ...interface{}) ([]types.Invoice, error) {
+	query := `SELECT id, project_id, period_start, period_end, rounding_increment, rounding_mode, total_duration, total_amount, created_at
+			  FROM invoices
+			  WHERE ` + condition + `
+			  ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []types.Invoice{}
+	for rows.Next() {
+		var invoice types.Invoice
+		err := rows.Scan(
+			&invoice.ID,
+			&invoice.ProjectID,
+			&invoice.PeriodStart,
+			&invoice.PeriodEnd,
+			&invoice.Rounding.IncrementMinutes,
+			&invoice.Rounding.Mode,
+			&invoice.TotalDuration,
+			&invoice.TotalAmount,
+			&invoice.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading invoice rows: %w", err)
+	}
+	rows.Close()
+
+	for i := range invoices {
+		invoices[i].Lines, err = s.getInvoiceLines(invoices[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return invoices, nil
+}
+
+// getInvoiceLines loads the lines of an invoice
+func (s *Storage) getInvoiceLines(invoiceID int) ([]types.InvoiceLine, error) {
+	query := `SELECT id, invoice_id, task_id, description, entries, duration, hourly_rate, amount
+			  FROM invoice_lines
+			  WHERE invoice_id = ?
+			  ORDER BY id ASC`
+
+	rows, err := s.db.Query(query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []types.InvoiceLine{}
+	for rows.Next() {
+		var line types.InvoiceLine
+		err := rows.Scan(&line.ID, &line.InvoiceID, &line.TaskID, &line.Description, &line.Entries,
+			&line.Duration, &line.HourlyRate, &line.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading invoice line rows: %w", err)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestCreateInvoice(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	other := createTestTask(t, s, project.ID)
+
+	projectRate := int64(10000)
+	if _, err := s.SetProjectRate(project.ID, &projectRate); err != nil {
+		t.Fatalf("Failed to set project rate: %v", err)
+	}
+	taskRate := int64(15000)
+	if _, err := s.SetTaskRate(other.ID, &taskRate); err != nil {
+		t.Fatalf("Failed to set task rate: %v", err)
+	}
+
+	start := time.Now().Add(-5 * time.Hour).Truncate(time.Second)
+	first := createStoppedEntry(t, s, task.ID, start, 22*time.Minute)
+	createStoppedEntry(t, s, task.ID, start.Add(time.Hour), 38*time.Minute)
+	createStoppedEntry(t, s, other.ID, start.Add(2*time.Hour), 30*time.Minute)
+	excluded := createStoppedEntry(t, s, other.ID, start.Add(3*time.Hour), 30*time.Minute)
+	if _, err := s.SetTimeEntryBillable(excluded.ID, false); err != nil {
+		t.Fatalf("Failed to clear billable flag: %v", err)
+	}
+
+	req := types.CreateInvoiceRequest{
+		ProjectID:   project.ID,
+		PeriodStart: start.Add(-time.Hour),
+		PeriodEnd:   time.Now(),
+		Rounding:    types.RoundingRule{IncrementMinutes: 15},
+	}
+	invoice, err := s.CreateInvoice(req)
+	if err != nil {
+		t.Fatalf("Failed to create invoice: %v", err)
+	}
+
+	// 22 and 38 minutes round to 15 and 45, one line per task
+	if len(invoice.Lines) != 2 {
+		t.Fatalf("Expected 2 invoice lines, got %d", len(invoice.Lines))
+	}
+	if line := invoice.Lines[0]; *line.TaskID != task.ID || line.Entries != 2 || line.Duration != 60*60 || line.Amount != 10000 {
+		t.Errorf("Unexpected first line %+v", line)
+	}
+	if line := invoice.Lines[1]; *line.TaskID != other.ID || line.Duration != 30*60 || line.Amount != 7500 {
+		t.Errorf("Unexpected second line %+v", line)
+	}
+	if invoice.TotalDuration != 90*60 || invoice.TotalAmount != 17500 {
+		t.Errorf("Expected 90 minutes for 17500, got %d seconds for %d", invoice.TotalDuration, invoice.TotalAmount)
+	}
+
+	retrieved, err := s.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatalf("Failed to get invoice: %v", err)
+	}
+	if retrieved.TotalAmount != invoice.TotalAmount || len(retrieved.Lines) != 2 || retrieved.Rounding.IncrementMinutes != 15 {
+		t.Errorf("Retrieved invoice does not match, got %+v", retrieved)
+	}
+
+	// Invoiced entries are locked and cannot be billed twice
+	if _, err := s.CreateInvoice(req); err == nil || !contains(err.Error(), "no unbilled time entries") {
+		t.Errorf("Expected no unbilled time entries error, got %v", err)
+	}
+	if err := s.DeleteTimeEntry(first.ID); err == nil || !contains(err.Error(), "is invoiced") {
+		t.Errorf("Expected invoiced error on delete, got %v", err)
+	}
+	if _, err := s.SetTimeEntryBillable(first.ID, false); err == nil || !contains(err.Error(), "is invoiced") {
+		t.Errorf("Expected invoiced error on billable change, got %v", err)
+	}
+
+	invoices, err := s.GetInvoicesByProject(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to list invoices: %v", err)
+	}
+	if len(invoices) != 1 {
+		t.Errorf("Expected 1 invoice, got %d", len(invoices))
+	}
+
+	// Deleting the draft releases its entries
+	if err := s.DeleteInvoice(invoice.ID); err != nil {
+		t.Fatalf("Failed to delete invoice: %v", err)
+	}
+	billing, err := s.GetTimeEntryBilling(first.ID)
+	if err != nil {
+		t.Fatalf("Failed to get billing: %v", err)
+	}
+	if billing.InvoiceID != nil {
+		t.Errorf("Expected the entry to be released, got invoice %d", *billing.InvoiceID)
+	}
+	if _, err := s.CreateInvoice(req); err != nil {
+		t.Errorf("Expected released entries to be invoiceable again, got %v", err)
+	}
+
+	// Test nonexistent invoice and project
+	if _, err := s.GetInvoice(99999); err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := s.DeleteInvoice(99999); err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	req.ProjectID = 99999
+	if _, err := s.CreateInvoice(req); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("Expected does not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// defaultMatrixRules treats tasks of priority 7 and up as important and tasks due within two days as urgent
+var defaultMatrixRules = types.MatrixRules{
+	ImportantPriority: 7,
+	DeriveUrgency:     true,
+	UrgentWithinDays:  2,
+}
+
+// matrixQuadrants lists the quadrants of the Eisenhower matrix in order
+var matrixQuadrants = []types.MatrixQuadrant{
+	{Quadrant: types.QuadrantDo, Important: true, Urgent: true},
+	{Quadrant: types.QuadrantSchedule, Important: true, Urgent: false},
+	{Quadrant: types.QuadrantDelegate, Important: false, Urgent: true},
+	{Quadrant: types.QuadrantEliminate, Important: false, Urgent: false},
+}
+
+// taskClassification is a task's explicit importance and urgency, nil where the matrix rules apply
+type taskClassification struct {
+	important *bool
+	urgent    *bool
+}
+
+// GetMatrix sorts open tasks, optionally of one project, into the four quadrants of the Eisenhower matrix.
+// Tasks without an explicit importance are important by priority and tasks without an explicit urgency
+// are urgent when due soon, as the matrix rules say.
+func (s *Storage) GetMatrix(projectID *int, now time.Time) (*types.Matrix, error) {
+	condition := "t.status IN ('pending', 'in_progress')"
+	var args []interface{}
+	if projectID != nil {
+		condition += " AND t.project_id = ?"
+		args = append(args, *projectID)
+	}
+
+	tasks, err := s.classifiedTasks(now, condition, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := &types.Matrix{Rules: s.matrix, Total: len(tasks), Quadrants: make([]types.MatrixQuadrant, len(matrixQuadrants))}
+	for i, quadrant := range matrixQuadrants {
+		quadrant.Tasks = []types.MatrixTask{}
+		for _, task := range tasks {
+			if task.Quadrant == quadrant.Quadrant {
+				quadrant.Tasks = append(quadrant.Tasks, task)
+			}
+		}
+		matrix.Quadrants[i] = quadrant
+	}
+
+	return matrix, nil
+}
+
+// ClassifyTasks sets the importance and urgency of tasks in bulk, or returns them to the matrix rules
+func (s *Storage) ClassifyTasks(req types.ClassifyTasksRequest, now time.Time) (*types.ClassifyTasksResult, error) {
+	if len(req.TaskIDs) == 0 {
+		return nil, fmt.Errorf("no tasks to classify")
+	}
+
+	important, urgent := req.Important, req.Urgent
+	switch {
+	case req.Quadrant != "" && (important != nil || urgent != nil || req.Reset):
+		return nil, fmt.Errorf("quadrant cannot be combined with important, urgent or reset")
+	case req.Reset && (important != nil || urgent != nil):
+		return nil, fmt.Errorf("reset cannot be combined with important or urgent")
+	case req.Quadrant != "":
+		for _, quadrant := range matrixQuadrants {
+			if quadrant.Quadrant == req.Quadrant {
+				isImportant, isUrgent := quadrant.Important, quadrant.Urgent
+				important, urgent = &isImportant, &isUrgent
+			}
+		}
+	case !req.Reset && important == nil && urgent == nil:
+		return nil, fmt.Errorf("nothing to change: set quadrant, important, urgent or reset")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	result := &types.ClassifyTasksResult{}
+	seen := make(map[int]bool)
+	var ids []interface{}
+	for _, taskID := range req.TaskIDs {
+		if seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+		ids = append(ids, taskID)
+
+		exists, err := taskExists(tx, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify task existence: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("task with id %d does not exist", taskID)
+		}
+
+		if req.Reset {
+			_, err = tx.Exec(`DELETE FROM task_classifications WHERE task_id = ?`, taskID)
+		} else {
+			// Keep whichever of importance and urgency is not being set
+			_, err = tx.Exec(`INSERT INTO task_classifications (task_id, important, urgent) VALUES (?, ?, ?)
+					  ON CONFLICT(task_id) DO UPDATE SET
+					  important = COALESCE(excluded.important, task_classifications.important),
+					  urgent = COALESCE(excluded.urgent, task_classifications.urgent)`,
+				taskID, important, urgent)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to classify task %d: %w", taskID, err)
+		}
+		result.Updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit task classification transaction: %w", err)
+	}
+
+	result.Tasks, err = s.classifiedTasks(now, "t.id IN (?"+strings.Repeat(", ?", len(ids)-1)+")", ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// classifiedTasks returns the tasks matching a condition with their place in the Eisenhower matrix
+func (s *Storage) classifiedTasks(now time.Time, condition string, args ...interface{}) ([]types.MatrixTask, error) {
+	tasks, err := s.getTasksWithProjects(condition, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	classifications, err := getTaskClassifications(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	classified := make([]types.MatrixTask, 0, len(tasks))
+	for _, task := range tasks {
+		classified = append(classified, s.classifyTask(task, classifications[task.ID], now))
+	}
+	return classified, nil
+}
+
+// classifyTask places a task in the Eisenhower matrix, falling back to the matrix rules where it has no
+// explicit classification
+func (s *Storage) classifyTask(task types.TaskWithProject, class taskClassification, now time.Time) types.MatrixTask {
+	classified := types.MatrixTask{TaskWithProject: task}
+
+	if class.important != nil {
+		classified.Important, classified.ImportanceSource = *class.important, "explicit"
+	} else {
+		classified.Important, classified.ImportanceSource = task.Priority >= s.matrix.ImportantPriority, "priority"
+	}
+
+	switch {
+	case class.urgent != nil:
+		classified.Urgent, classified.UrgencySource = *class.urgent, "explicit"
+	case s.matrix.DeriveUrgency && task.DueDate != nil:
+		urgentUntil := startOfDay(now, s.loc).AddDate(0, 0, s.matrix.UrgentWithinDays)
+		classified.Urgent, classified.UrgencySource = !startOfDay(*task.DueDate, s.loc).After(urgentUntil), "due_date"
+	default:
+		classified.UrgencySource = "none"
+	}
+
+	for _, quadrant := range matrixQuadrants {
+		if quadrant.Important == classified.Important && quadrant.Urgent == classified.Urgent {
+			classified.Quadrant = quadrant.Quadrant
+		}
+	}
+	return classified
+}
+
+// getTaskClassifications loads every explicit task classification by task ID
+func getTaskClassifications(q querier) (map[int]taskClassification, error) {
+	rows, err := q.Query(`SELECT task_id, important, urgent FROM task_classifications`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task classifications: %w", err)
+	}
+	defer rows.Close()
+
+	classifications := make(map[int]taskClassification)
+	for rows.Next() {
+		var taskID int
+		var important, urgent sql.NullBool
+		if err := rows.Scan(&taskID, &important, &urgent); err != nil {
+			return nil, fmt.Errorf("failed to scan task classification: %w", err)
+		}
+
+		var class taskClassification
+		if important.Valid {
+			class.important = &important.Bool
+		}
+		if urgent.Valid {
+			class.urgent = &urgent.Bool
+		}
+		classifications[taskID] = class
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading task classification rows: %w", err)
+	}
+
+	return classifications, nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestMatrix(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.SetCalendar(time.UTC, time.Monday)
+
+	project := createTestProject(t, s)
+	now := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+
+	newTask := func(title string, priority int, dueInDays int) *types.Task {
+		req := types.CreateTaskRequest{ProjectID: project.ID, Title: title, Priority: priority}
+		if dueInDays >= 0 {
+			due := time.Date(2030, 1, 7+dueInDays, 0, 0, 0, 0, time.UTC)
+			req.DueDate = &due
+		}
+		task, err := s.CreateTask(req)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return task
+	}
+
+	doNow := newTask("Do now", 8, 1)
+	plan := newTask("Plan", 8, -1)
+	hand := newTask("Hand off", 2, 2)
+	later := newTask("Later", 2, 3)
+	done := newTask("Done", 9, 0)
+	if _, err := s.UpdateTaskStatus(done.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	quadrantsOf := func(matrix *types.Matrix) map[int]types.Quadrant {
+		placed := make(map[int]types.Quadrant)
+		for _, quadrant := range matrix.Quadrants {
+			for _, task := range quadrant.Tasks {
+				placed[task.ID] = quadrant.Quadrant
+			}
+		}
+		return placed
+	}
+
+	matrix, err := s.GetMatrix(nil, now)
+	if err != nil {
+		t.Fatalf("Failed to get matrix: %v", err)
+	}
+	placed := quadrantsOf(matrix)
+	expected := map[int]types.Quadrant{
+		doNow.ID: types.QuadrantDo,
+		plan.ID:  types.QuadrantSchedule,
+		hand.ID:  types.QuadrantDelegate,
+		later.ID: types.QuadrantEliminate,
+	}
+	if matrix.Total != 4 || len(placed) != len(expected) {
+		t.Fatalf("Expected 4 open tasks, got %+v", placed)
+	}
+	for id, quadrant := range expected {
+		if placed[id] != quadrant {
+			t.Errorf("Task %d: expected %s, got %s", id, quadrant, placed[id])
+		}
+	}
+
+	// Moving a task to a quadrant makes both explicit
+	result, err := s.ClassifyTasks(types.ClassifyTasksRequest{TaskIDs: []int{later.ID, later.ID}, Quadrant: types.QuadrantDo}, now)
+	if err != nil {
+		t.Fatalf("Failed to classify tasks: %v", err)
+	}
+	if result.Updated != 1 || len(result.Tasks) != 1 || result.Tasks[0].Quadrant != types.QuadrantDo ||
+		result.Tasks[0].ImportanceSource != "explicit" || result.Tasks[0].UrgencySource != "explicit" {
+		t.Errorf("Expected the task moved to do, got %+v", result)
+	}
+
+	// Setting only importance keeps urgency derived from the due date
+	notImportant := false
+	result, err = s.ClassifyTasks(types.ClassifyTasksRequest{TaskIDs: []int{doNow.ID}, Important: &notImportant}, now)
+	if err != nil {
+		t.Fatalf("Failed to classify tasks: %v", err)
+	}
+	if task := result.Tasks[0]; task.Quadrant != types.QuadrantDelegate || task.UrgencySource != "due_date" {
+		t.Errorf("Expected the task to be delegated, got %+v", task)
+	}
+
+	if _, err := s.ClassifyTasks(types.ClassifyTasksRequest{TaskIDs: []int{doNow.ID}, Reset: true}, now); err != nil {
+		t.Fatalf("Failed to reset classification: %v", err)
+	}
+
+	// Without derived urgency only explicit urgency counts
+	s.SetMatrixRules(types.MatrixRules{ImportantPriority: 7})
+	matrix, err = s.GetMatrix(&project.ID, now)
+	if err != nil {
+		t.Fatalf("Failed to get matrix: %v", err)
+	}
+	placed = quadrantsOf(matrix)
+	if placed[doNow.ID] != types.QuadrantSchedule || placed[hand.ID] != types.QuadrantEliminate || placed[later.ID] != types.QuadrantDo {
+		t.Errorf("Unexpected quadrants without derived urgency: %+v", placed)
+	}
+
+	tests := []struct {
+		name string
+		req  types.ClassifyTasksRequest
+		err  string
+	}{
+		{"combined", types.ClassifyTasksRequest{TaskIDs: []int{plan.ID}, Quadrant: types.QuadrantDo, Reset: true}, "cannot be combined"},
+		{"reset with value", types.ClassifyTasksRequest{TaskIDs: []int{plan.ID}, Important: &notImportant, Reset: true}, "cannot be combined"},
+		{"empty", types.ClassifyTasksRequest{TaskIDs: []int{plan.ID}}, "nothing to change"},
+		{"no tasks", types.ClassifyTasksRequest{Quadrant: types.QuadrantDo}, "no tasks to classify"},
+		{"unknown task", types.ClassifyTasksRequest{TaskIDs: []int{plan.ID, 9999}, Reset: true}, "does not exist"},
+	}
+	for _, tt := range tests {
+		if _, err := s.ClassifyTasks(tt.req, now); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
@@ -11,6 +11,7 @@ type Migration struct {
 	Version int
 	Name    string
 	Up      string
+	UpFunc  func(q querier) error // Data changes run after Up that SQL cannot express, may be nil
 	Down    string
 }
 
@@ -97,6 +98,334 @@ var migrations = []Migration{
 		       DROP INDEX IF EXISTS idx_projects_created_at;
 		       DROP INDEX IF EXISTS idx_tasks_created_at;`,
 	},
+	{
+		Version: 6,
+		Name:    "add_tasks_completed_at",
+		Up: `ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+		     UPDATE tasks SET completed_at = updated_at WHERE status = 'completed';
+		     CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);
+		     CREATE INDEX IF NOT EXISTS idx_time_entries_start_time ON time_entries(start_time);`,
+		Down: `DROP INDEX IF EXISTS idx_time_entries_start_time;
+		       DROP INDEX IF EXISTS idx_tasks_due_date;
+		       ALTER TABLE tasks DROP COLUMN completed_at;`,
+	},
+	{
+		Version: 7,
+		Name:    "create_board_tables",
+		Up: `CREATE TABLE IF NOT EXISTS board_positions (
+			task_id INTEGER PRIMARY KEY,
+			position INTEGER NOT NULL,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS board_wip_limits (
+			project_id INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			wip_limit INTEGER NOT NULL,
+			PRIMARY KEY (project_id, status),
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		);`,
+		Down: `DROP TABLE IF EXISTS board_wip_limits;
+		       DROP TABLE IF EXISTS board_positions;`,
+	},
+	{
+		Version: 8,
+		Name:    "create_time_entry_pauses_table",
+		Up: `CREATE TABLE IF NOT EXISTS time_entry_pauses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time_entry_id INTEGER NOT NULL,
+			paused_at DATETIME NOT NULL,
+			resumed_at DATETIME,
+			FOREIGN KEY (time_entry_id) REFERENCES time_entries(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_time_entry_pauses_time_entry_id ON time_entry_pauses(time_entry_id);`,
+		Down: `DROP INDEX IF EXISTS idx_time_entry_pauses_time_entry_id;
+		       DROP TABLE IF EXISTS time_entry_pauses;`,
+	},
+	{
+		Version: 9,
+		Name:    "create_time_entry_reviews_table",
+		Up: `ALTER TABLE time_entries ADD COLUMN last_heartbeat_at DATETIME;
+		CREATE TABLE IF NOT EXISTS time_entry_reviews (
+			time_entry_id INTEGER PRIMARY KEY,
+			action TEXT NOT NULL,
+			detected_at DATETIME NOT NULL,
+			resolved_at DATETIME,
+			FOREIGN KEY (time_entry_id) REFERENCES time_entries(id) ON DELETE CASCADE
+		);`,
+		Down: `DROP TABLE IF EXISTS time_entry_reviews;
+		       ALTER TABLE time_entries DROP COLUMN last_heartbeat_at;`,
+	},
+	{
+		Version: 10,
+		Name:    "create_time_entry_idle_spans_table",
+		Up: `CREATE TABLE IF NOT EXISTS time_entry_idle_spans (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time_entry_id INTEGER NOT NULL,
+			started_at DATETIME NOT NULL,
+			ended_at DATETIME NOT NULL,
+			resolution TEXT,
+			resolved_at DATETIME,
+			FOREIGN KEY (time_entry_id) REFERENCES time_entries(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_time_entry_idle_spans_time_entry_id ON time_entry_idle_spans(time_entry_id);`,
+		Down: `DROP INDEX IF EXISTS idx_time_entry_idle_spans_time_entry_id;
+		       DROP TABLE IF EXISTS time_entry_idle_spans;`,
+	},
+	{
+		Version: 11,
+		Name:    "create_task_tags_table",
+		Up: `CREATE TABLE IF NOT EXISTS task_tags (
+			task_id INTEGER NOT NULL,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (task_id, tag),
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(tag);`,
+		Down: `DROP INDEX IF EXISTS idx_task_tags_tag;
+		       DROP TABLE IF EXISTS task_tags;`,
+	},
+	{
+		Version: 12,
+		Name:    "create_billing_tables",
+		Up: `CREATE TABLE IF NOT EXISTS project_rates (
+			project_id INTEGER PRIMARY KEY,
+			hourly_rate INTEGER NOT NULL,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS task_rates (
+			task_id INTEGER PRIMARY KEY,
+			hourly_rate INTEGER NOT NULL,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS invoices (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER NOT NULL,
+			period_start DATETIME NOT NULL,
+			period_end DATETIME NOT NULL,
+			rounding_increment INTEGER NOT NULL DEFAULT 0,
+			rounding_mode TEXT NOT NULL DEFAULT '',
+			total_duration INTEGER NOT NULL,
+			total_amount INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS invoice_lines (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			invoice_id INTEGER NOT NULL,
+			task_id INTEGER,
+			description TEXT NOT NULL,
+			entries INTEGER NOT NULL,
+			duration INTEGER NOT NULL,
+			hourly_rate INTEGER NOT NULL,
+			amount INTEGER NOT NULL,
+			FOREIGN KEY (invoice_id) REFERENCES invoices(id) ON DELETE CASCADE,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE SET NULL
+		);
+		ALTER TABLE time_entries ADD COLUMN billable INTEGER NOT NULL DEFAULT 1;
+		ALTER TABLE time_entries ADD COLUMN invoice_id INTEGER;
+		CREATE INDEX IF NOT EXISTS idx_time_entries_invoice_id ON time_entries(invoice_id);
+		CREATE INDEX IF NOT EXISTS idx_invoices_project_id ON invoices(project_id);`,
+		Down: `DROP INDEX IF EXISTS idx_invoices_project_id;
+		       DROP INDEX IF EXISTS idx_time_entries_invoice_id;
+		       ALTER TABLE time_entries DROP COLUMN invoice_id;
+		       ALTER TABLE time_entries DROP COLUMN billable;
+		       DROP TABLE IF EXISTS invoice_lines;
+		       DROP TABLE IF EXISTS invoices;
+		       DROP TABLE IF EXISTS task_rates;
+		       DROP TABLE IF EXISTS project_rates;`,
+	},
+	{
+		Version: 13,
+		Name:    "create_project_time_entry_rules_table",
+		Up: `CREATE TABLE IF NOT EXISTS project_time_entry_rules (
+			project_id INTEGER PRIMARY KEY,
+			max_backdate_days INTEGER,
+			max_duration_minutes INTEGER,
+			min_duration_seconds INTEGER,
+			clock_skew_seconds INTEGER,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		);`,
+		Down: `DROP TABLE IF EXISTS project_time_entry_rules;`,
+	},
+	{
+		// Timestamps are rewritten through the driver so migrated rows match the format of new ones
+		Version: 14,
+		Name:    "normalize_timestamps_to_utc",
+		Up:      `SELECT 1;`,
+		UpFunc:  normalizeTimestampsToUTC,
+		// UTC timestamps stay valid after rolling back, so there is nothing to undo
+		Down: `SELECT 1;`,
+	},
+	{
+		Version: 15,
+		Name:    "create_budget_tables",
+		Up: `CREATE TABLE IF NOT EXISTS budgets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER,
+			task_id INTEGER,
+			period TEXT NOT NULL CHECK (period IN ('total', 'week', 'month')),
+			limit_seconds INTEGER NOT NULL,
+			thresholds TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			CHECK ((project_id IS NULL) != (task_id IS NULL)),
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS budget_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			budget_id INTEGER NOT NULL,
+			threshold INTEGER NOT NULL,
+			period TEXT NOT NULL,
+			consumed INTEGER NOT NULL,
+			limit_seconds INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			acknowledged_at DATETIME,
+			UNIQUE (budget_id, threshold, period),
+			FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_budgets_project_id ON budgets(project_id);
+		CREATE INDEX IF NOT EXISTS idx_budgets_task_id ON budgets(task_id);`,
+		Down: `DROP INDEX IF EXISTS idx_budgets_task_id;
+		       DROP INDEX IF EXISTS idx_budgets_project_id;
+		       DROP TABLE IF EXISTS budget_alerts;
+		       DROP TABLE IF EXISTS budgets;`,
+	},
+	{
+		Version: 16,
+		Name:    "create_time_entry_imports_table",
+		Up: `CREATE TABLE IF NOT EXISTS time_entry_imports (
+			time_entry_id INTEGER PRIMARY KEY,
+			source TEXT NOT NULL,
+			import_key TEXT NOT NULL,
+			imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (source, import_key),
+			FOREIGN KEY (time_entry_id) REFERENCES time_entries(id) ON DELETE CASCADE
+		);`,
+		Down: `DROP TABLE IF EXISTS time_entry_imports;`,
+	},
+	{
+		Version: 17,
+		Name:    "add_time_entry_revisions",
+		Up: `ALTER TABLE time_entries ADD COLUMN updated_at DATETIME;
+		ALTER TABLE time_entries ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;`,
+		Down: `ALTER TABLE time_entries DROP COLUMN revision;
+		       ALTER TABLE time_entries DROP COLUMN updated_at;`,
+	},
+	{
+		Version: 18,
+		Name:    "create_calendar_blocks_table",
+		Up: `CREATE TABLE IF NOT EXISTS calendar_blocks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			calendar TEXT NOT NULL,
+			uid TEXT NOT NULL,
+			summary TEXT NOT NULL DEFAULT '',
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			all_day BOOLEAN NOT NULL DEFAULT FALSE,
+			time_entry_id INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (calendar, uid, start_time),
+			FOREIGN KEY (time_entry_id) REFERENCES time_entries(id) ON DELETE SET NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_calendar_blocks_start_time ON calendar_blocks(start_time);`,
+		Down: `DROP INDEX IF EXISTS idx_calendar_blocks_start_time;
+		       DROP TABLE IF EXISTS calendar_blocks;`,
+	},
+	{
+		Version: 19,
+		Name:    "create_daily_plan_items_table",
+		Up: `CREATE TABLE IF NOT EXISTS daily_plan_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			plan_date TEXT NOT NULL,
+			task_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			carried_from TEXT,
+			carried_to TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (plan_date, task_id),
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_daily_plan_items_task_id ON daily_plan_items(task_id);`,
+		Down: `DROP INDEX IF EXISTS idx_daily_plan_items_task_id;
+		       DROP TABLE IF EXISTS daily_plan_items;`,
+	},
+	{
+		Version: 20,
+		Name:    "create_planned_blocks_table",
+		Up: `CREATE TABLE IF NOT EXISTS planned_blocks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_planned_blocks_start_time ON planned_blocks(start_time);
+		CREATE INDEX IF NOT EXISTS idx_planned_blocks_task_id ON planned_blocks(task_id);`,
+		Down: `DROP INDEX IF EXISTS idx_planned_blocks_task_id;
+		       DROP INDEX IF EXISTS idx_planned_blocks_start_time;
+		       DROP TABLE IF EXISTS planned_blocks;`,
+	},
+	{
+		Version: 21,
+		Name:    "create_task_estimates_table",
+		Up: `CREATE TABLE IF NOT EXISTS task_estimates (
+			task_id INTEGER PRIMARY KEY,
+			minutes INTEGER NOT NULL,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);`,
+		Down: `DROP TABLE IF EXISTS task_estimates;`,
+	},
+	{
+		Version: 22,
+		Name:    "create_time_off_table",
+		Up: `CREATE TABLE IF NOT EXISTS time_off (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			start_date TEXT NOT NULL,
+			end_date TEXT NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_time_off_dates ON time_off(start_date, end_date);`,
+		Down: `DROP INDEX IF EXISTS idx_time_off_dates;
+		       DROP TABLE IF EXISTS time_off;`,
+	},
+	{
+		Version: 23,
+		Name:    "create_task_classifications_table",
+		Up: `CREATE TABLE IF NOT EXISTS task_classifications (
+			task_id INTEGER PRIMARY KEY,
+			important BOOLEAN,
+			urgent BOOLEAN,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);`,
+		Down: `DROP TABLE IF EXISTS task_classifications;`,
+	},
+	{
+		Version: 24,
+		Name:    "create_task_review_tables",
+		Up: `CREATE TABLE IF NOT EXISTS task_reviews (
+			task_id INTEGER PRIMARY KEY,
+			reviewed_at DATETIME NOT NULL,
+			deferred_until DATETIME,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS task_review_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			reviewed_at DATETIME NOT NULL,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_task_review_log_reviewed_at ON task_review_log(reviewed_at);`,
+		Down: `DROP INDEX IF EXISTS idx_task_review_log_reviewed_at;
+		       DROP TABLE IF EXISTS task_review_log;
+		       DROP TABLE IF EXISTS task_reviews;`,
+	},
 }
 
 // migrate runs all pending migrations
@@ -175,6 +504,11 @@ func (s *Storage) applyMigration(migration Migration) error {
 	if _, err := tx.Exec(migration.Up); err != nil {
 		return fmt.Errorf("failed to execute migration SQL: %w", err)
 	}
+	if migration.UpFunc != nil {
+		if err := migration.UpFunc(tx); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
 
 	// Record the migration in schema_migrations table
 	recordQuery := `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// MaxPageLimit is the largest page size accepted by list operations
+const MaxPageLimit = 500
+
+// DefaultPageLimit is the page size of list requests that continue from a cursor without asking for a size
+const DefaultPageLimit = 100
+
+// iterateBatchSize is the page size used internally by the Iterate* helpers
+const iterateBatchSize = 200
+
+// pageCursor identifies the last row of a page by its sort key values and ID
+type pageCursor struct {
+	Sort []string `json:"s"`
+	ID   int      `json:"id"`
+}
+
+// encodeCursor serializes a cursor into an opaque URL-safe string
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor, checking it carries the expected number of sort keys
+func decodeCursor(encoded string, sortKeys int) (*pageCursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Sort) != sortKeys {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &c, nil
+}
+
+// validatePageRequest checks the limit of a page request
+func validatePageRequest(page types.PageRequest) error {
+	if page.Limit < 0 || page.Limit > MaxPageLimit {
+		return fmt.Errorf("limit must be between 0 and %d", MaxPageLimit)
+	}
+	return nil
+}
+
+// finishPage trims a result fetched with one extra row and sets the cursor of the next page
+func finishPage[T any](items []T, cursors []pageCursor, limit int) types.Page[T] {
+	page := types.Page[T]{Items: items}
+	if limit > 0 && len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = encodeCursor(cursors[limit-1])
+	}
+	return page
+}
+
+// countRows runs a COUNT query for a page that asked for its total
+func (s *Storage) countRows(query string, args ...interface{}) (*int, error) {
+	var total int
+	if err := s.db.QueryRow(query, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return &total, nil
+}
+
+// iteratePages walks every page returned by fetch and calls fn for each item.
+// Iteration stops at the first error returned by fn.
+func iteratePages[T any](fetch func(cursor string) (types.Page[T], error), fn func(T) error) error {
+	cursor := ""
+	for {
+		page, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
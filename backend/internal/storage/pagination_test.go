@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestListTasksByProjectPagination(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+
+	// Equal priorities force the cursor to fall back to created_at and id
+	priorities := []int{9, 5, 5, 5, 1}
+	for i, priority := range priorities {
+		_, err := s.CreateTask(types.CreateTaskRequest{
+			ProjectID: project.ID,
+			Title:     "Task " + string(rune('A'+i)),
+			Priority:  priority,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	all, err := s.GetTasksByProject(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get tasks: %v", err)
+	}
+
+	var paged []types.Task
+	cursor := ""
+	pages := 0
+	for {
+		page, err := s.ListTasksByProject(project.ID, types.PageRequest{Limit: 2, Cursor: cursor, IncludeTotal: true})
+		if err != nil {
+			t.Fatalf("Failed to list tasks: %v", err)
+		}
+		if page.Total == nil || *page.Total != len(priorities) {
+			t.Errorf("Expected total %d, got %v", len(priorities), page.Total)
+		}
+		paged = append(paged, page.Items...)
+		pages++
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("Expected %d tasks across pages, got %d", len(all), len(paged))
+	}
+	for i := range all {
+		if paged[i].ID != all[i].ID {
+			t.Errorf("Expected task %d at position %d, got %d", all[i].ID, i, paged[i].ID)
+		}
+	}
+
+	// Test malformed cursor
+	_, err = s.ListTasksByProject(project.ID, types.PageRequest{Limit: 2, Cursor: "not-a-cursor"})
+	if err == nil || !contains(err.Error(), "invalid cursor") {
+		t.Errorf("Expected invalid cursor error, got %v", err)
+	}
+}
+
+func TestIterateTimeEntriesByProject(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	base := time.Now().Add(-10 * time.Hour)
+	for i := 0; i < 5; i++ {
+		start := base.Add(time.Duration(i) * time.Hour)
+		end := start.Add(30 * time.Minute)
+		_, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: start, EndTime: &end})
+		if err != nil {
+			t.Fatalf("Failed to create time entry: %v", err)
+		}
+	}
+
+	page, err := s.ListTimeEntriesByProject(project.ID, types.PageRequest{Limit: 3})
+	if err != nil {
+		t.Fatalf("Failed to list time entries: %v", err)
+	}
+	if len(page.Items) != 3 || page.NextCursor == "" {
+		t.Fatalf("Expected a first page of 3 entries with a next cursor, got %d", len(page.Items))
+	}
+
+	var seen []time.Time
+	err = s.IterateTimeEntriesByProject(project.ID, func(entry types.TimeEntry) error {
+		seen = append(seen, entry.StartTime)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to iterate time entries: %v", err)
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("Expected 5 entries, got %d", len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i].After(seen[i-1]) {
+			t.Errorf("Expected entries newest first")
+		}
+	}
+}
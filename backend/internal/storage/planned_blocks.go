@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// maxPlannedBlockDuration is the longest a planned block may last
+const maxPlannedBlockDuration = 24 * time.Hour
+
+// CreatePlannedBlock plans time on a task. Planned blocks may not overlap each other.
+func (s *Storage) CreatePlannedBlock(req types.CreatePlannedBlockRequest) (*types.PlannedBlock, error) {
+	if err := s.validatePlannedBlock(s.db, req, 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var id int
+	err := s.db.QueryRow(`INSERT INTO planned_blocks (task_id, start_time, end_time, note, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?)
+			  RETURNING id`,
+		req.TaskID, req.StartTime, req.EndTime, req.Note, now, now).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create planned block: %w", err)
+	}
+
+	return s.GetPlannedBlock(id)
+}
+
+// GetPlannedBlock retrieves a planned block by ID
+func (s *Storage) GetPlannedBlock(id int) (*types.PlannedBlock, error) {
+	blocks, err := getPlannedBlocks(s.db, "pb.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("planned block with id %d not found", id)
+	}
+	return &blocks[0], nil
+}
+
+// GetPlannedBlocks returns the planned blocks overlapping [from, until), optionally only those of a task
+func (s *Storage) GetPlannedBlocks(from, until time.Time, taskID *int) ([]types.PlannedBlock, error) {
+	if taskID != nil {
+		return getPlannedBlocks(s.db, "pb.start_time < ? AND pb.end_time > ? AND pb.task_id = ?", until, from, *taskID)
+	}
+	return getPlannedBlocks(s.db, "pb.start_time < ? AND pb.end_time > ?", until, from)
+}
+
+// UpdatePlannedBlock replaces a planned block's task, times and note
+func (s *Storage) UpdatePlannedBlock(id int, req types.CreatePlannedBlockRequest) (*types.PlannedBlock, error) {
+	if _, err := s.GetPlannedBlock(id); err != nil {
+		return nil, err
+	}
+	if err := s.validatePlannedBlock(s.db, req, id); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Exec(`UPDATE planned_blocks SET task_id = ?, start_time = ?, end_time = ?, note = ?, updated_at = ? WHERE id = ?`,
+		req.TaskID, req.StartTime, req.EndTime, req.Note, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update planned block: %w", err)
+	}
+
+	return s.GetPlannedBlock(id)
+}
+
+// DeletePlannedBlock deletes a planned block
+func (s *Storage) DeletePlannedBlock(id int) error {
+	result, err := s.db.Exec(`DELETE FROM planned_blocks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete planned block: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("planned block with id %d not found", id)
+	}
+
+	return nil
+}
+
+// GetPlanAdherence overlays the planned blocks of the week starting at weekStart on the time tracked
+// during it. Day boundaries follow weekStart's location and running entries are counted up to now.
+// Each block's time is split into time on plan, off plan and missed; tracked time outside every
+// block is unplanned.
+func (s *Storage) GetPlanAdherence(weekStart time.Time, now time.Time) (*types.PlanAdherence, error) {
+	loc := weekStart.Location()
+	weekStart = startOfDay(weekStart, loc)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	blocks, err := s.GetPlannedBlocks(weekStart, weekEnd, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.getTimelineEntries(weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+	var all []interval
+	byTask := make(map[int][]interval)
+	for _, te := range entries {
+		spans := trackedIntervals(te.entry, te.pauses, now)
+		all = append(all, spans...)
+		byTask[te.entry.TaskID] = append(byTask[te.entry.TaskID], spans...)
+	}
+	tracked := mergeIntervals(all)
+	for taskID, spans := range byTask {
+		byTask[taskID] = mergeIntervals(spans)
+	}
+
+	adherence := &types.PlanAdherence{
+		Week:     weekLabel(weekStart),
+		Timezone: loc.String(),
+		Start:    weekStart,
+		End:      weekEnd,
+		Totals:   adherenceTotals(blocks, tracked, byTask, weekStart, weekEnd),
+		Days:     make([]types.AdherenceDay, 7),
+		Blocks:   []types.PlannedBlockAdherence{},
+	}
+
+	for i := range adherence.Days {
+		dayStart := weekStart.AddDate(0, 0, i)
+		adherence.Days[i] = types.AdherenceDay{
+			Date:            dayStart.Format("2006-01-02"),
+			AdherenceTotals: adherenceTotals(blocks, tracked, byTask, dayStart, weekStart.AddDate(0, 0, i+1)),
+		}
+	}
+
+	for _, block := range blocks {
+		span := []interval{{block.StartTime, block.EndTime}}
+		covered := intervalSeconds(intersectIntervals(span, tracked))
+		onPlan := intervalSeconds(intersectIntervals(span, byTask[block.TaskID]))
+
+		block.StartTime, block.EndTime = block.StartTime.In(loc), block.EndTime.In(loc)
+		adherence.Blocks = append(adherence.Blocks, types.PlannedBlockAdherence{
+			Block:   block,
+			OnPlan:  onPlan,
+			OffPlan: covered - onPlan,
+			Missed:  int(block.EndTime.Sub(block.StartTime).Seconds()) - covered,
+		})
+	}
+
+	return adherence, nil
+}
+
+// adherenceTotals compares the blocks with tracked time within [from, until). tracked is the union of
+// all tracked time and byTask the tracked time per task, all merged.
+func adherenceTotals(blocks []types.PlannedBlock, tracked []interval, byTask map[int][]interval, from, until time.Time) types.AdherenceTotals {
+	var totals types.AdherenceTotals
+	var planned, onPlan []interval
+	for _, block := range blocks {
+		span := clipIntervals([]interval{{block.StartTime, block.EndTime}}, from, until)
+		planned = append(planned, span...)
+		onPlan = append(onPlan, intersectIntervals(span, byTask[block.TaskID])...)
+	}
+	planned = mergeIntervals(planned)
+	tracked = clipIntervals(tracked, from, until)
+
+	covered := intervalSeconds(intersectIntervals(planned, tracked))
+	totals.Planned = intervalSeconds(planned)
+	totals.OnPlan = intervalSeconds(mergeIntervals(onPlan))
+	totals.OffPlan = covered - totals.OnPlan
+	totals.Missed = totals.Planned - covered
+	totals.Unplanned = intervalSeconds(subtractIntervals(tracked, planned))
+	if totals.Planned > 0 {
+		totals.Adherence = float64(totals.OnPlan) / float64(totals.Planned)
+	}
+	return totals
+}
+
+// validatePlannedBlock checks a planned block's times and task, and that it overlaps no other block
+func (s *Storage) validatePlannedBlock(q querier, req types.CreatePlannedBlockRequest, excludeID int) error {
+	if !req.EndTime.After(req.StartTime) {
+		return fmt.Errorf("end time must be after start time")
+	}
+	if req.EndTime.Sub(req.StartTime) > maxPlannedBlockDuration {
+		return fmt.Errorf("planned block must not be longer than 24 hours")
+	}
+
+	exists, err := taskExists(q, req.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to check task existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("task with id %d does not exist", req.TaskID)
+	}
+
+	var otherID int
+	err = q.QueryRow(`SELECT id FROM planned_blocks WHERE id != ? AND start_time < ? AND end_time > ? ORDER BY start_time ASC LIMIT 1`,
+		excludeID, req.EndTime, req.StartTime).Scan(&otherID)
+	if err == nil {
+		return fmt.Errorf("planned block overlaps planned block %d", otherID)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check for overlapping planned blocks: %w", err)
+	}
+
+	return nil
+}
+
+// getPlannedBlocks returns the planned blocks matching a condition with their task, ordered by start time
+func getPlannedBlocks(q querier, condition string, args ...interface{}) ([]types.PlannedBlock, error) {
+	query := `SELECT pb.id, pb.task_id, t.title, t.project_id, pb.start_time, pb.end_time, pb.note, pb.created_at, pb.updated_at
+			  FROM planned_blocks pb
+			  JOIN tasks t ON pb.task_id = t.id
+			  WHERE ` + condition + `
+			  ORDER BY pb.start_time ASC, pb.id ASC`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query planned blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []types.PlannedBlock{}
+	for rows.Next() {
+		var block types.PlannedBlock
+		err := rows.Scan(
+			&block.ID,
+			&block.TaskID,
+			&block.TaskTitle,
+			&block.ProjectID,
+			&block.StartTime,
+			&block.EndTime,
+			&block.Note,
+			&block.CreatedAt,
+			&block.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan planned block: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading planned block rows: %w", err)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestPlannedBlockCRUD(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	start := time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)
+	block, err := s.CreatePlannedBlock(types.CreatePlannedBlockRequest{TaskID: task.ID, StartTime: start, EndTime: start.Add(90 * time.Minute), Note: "Draft"})
+	if err != nil {
+		t.Fatalf("Failed to create planned block: %v", err)
+	}
+	if block.TaskTitle != task.Title || block.ProjectID != project.ID || block.Note != "Draft" {
+		t.Errorf("Expected the block with its task, got %+v", block)
+	}
+
+	tests := []struct {
+		name string
+		req  types.CreatePlannedBlockRequest
+		err  string
+	}{
+		{"overlap", types.CreatePlannedBlockRequest{TaskID: task.ID, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour)}, "overlaps"},
+		{"reversed", types.CreatePlannedBlockRequest{TaskID: task.ID, StartTime: start, EndTime: start.Add(-time.Hour)}, "must be after"},
+		{"too long", types.CreatePlannedBlockRequest{TaskID: task.ID, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(27 * time.Hour)}, "longer than"},
+		{"unknown task", types.CreatePlannedBlockRequest{TaskID: 9999, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)}, "does not exist"},
+	}
+	for _, tt := range tests {
+		if _, err := s.CreatePlannedBlock(tt.req); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	// Moving a block does not conflict with itself
+	updated, err := s.UpdatePlannedBlock(block.ID, types.CreatePlannedBlockRequest{TaskID: task.ID, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to update planned block: %v", err)
+	}
+	if !updated.StartTime.Equal(start.Add(30*time.Minute)) || updated.Note != "" {
+		t.Errorf("Expected the block to be replaced, got %+v", updated)
+	}
+
+	blocks, err := s.GetPlannedBlocks(start, start.AddDate(0, 0, 7), &task.ID)
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("Expected 1 planned block, got %d, %v", len(blocks), err)
+	}
+
+	if err := s.DeletePlannedBlock(block.ID); err != nil {
+		t.Fatalf("Failed to delete planned block: %v", err)
+	}
+	if _, err := s.GetPlannedBlock(block.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected the block to be gone, got %v", err)
+	}
+}
+
+func TestPlanAdherence(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	planned := createTestTask(t, s, project.ID)
+	other := createTestTask(t, s, project.ID)
+
+	weekStart := startOfDay(time.Now().AddDate(0, 0, -9), time.UTC)
+	at := func(hour, minute int) time.Time {
+		return weekStart.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	// Two hours planned on Monday morning
+	block, err := s.CreatePlannedBlock(types.CreatePlannedBlockRequest{TaskID: planned.ID, StartTime: at(9, 0), EndTime: at(11, 0)})
+	if err != nil {
+		t.Fatalf("Failed to create planned block: %v", err)
+	}
+
+	// An hour on plan, half an hour on something else, half an hour missed, then an hour of unplanned work
+	createStoppedEntry(t, s, planned.ID, at(9, 0), time.Hour)
+	createStoppedEntry(t, s, other.ID, at(10, 0), 30*time.Minute)
+	createStoppedEntry(t, s, other.ID, at(14, 0), time.Hour)
+
+	adherence, err := s.GetPlanAdherence(weekStart, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to get plan adherence: %v", err)
+	}
+
+	expected := types.AdherenceTotals{Planned: 7200, OnPlan: 3600, OffPlan: 1800, Missed: 1800, Unplanned: 3600, Adherence: 0.5}
+	if adherence.Totals != expected {
+		t.Errorf("Expected totals %+v, got %+v", expected, adherence.Totals)
+	}
+	if adherence.Days[0].AdherenceTotals != expected || adherence.Days[1].Planned != 0 {
+		t.Errorf("Expected all of it on Monday, got %+v", adherence.Days[:2])
+	}
+
+	if len(adherence.Blocks) != 1 {
+		t.Fatalf("Expected 1 block, got %d", len(adherence.Blocks))
+	}
+	b := adherence.Blocks[0]
+	if b.Block.ID != block.ID || b.OnPlan != 3600 || b.OffPlan != 1800 || b.Missed != 1800 {
+		t.Errorf("Unexpected block adherence %+v", b)
+	}
+}
+
+func TestSubtractIntervals(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := func(hour int) time.Time { return base.Add(time.Duration(hour) * time.Hour) }
+
+	a := []interval{{h(0), h(4)}, {h(6), h(10)}}
+	b := []interval{{h(1), h(2)}, {h(3), h(7)}, {h(9), h(12)}}
+	rest := subtractIntervals(a, b)
+
+	expected := []interval{{h(0), h(1)}, {h(2), h(3)}, {h(7), h(9)}}
+	if len(rest) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, rest)
+	}
+	for i := range expected {
+		if !rest[i].start.Equal(expected[i].start) || !rest[i].end.Equal(expected[i].end) {
+			t.Errorf("Interval %d: expected %v, got %v", i, expected[i], rest[i])
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// defaultDailyPlanSlots is the most tasks a daily plan holds until SetDailyPlanSlots is called
+const defaultDailyPlanSlots = 5
+
+// planItem is a stored daily plan entry
+type planItem struct {
+	taskID      int
+	position    int
+	carriedFrom *string
+	carriedTo   *string
+}
+
+// GetDailyPlan returns the plan for the day containing day, in day's location, with a review of
+// the planned tasks against what was completed and tracked so far
+func (s *Storage) GetDailyPlan(day time.Time) (*types.DailyPlan, error) {
+	return s.GetDailyPlanAt(day, time.Now())
+}
+
+// GetDailyPlanAt returns the plan for a day, counting running time entries up to now
+func (s *Storage) GetDailyPlanAt(day time.Time, now time.Time) (*types.DailyPlan, error) {
+	dayStart := startOfDay(day, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	date := dayStart.Format("2006-01-02")
+
+	items, err := getPlanItems(s.db, date)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.getTasksWithProjects("t.id IN (SELECT task_id FROM daily_plan_items WHERE plan_date = ?)", date)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]types.TaskWithProject, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	tracked, titles, err := s.trackedByTask(dayStart, dayEnd, now)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &types.DailyPlan{
+		Date:      date,
+		SlotLimit: s.planSlots,
+		Items:     []types.DailyPlanItem{},
+		Review:    types.DailyPlanReview{Unplanned: []types.DailyPlanTask{}},
+	}
+
+	planned := make(map[int]bool, len(items))
+	for i, item := range items {
+		planned[item.taskID] = true
+		task := byID[item.taskID]
+		completed := task.Status == types.TaskStatusCompleted && task.CompletedAt != nil && task.CompletedAt.Before(dayEnd)
+
+		plan.Items = append(plan.Items, types.DailyPlanItem{
+			Position:    i + 1, // Deleted tasks leave gaps in the stored positions
+			Task:        task,
+			CarriedFrom: item.carriedFrom,
+			CarriedTo:   item.carriedTo,
+			Completed:   completed,
+			Tracked:     tracked[item.taskID],
+		})
+
+		plan.Review.Planned++
+		plan.Review.TrackedPlanned += tracked[item.taskID]
+		if completed {
+			plan.Review.Completed++
+		}
+	}
+	if plan.Review.Planned > 0 {
+		plan.Review.CompletionRate = float64(plan.Review.Completed) / float64(plan.Review.Planned)
+	}
+
+	// Work outside the plan is either tracked time or a task completed during the day
+	unplanned := make(map[int]*types.DailyPlanTask)
+	for taskID, seconds := range tracked {
+		if planned[taskID] {
+			continue
+		}
+		plan.Review.TrackedOther += seconds
+		unplanned[taskID] = &types.DailyPlanTask{TaskID: taskID, Title: titles[taskID].title, ProjectID: titles[taskID].projectID, Tracked: seconds}
+	}
+
+	completedTasks, err := s.getTasksWithProjects("t.status = ? AND t.completed_at >= ? AND t.completed_at < ?",
+		types.TaskStatusCompleted, dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range completedTasks {
+		if planned[task.ID] {
+			continue
+		}
+		if unplanned[task.ID] == nil {
+			unplanned[task.ID] = &types.DailyPlanTask{TaskID: task.ID, Title: task.Title, ProjectID: task.ProjectID}
+		}
+		unplanned[task.ID].Completed = true
+	}
+
+	for _, task := range unplanned {
+		plan.Review.Unplanned = append(plan.Review.Unplanned, *task)
+	}
+	sort.Slice(plan.Review.Unplanned, func(i, j int) bool {
+		a, b := plan.Review.Unplanned[i], plan.Review.Unplanned[j]
+		if a.Tracked != b.Tracked {
+			return a.Tracked > b.Tracked
+		}
+		return a.TaskID < b.TaskID
+	})
+
+	return plan, nil
+}
+
+// SetDailyPlan replaces the plan for the day containing day with the given tasks in order. Completed
+// and cancelled tasks may stay on a plan but not be added to it. Tasks that stay keep where they were
+// carried from and to.
+func (s *Storage) SetDailyPlan(day time.Time, taskIDs []int) (*types.DailyPlan, error) {
+	if len(taskIDs) > s.planSlots {
+		return nil, fmt.Errorf("daily plan holds at most %d tasks", s.planSlots)
+	}
+
+	date := startOfDay(day, day.Location()).Format("2006-01-02")
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	existing, err := getPlanItems(tx, date)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[int]planItem, len(existing))
+	for _, item := range existing {
+		previous[item.taskID] = item
+	}
+
+	seen := make(map[int]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if seen[taskID] {
+			return nil, fmt.Errorf("task %d is listed more than once", taskID)
+		}
+		seen[taskID] = true
+
+		if _, ok := previous[taskID]; ok {
+			continue
+		}
+		if err := checkPlannable(tx, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range existing {
+		if !seen[item.taskID] {
+			if err := clearCarryLinks(tx, date, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM daily_plan_items WHERE plan_date = ?`, date); err != nil {
+		return nil, fmt.Errorf("failed to clear daily plan: %w", err)
+	}
+
+	now := time.Now()
+	for i, taskID := range taskIDs {
+		item := previous[taskID]
+		if _, err := tx.Exec(`INSERT INTO daily_plan_items (plan_date, task_id, position, carried_from, carried_to, created_at)
+				  VALUES (?, ?, ?, ?, ?, ?)`,
+			date, taskID, i+1, item.carriedFrom, item.carriedTo, now); err != nil {
+			return nil, fmt.Errorf("failed to add task %d to daily plan: %w", taskID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit daily plan transaction: %w", err)
+	}
+
+	return s.GetDailyPlan(day)
+}
+
+// ClearDailyPlan removes every task from the plan for the day containing day
+func (s *Storage) ClearDailyPlan(day time.Time) error {
+	date := startOfDay(day, day.Location()).Format("2006-01-02")
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	items, err := getPlanItems(tx, date)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := clearCarryLinks(tx, date, item); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM daily_plan_items WHERE plan_date = ?`, date); err != nil {
+		return fmt.Errorf("failed to clear daily plan: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit daily plan transaction: %w", err)
+	}
+	return nil
+}
+
+// clearCarryLinks unlinks a task leaving the plan for date from the plans it was carried from and to,
+// so the task can be carried forward again and no plan points at an item that is gone
+func clearCarryLinks(q querier, date string, item planItem) error {
+	if item.carriedFrom != nil {
+		if _, err := q.Exec(`UPDATE daily_plan_items SET carried_to = NULL WHERE plan_date = ? AND task_id = ? AND carried_to = ?`,
+			*item.carriedFrom, item.taskID, date); err != nil {
+			return fmt.Errorf("failed to unlink task %d from %s: %w", item.taskID, *item.carriedFrom, err)
+		}
+	}
+	if item.carriedTo != nil {
+		if _, err := q.Exec(`UPDATE daily_plan_items SET carried_from = NULL WHERE plan_date = ? AND task_id = ? AND carried_from = ?`,
+			*item.carriedTo, item.taskID, date); err != nil {
+			return fmt.Errorf("failed to unlink task %d from %s: %w", item.taskID, *item.carriedTo, err)
+		}
+	}
+	return nil
+}
+
+// CarryForward appends the unfinished tasks of one day's plan to a later day's plan. Tasks already on the
+// target plan, carried forward before or not fitting the target's free slots are skipped. The source plan
+// keeps its tasks, marked with where they were carried to, so its review stays intact.
+func (s *Storage) CarryForward(from, to time.Time) (*types.CarryForwardResult, error) {
+	fromDate := startOfDay(from, from.Location()).Format("2006-01-02")
+	toDay := startOfDay(to, to.Location())
+	toDate := toDay.Format("2006-01-02")
+	if toDate <= fromDate {
+		return nil, fmt.Errorf("carry forward target must be after %s", fromDate)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	source, err := getPlanItems(tx, fromDate)
+	if err != nil {
+		return nil, err
+	}
+	target, err := getPlanItems(tx, toDate)
+	if err != nil {
+		return nil, err
+	}
+	onTarget := make(map[int]bool, len(target))
+	for _, item := range target {
+		onTarget[item.taskID] = true
+	}
+
+	result := &types.CarryForwardResult{From: fromDate, To: toDate, Carried: []int{}, Skipped: []types.CarryForwardSkip{}}
+	now := time.Now()
+	for _, item := range source {
+		var status types.TaskStatus
+		if err := tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, item.taskID).Scan(&status); err != nil {
+			return nil, fmt.Errorf("failed to get status of task %d: %w", item.taskID, err)
+		}
+		if status == types.TaskStatusCompleted || status == types.TaskStatusCancelled {
+			continue
+		}
+
+		skip := ""
+		switch {
+		case item.carriedTo != nil:
+			skip = fmt.Sprintf("already carried forward to %s", *item.carriedTo)
+		case onTarget[item.taskID]:
+			skip = fmt.Sprintf("already planned on %s", toDate)
+		case len(target) >= s.planSlots:
+			skip = fmt.Sprintf("no free slot on %s", toDate)
+		}
+		if skip != "" {
+			result.Skipped = append(result.Skipped, types.CarryForwardSkip{TaskID: item.taskID, Reason: skip})
+			continue
+		}
+
+		if _, err := tx.Exec(`INSERT INTO daily_plan_items (plan_date, task_id, position, carried_from, created_at) VALUES (?, ?, ?, ?, ?)`,
+			toDate, item.taskID, len(target)+1, fromDate, now); err != nil {
+			return nil, fmt.Errorf("failed to carry task %d forward: %w", item.taskID, err)
+		}
+		if _, err := tx.Exec(`UPDATE daily_plan_items SET carried_to = ? WHERE plan_date = ? AND task_id = ?`,
+			toDate, fromDate, item.taskID); err != nil {
+			return nil, fmt.Errorf("failed to mark task %d as carried forward: %w", item.taskID, err)
+		}
+		target = append(target, planItem{taskID: item.taskID, position: len(target) + 1})
+		onTarget[item.taskID] = true
+		result.Carried = append(result.Carried, item.taskID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit carry forward transaction: %w", err)
+	}
+
+	plan, err := s.GetDailyPlan(toDay)
+	if err != nil {
+		return nil, err
+	}
+	result.Plan = *plan
+
+	return result, nil
+}
+
+// checkPlannable checks that a task exists and is still open, so it may be added to a plan
+func checkPlannable(q querier, taskID int) error {
+	var status types.TaskStatus
+	err := q.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task %d does not exist", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check task %d: %w", taskID, err)
+	}
+	if status == types.TaskStatusCompleted || status == types.TaskStatusCancelled {
+		return fmt.Errorf("task %d is %s and cannot be planned", taskID, status)
+	}
+	return nil
+}
+
+// getPlanItems returns the stored entries of a day's plan in order
+func getPlanItems(q querier, date string) ([]planItem, error) {
+	rows, err := q.Query(`SELECT task_id, position, carried_from, carried_to
+			  FROM daily_plan_items
+			  WHERE plan_date = ?
+			  ORDER BY position ASC, id ASC`, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily plan: %w", err)
+	}
+	defer rows.Close()
+
+	var items []planItem
+	for rows.Next() {
+		var item planItem
+		if err := rows.Scan(&item.taskID, &item.position, &item.carriedFrom, &item.carriedTo); err != nil {
+			return nil, fmt.Errorf("failed to scan daily plan item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading daily plan rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// trackedTask names a task that time was tracked on
+type trackedTask struct {
+	title     string
+	projectID int
+}
+
+// trackedByTask returns the seconds tracked per task within [from, until), counting running entries
+// up to now and leaving out paused time, along with the tracked tasks' titles and projects
+func (s *Storage) trackedByTask(from, until, now time.Time) (map[int]int, map[int]trackedTask, error) {
+	entries, err := s.getTimelineEntries(from, until)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracked := make(map[int]int)
+	tasks := make(map[int]trackedTask)
+	for _, te := range entries {
+		start, end, ok := te.span(from, until, now)
+		if !ok {
+			continue
+		}
+		tracked[te.entry.TaskID] += te.trackedSeconds(start, end)
+		tasks[te.entry.TaskID] = trackedTask{title: te.taskTitle, projectID: te.projectID}
+	}
+
+	return tracked, tasks, nil
+}
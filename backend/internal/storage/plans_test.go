@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestDailyPlanReview(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	write := createTestTask(t, s, project.ID)
+	review := createTestTask(t, s, project.ID)
+	other := createTestTask(t, s, project.ID)
+
+	day := startOfDay(time.Now().AddDate(0, 0, -2), time.UTC)
+	if _, err := s.SetDailyPlan(day, []int{review.ID, write.ID}); err != nil {
+		t.Fatalf("Failed to set daily plan: %v", err)
+	}
+
+	createStoppedEntry(t, s, write.ID, day.Add(9*time.Hour), time.Hour)
+	createStoppedEntry(t, s, other.ID, day.Add(11*time.Hour), 30*time.Minute)
+	// Time tracked the day before does not count
+	createStoppedEntry(t, s, other.ID, day.Add(-2*time.Hour), time.Hour)
+
+	// The review was completed during the day
+	if _, err := s.UpdateTaskStatus(review.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE tasks SET completed_at = ? WHERE id = ?`, day.Add(15*time.Hour), review.ID); err != nil {
+		t.Fatalf("Failed to backdate completion: %v", err)
+	}
+
+	plan, err := s.GetDailyPlan(day)
+	if err != nil {
+		t.Fatalf("Failed to get daily plan: %v", err)
+	}
+
+	if plan.SlotLimit != defaultDailyPlanSlots || len(plan.Items) != 2 {
+		t.Fatalf("Expected 2 items with the default slot limit, got %+v", plan)
+	}
+	if plan.Items[0].Task.ID != review.ID || plan.Items[0].Position != 1 || !plan.Items[0].Completed {
+		t.Errorf("Expected the completed review first, got %+v", plan.Items[0])
+	}
+	if plan.Items[1].Task.ID != write.ID || plan.Items[1].Tracked != 3600 || plan.Items[1].Completed {
+		t.Errorf("Expected an hour tracked on the open task, got %+v", plan.Items[1])
+	}
+
+	r := plan.Review
+	if r.Planned != 2 || r.Completed != 1 || r.CompletionRate != 0.5 || r.TrackedPlanned != 3600 || r.TrackedOther != 1800 {
+		t.Errorf("Unexpected review %+v", r)
+	}
+	if len(r.Unplanned) != 1 || r.Unplanned[0].TaskID != other.ID || r.Unplanned[0].Tracked != 1800 {
+		t.Errorf("Expected the other task as unplanned work, got %+v", r.Unplanned)
+	}
+}
+
+func TestSetDailyPlanLimits(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	var ids []int
+	for i := 0; i < 3; i++ {
+		ids = append(ids, createTestTask(t, s, project.ID).ID)
+	}
+	day := startOfDay(time.Now(), time.UTC)
+
+	s.SetDailyPlanSlots(2)
+	if _, err := s.SetDailyPlan(day, ids); err == nil || !strings.Contains(err.Error(), "at most 2") {
+		t.Errorf("Expected the slot limit to be enforced, got %v", err)
+	}
+	if _, err := s.SetDailyPlan(day, []int{ids[0], ids[0]}); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("Expected duplicate tasks to be rejected, got %v", err)
+	}
+	if _, err := s.SetDailyPlan(day, []int{9999}); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected an unknown task to be rejected, got %v", err)
+	}
+
+	// Completed tasks cannot be added, but may stay on the plan
+	if _, err := s.UpdateTaskStatus(ids[2], types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if _, err := s.SetDailyPlan(day, []int{ids[2]}); err == nil || !strings.Contains(err.Error(), "cannot be planned") {
+		t.Errorf("Expected a completed task to be rejected, got %v", err)
+	}
+	if _, err := s.SetDailyPlan(day, []int{ids[0], ids[1]}); err != nil {
+		t.Fatalf("Failed to set daily plan: %v", err)
+	}
+	if _, err := s.UpdateTaskStatus(ids[1], types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	plan, err := s.SetDailyPlan(day, []int{ids[1], ids[0]})
+	if err != nil {
+		t.Fatalf("Expected a completed task to be reordered, got %v", err)
+	}
+	if plan.Items[0].Task.ID != ids[1] || plan.Items[1].Task.ID != ids[0] {
+		t.Errorf("Expected the plan to be reordered, got %+v", plan.Items)
+	}
+
+	if err := s.ClearDailyPlan(day); err != nil {
+		t.Fatalf("Failed to clear daily plan: %v", err)
+	}
+	plan, err = s.GetDailyPlan(day)
+	if err != nil || len(plan.Items) != 0 {
+		t.Errorf("Expected an empty plan, got %+v, %v", plan, err)
+	}
+}
+
+func TestCarryForward(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	done := createTestTask(t, s, project.ID)
+	open := createTestTask(t, s, project.ID)
+	third := createTestTask(t, s, project.ID)
+	planned := createTestTask(t, s, project.ID)
+	extra := createTestTask(t, s, project.ID)
+
+	today := startOfDay(time.Now(), time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+	s.SetDailyPlanSlots(2)
+
+	if _, err := s.SetDailyPlan(today, []int{done.ID, open.ID}); err != nil {
+		t.Fatalf("Failed to set today's plan: %v", err)
+	}
+	if _, err := s.SetDailyPlan(tomorrow, []int{planned.ID}); err != nil {
+		t.Fatalf("Failed to set tomorrow's plan: %v", err)
+	}
+	if _, err := s.UpdateTaskStatus(done.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	result, err := s.CarryForward(today, tomorrow)
+	if err != nil {
+		t.Fatalf("Failed to carry forward: %v", err)
+	}
+	if len(result.Carried) != 1 || result.Carried[0] != open.ID || len(result.Skipped) != 0 {
+		t.Fatalf("Expected only the open task to be carried, got %+v", result)
+	}
+	if len(result.Plan.Items) != 2 || result.Plan.Items[1].Task.ID != open.ID {
+		t.Fatalf("Expected the open task appended to tomorrow's plan, got %+v", result.Plan.Items)
+	}
+	if from := result.Plan.Items[1].CarriedFrom; from == nil || *from != today.Format("2006-01-02") {
+		t.Errorf("Expected the carried task to record its source, got %v", from)
+	}
+
+	plan, err := s.GetDailyPlan(today)
+	if err != nil {
+		t.Fatalf("Failed to get today's plan: %v", err)
+	}
+	if len(plan.Items) != 2 || plan.Items[1].CarriedTo == nil {
+		t.Errorf("Expected today's plan to keep the carried task, got %+v", plan.Items)
+	}
+
+	// Carrying again skips the carried task, and a full target skips the rest
+	if _, err := s.SetDailyPlan(today, []int{done.ID, open.ID}); err != nil {
+		t.Fatalf("Failed to reset today's plan: %v", err)
+	}
+	s.SetDailyPlanSlots(3)
+	if _, err := s.SetDailyPlan(today, []int{done.ID, open.ID, third.ID}); err != nil {
+		t.Fatalf("Failed to extend today's plan: %v", err)
+	}
+	if _, err := s.SetDailyPlan(tomorrow, []int{planned.ID, open.ID, extra.ID}); err != nil {
+		t.Fatalf("Failed to fill tomorrow's plan: %v", err)
+	}
+	result, err = s.CarryForward(today, tomorrow)
+	if err != nil {
+		t.Fatalf("Failed to carry forward again: %v", err)
+	}
+	if len(result.Carried) != 0 || len(result.Skipped) != 2 {
+		t.Fatalf("Expected both open tasks to be skipped, got %+v", result)
+	}
+	if !strings.Contains(result.Skipped[0].Reason, "already carried") || !strings.Contains(result.Skipped[1].Reason, "no free slot") {
+		t.Errorf("Unexpected skip reasons %+v", result.Skipped)
+	}
+
+	// Dropping a carried task from the target plan unlinks its source, so it can be carried again
+	if _, err := s.SetDailyPlan(tomorrow, []int{planned.ID, extra.ID}); err != nil {
+		t.Fatalf("Failed to drop the carried task: %v", err)
+	}
+	plan, err = s.GetDailyPlan(today)
+	if err != nil {
+		t.Fatalf("Failed to get today's plan: %v", err)
+	}
+	if plan.Items[1].Task.ID != open.ID || plan.Items[1].CarriedTo != nil {
+		t.Errorf("Expected the dropped task to lose its carry link, got %+v", plan.Items[1])
+	}
+	result, err = s.CarryForward(today, tomorrow)
+	if err != nil {
+		t.Fatalf("Failed to carry forward again: %v", err)
+	}
+	if len(result.Carried) != 1 || result.Carried[0] != open.ID {
+		t.Errorf("Expected the dropped task to be carried again, got %+v", result)
+	}
+
+	if _, err := s.CarryForward(today, today); err == nil {
+		t.Error("Expected carrying to the same day to fail")
+	}
+}
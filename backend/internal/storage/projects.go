@@ -63,19 +63,48 @@ func (s *Storage) GetProject(id int) (*types.Project, error) {
 
 // GetAllProjects retrieves all projects from the database
 func (s *Storage) GetAllProjects() ([]types.Project, error) {
-	query := `SELECT id, name, description, color, icon, created_at, updated_at 
-			  FROM projects 
-			  ORDER BY created_at DESC`
+	page, err := s.ListProjects(types.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
 
-	rows, err := s.db.Query(query)
+// ListProjects retrieves one page of projects, newest first
+func (s *Storage) ListProjects(page types.PageRequest) (types.Page[types.Project], error) {
+	if err := validatePageRequest(page); err != nil {
+		return types.Page[types.Project]{}, err
+	}
+
+	cursor, err := decodeCursor(page.Cursor, 1)
+	if err != nil {
+		return types.Page[types.Project]{}, err
+	}
+
+	query := `SELECT id, name, description, color, icon, created_at, updated_at, CAST(created_at AS TEXT) 
+			  FROM projects`
+	var args []interface{}
+	if cursor != nil {
+		query += ` WHERE (created_at < ? OR (created_at = ? AND id < ?))`
+		args = append(args, cursor.Sort[0], cursor.Sort[0], cursor.ID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if page.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, page.Limit+1) // One extra row tells us whether another page exists
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query projects: %w", err)
+		return types.Page[types.Project]{}, fmt.Errorf("failed to query projects: %w", err)
 	}
 	defer rows.Close()
 
-	var projects []types.Project
+	projects := []types.Project{}
+	var cursors []pageCursor
 	for rows.Next() {
 		var project types.Project
+		var createdAtKey string
 		err := rows.Scan(
 			&project.ID,
 			&project.Name,
@@ -84,18 +113,35 @@ func (s *Storage) GetAllProjects() ([]types.Project, error) {
 			&project.Icon,
 			&project.CreatedAt,
 			&project.UpdatedAt,
+			&createdAtKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan project: %w", err)
+			return types.Page[types.Project]{}, fmt.Errorf("failed to scan project: %w", err)
 		}
 		projects = append(projects, project)
+		cursors = append(cursors, pageCursor{Sort: []string{createdAtKey}, ID: project.ID})
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading project rows: %w", err)
+		return types.Page[types.Project]{}, fmt.Errorf("error reading project rows: %w", err)
 	}
 
-	return projects, nil
+	result := finishPage(projects, cursors, page.Limit)
+	if page.IncludeTotal {
+		result.Total, err = s.countRows(`SELECT COUNT(*) FROM projects`)
+		if err != nil {
+			return types.Page[types.Project]{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// IterateProjects calls fn for every project, newest first, without loading them all into memory
+func (s *Storage) IterateProjects(fn func(types.Project) error) error {
+	return iteratePages(func(cursor string) (types.Page[types.Project], error) {
+		return s.ListProjects(types.PageRequest{Limit: iterateBatchSize, Cursor: cursor})
+	}, fn)
 }
 
 // UpdateProject updates an existing project
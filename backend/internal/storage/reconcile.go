@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// danglingEntry is a running time entry together with its last recorded activity
+type danglingEntry struct {
+	entry           types.TimeEntry
+	lastHeartbeatAt *time.Time
+}
+
+// ReconcileDanglingTimeEntries closes or flags time entries left running by a crash.
+// An entry is dangling when its last heartbeat, or its start when it never sent one,
+// is older than the threshold. Entries that already await review are left alone.
+func (s *Storage) ReconcileDanglingTimeEntries(opts types.ReconcileOptions, now time.Time) ([]types.TimeEntryReview, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at, te.last_heartbeat_at
+			  FROM time_entries te
+			  LEFT JOIN time_entry_reviews r ON r.time_entry_id = te.id AND r.resolved_at IS NULL
+			  WHERE te.end_time IS NULL AND r.time_entry_id IS NULL
+			  ORDER BY te.start_time ASC`
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query running time entries: %w", err)
+	}
+
+	var candidates []danglingEntry
+	for rows.Next() {
+		var candidate danglingEntry
+		err := rows.Scan(
+			&candidate.entry.ID,
+			&candidate.entry.TaskID,
+			&candidate.entry.StartTime,
+			&candidate.entry.EndTime,
+			&candidate.entry.Duration,
+			&candidate.entry.Description,
+			&candidate.entry.CreatedAt,
+			&candidate.lastHeartbeatAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan running time entry: %w", err)
+		}
+
+		lastActivity := candidate.entry.StartTime
+		if candidate.lastHeartbeatAt != nil {
+			lastActivity = *candidate.lastHeartbeatAt
+		}
+		if now.Sub(lastActivity) > opts.Threshold {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error reading running time entry rows: %w", err)
+	}
+	rows.Close()
+
+	reviews := []types.TimeEntryReview{}
+	for _, candidate := range candidates {
+		entry := &candidate.entry
+		action := types.ReviewActionFlagged
+
+		switch opts.Policy {
+		case types.DanglingPolicyHeartbeat:
+			// Without a heartbeat there is no evidence of when work stopped
+			if candidate.lastHeartbeatAt != nil {
+				entry, err = stopTimeEntry(tx, entry, entry.Description, *candidate.lastHeartbeatAt)
+				action = types.ReviewActionClosedAtHeartbeat
+			}
+		case types.DanglingPolicyCap:
+			end := entry.StartTime.Add(opts.Cap)
+			if end.After(now) {
+				end = now
+			}
+			entry, err = stopTimeEntry(tx, entry, entry.Description, end)
+			action = types.ReviewActionClosedAtCap
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		insertQuery := `INSERT OR REPLACE INTO time_entry_reviews (time_entry_id, action, detected_at, resolved_at)
+						VALUES (?, ?, ?, NULL)`
+		if _, err := tx.Exec(insertQuery, entry.ID, action, now); err != nil {
+			return nil, fmt.Errorf("failed to record time entry review: %w", err)
+		}
+
+		reviews = append(reviews, types.TimeEntryReview{
+			TimeEntry:       *entry,
+			Action:          action,
+			LastHeartbeatAt: candidate.lastHeartbeatAt,
+			DetectedAt:      now,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reconcile transaction: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// GetTimeEntryReviews returns the reconciled time entries awaiting review, oldest first
+func (s *Storage) GetTimeEntryReviews() ([]types.TimeEntryReview, error) {
+	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at,
+			         te.last_heartbeat_at, r.action, r.detected_at
+			  FROM time_entry_reviews r
+			  JOIN time_entries te ON te.id = r.time_entry_id
+			  WHERE r.resolved_at IS NULL
+			  ORDER BY te.start_time ASC, te.id ASC`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time entry reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []types.TimeEntryReview{}
+	for rows.Next() {
+		var review types.TimeEntryReview
+		err := rows.Scan(
+			&review.TimeEntry.ID,
+			&review.TimeEntry.TaskID,
+			&review.TimeEntry.StartTime,
+			&review.TimeEntry.EndTime,
+			&review.TimeEntry.Duration,
+			&review.TimeEntry.Description,
+			&review.TimeEntry.CreatedAt,
+			&review.LastHeartbeatAt,
+			&review.Action,
+			&review.DetectedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan time entry review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading time entry review rows: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// ResolveTimeEntryReview marks a time entry's review as handled, keeping the entry as it is
+func (s *Storage) ResolveTimeEntryReview(timeEntryID int) error {
+	resolved, err := resolveTimeEntryReview(s.db, timeEntryID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return fmt.Errorf("no open review found for time entry %d", timeEntryID)
+	}
+	return nil
+}
+
+// hasOpenTimeEntryReview reports whether a time entry awaits review
+func hasOpenTimeEntryReview(q querier, timeEntryID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM time_entry_reviews WHERE time_entry_id = ? AND resolved_at IS NULL)`
+	if err := q.QueryRow(query, timeEntryID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check time entry review: %w", err)
+	}
+	return exists, nil
+}
+
+// resolveTimeEntryReview closes the open review of a time entry and reports whether one existed
+func resolveTimeEntryReview(q querier, timeEntryID int, now time.Time) (bool, error) {
+	query := `UPDATE time_entry_reviews SET resolved_at = ? WHERE time_entry_id = ? AND resolved_at IS NULL`
+	result, err := q.Exec(query, now, timeEntryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve time entry review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
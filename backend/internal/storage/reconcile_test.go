@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// createDanglingEntry creates a running time entry that started the given time ago
+func createDanglingEntry(t *testing.T, s *Storage, taskID int, age time.Duration) *types.TimeEntry {
+	entry, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: taskID, StartTime: time.Now().Add(-age)})
+	if err != nil {
+		t.Fatalf("Failed to create running entry: %v", err)
+	}
+	return entry
+}
+
+func TestReconcileDanglingTimeEntriesReview(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.SetGlobalTimer(false)
+
+	project := createTestProject(t, s)
+	stale := createDanglingEntry(t, s, createTestTask(t, s, project.ID).ID, 70*time.Hour)
+	fresh := createDanglingEntry(t, s, createTestTask(t, s, project.ID).ID, time.Hour)
+
+	opts := types.ReconcileOptions{Policy: types.DanglingPolicyReview, Threshold: 12 * time.Hour, Cap: 8 * time.Hour}
+	reviews, err := s.ReconcileDanglingTimeEntries(opts, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].TimeEntry.ID != stale.ID {
+		t.Fatalf("Expected only entry %d to be flagged, got %v", stale.ID, reviews)
+	}
+	if reviews[0].Action != types.ReviewActionFlagged || reviews[0].TimeEntry.EndTime != nil {
+		t.Errorf("Expected the entry to stay open and be flagged")
+	}
+
+	// Running reconciliation again does not flag the entry twice
+	reviews, err = s.ReconcileDanglingTimeEntries(opts, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(reviews) != 0 {
+		t.Errorf("Expected no new reviews, got %d", len(reviews))
+	}
+
+	pending, err := s.GetTimeEntryReviews()
+	if err != nil {
+		t.Fatalf("Failed to get reviews: %v", err)
+	}
+	if len(pending) != 1 || pending[0].TimeEntry.ID != stale.ID {
+		t.Fatalf("Expected one pending review for entry %d, got %v", stale.ID, pending)
+	}
+
+	// Correcting the entry settles the review
+	end := stale.StartTime.Add(2 * time.Hour)
+	_, err = s.UpdateTimeEntry(stale.ID, types.CreateTimeEntryRequest{TaskID: stale.TaskID, StartTime: stale.StartTime, EndTime: &end})
+	if err != nil {
+		t.Fatalf("Failed to update entry: %v", err)
+	}
+
+	pending, err = s.GetTimeEntryReviews()
+	if err != nil {
+		t.Fatalf("Failed to get reviews: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending reviews after correction, got %d", len(pending))
+	}
+
+	if active, err := s.GetActiveTimeEntry(fresh.TaskID); err != nil || active == nil {
+		t.Errorf("Expected the recent entry to keep running: %v", err)
+	}
+}
+
+func TestReconcileDanglingTimeEntriesHeartbeat(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.SetGlobalTimer(false)
+
+	project := createTestProject(t, s)
+	withHeartbeat := createDanglingEntry(t, s, createTestTask(t, s, project.ID).ID, 70*time.Hour)
+	withoutHeartbeat := createDanglingEntry(t, s, createTestTask(t, s, project.ID).ID, 70*time.Hour)
+
+	heartbeat := withHeartbeat.StartTime.Add(90 * time.Minute)
+	if _, err := s.db.Exec(`UPDATE time_entries SET last_heartbeat_at = ? WHERE id = ?`, heartbeat, withHeartbeat.ID); err != nil {
+		t.Fatalf("Failed to set heartbeat: %v", err)
+	}
+
+	opts := types.ReconcileOptions{Policy: types.DanglingPolicyHeartbeat, Threshold: 12 * time.Hour, Cap: 8 * time.Hour}
+	reviews, err := s.ReconcileDanglingTimeEntries(opts, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("Expected 2 reviews, got %d", len(reviews))
+	}
+
+	for _, review := range reviews {
+		switch review.TimeEntry.ID {
+		case withHeartbeat.ID:
+			if review.Action != types.ReviewActionClosedAtHeartbeat {
+				t.Errorf("Expected entry closed at heartbeat, got %s", review.Action)
+			}
+			if review.TimeEntry.Duration == nil || *review.TimeEntry.Duration != 90*60 {
+				t.Errorf("Expected a 90 minute duration, got %v", review.TimeEntry.Duration)
+			}
+		case withoutHeartbeat.ID:
+			// Without a heartbeat the entry is only flagged
+			if review.Action != types.ReviewActionFlagged || review.TimeEntry.EndTime != nil {
+				t.Errorf("Expected entry without heartbeat to be flagged, got %s", review.Action)
+			}
+		}
+	}
+}
+
+func TestReconcileDanglingTimeEntriesCap(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	entry := createDanglingEntry(t, s, task.ID, 70*time.Hour)
+
+	opts := types.ReconcileOptions{Policy: types.DanglingPolicyCap, Threshold: 12 * time.Hour, Cap: 8 * time.Hour}
+	reviews, err := s.ReconcileDanglingTimeEntries(opts, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].Action != types.ReviewActionClosedAtCap {
+		t.Fatalf("Expected entry to be closed at the cap, got %v", reviews)
+	}
+	if reviews[0].TimeEntry.Duration == nil || *reviews[0].TimeEntry.Duration != 8*60*60 {
+		t.Errorf("Expected an 8 hour duration, got %v", reviews[0].TimeEntry.Duration)
+	}
+
+	if err := s.ResolveTimeEntryReview(entry.ID); err != nil {
+		t.Fatalf("Failed to resolve review: %v", err)
+	}
+	err = s.ResolveTimeEntryReview(entry.ID)
+	if err == nil || !contains(err.Error(), "no open review") {
+		t.Errorf("Expected no open review error, got %v", err)
+	}
+}
+
+func TestFlaggedEntryKeepsRunningWhenAnotherTimerStarts(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.SetGlobalTimer(true)
+
+	project := createTestProject(t, s)
+	stale := createDanglingEntry(t, s, createTestTask(t, s, project.ID).ID, 70*time.Hour)
+
+	opts := types.ReconcileOptions{Policy: types.DanglingPolicyReview, Threshold: 12 * time.Hour, Cap: 8 * time.Hour}
+	if _, err := s.ReconcileDanglingTimeEntries(opts, time.Now()); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	// Starting another timer must not close the flagged entry with the whole 70 hour gap
+	started, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: createTestTask(t, s, project.ID).ID})
+	if err != nil {
+		t.Fatalf("Failed to start timer: %v", err)
+	}
+	if len(started.Stopped) != 0 {
+		t.Errorf("Expected no entries to be stopped, got %v", started.Stopped)
+	}
+
+	entry, err := s.GetTimeEntry(stale.ID)
+	if err != nil {
+		t.Fatalf("Failed to get flagged entry: %v", err)
+	}
+	if entry.EndTime != nil {
+		t.Errorf("Expected the flagged entry to stay open for review, got a %d second entry", entry.Duration)
+	}
+
+	pending, err := s.GetTimeEntryReviews()
+	if err != nil {
+		t.Fatalf("Failed to get reviews: %v", err)
+	}
+	if len(pending) != 1 || pending[0].TimeEntry.ID != stale.ID {
+		t.Errorf("Expected the review of entry %d to stay open, got %v", stale.ID, pending)
+	}
+}
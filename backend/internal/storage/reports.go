@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// reportTask holds the task fields needed to label and roll up report rows
+type reportTask struct {
+	parentID *int
+	title    string
+}
+
+// GetTimeReport returns the stopped time tracked in [From, To) grouped by the requested dimension.
+// Entries are clipped to the period and paused time is left out. Day and week groups split entries
+// at midnight in From's location, with weeks keyed by the date they start on. Billable entries are
+// rounded once with query.Rounding, as on invoices, and priced at their task's or project's hourly
+// rate, and an entry's billed time and amount are shared out over its pieces.
+func (s *Storage) GetTimeReport(query types.TimeReportQuery) (*types.TimeReport, error) {
+	if !query.To.After(query.From) {
+		return nil, fmt.Errorf("report end must be after its start")
+	}
+
+	switch query.GroupBy {
+	case types.ReportGroupByProject, types.ReportGroupByTask, types.ReportGroupByDay,
+		types.ReportGroupByWeek, types.ReportGroupByTag:
+	default:
+		return nil, fmt.Errorf("invalid report grouping %q", query.GroupBy)
+	}
+
+	loc := query.From.Location()
+
+	entries, err := s.getTimelineEntries(query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := getReportTasks(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := getBillingRates(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags map[int][]string
+	if query.GroupBy == types.ReportGroupByTag {
+		tags, err = getAllTaskTags(s.db, "1 = 1")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &types.TimeReport{
+		From:     query.From,
+		To:       query.To,
+		Timezone: loc.String(),
+		GroupBy:  query.GroupBy,
+		Rollup:   query.Rollup,
+		Rounding: query.Rounding,
+		Rows:     []types.TimeReportRow{},
+	}
+
+	rows := make(map[string]*types.TimeReportRow)
+	rowEntries := make(map[string]map[int]bool)
+
+	for _, te := range entries {
+		// Running entries are reported once they are stopped
+		if te.entry.EndTime == nil {
+			continue
+		}
+		if query.ProjectID != nil && te.projectID != *query.ProjectID {
+			continue
+		}
+
+		start, end, ok := te.span(query.From, query.To, *te.entry.EndTime)
+		if !ok {
+			continue
+		}
+
+		taskID := te.entry.TaskID
+		if query.Rollup {
+			taskID = rootTaskID(tasks, taskID)
+		}
+		projectID := te.projectID
+		hourlyRate := rates.rateFor(te.entry.TaskID, te.projectID)
+
+		// Day and week groups need each piece to fall within a single day
+		pieces := [][2]time.Time{{start, end}}
+		if query.GroupBy == types.ReportGroupByDay || query.GroupBy == types.ReportGroupByWeek {
+			pieces = splitAtMidnight(start, end, loc)
+		}
+
+		// Rounding each piece would bill an entry across midnight for two increments
+		tracked := te.trackedSeconds(start, end)
+		var entryBilled int
+		var entryAmount int64
+		if te.billable {
+			entryBilled = roundDuration(tracked, query.Rounding)
+			entryAmount = billedAmount(entryBilled, hourlyRate)
+		}
+
+		// Each piece gets its share of the tracked time, and the shares add up to the entry's totals
+		trackedSoFar, billedSoFar, amountSoFar := 0, 0, int64(0)
+		for i, piece := range pieces {
+			duration := te.trackedSeconds(piece[0], piece[1])
+
+			trackedSoFar += duration
+			billed, amount := entryBilled-billedSoFar, entryAmount-amountSoFar
+			if i < len(pieces)-1 && tracked > 0 {
+				billed = entryBilled*trackedSoFar/tracked - billedSoFar
+				amount = entryAmount*int64(trackedSoFar)/int64(tracked) - amountSoFar
+			}
+			billedSoFar += billed
+			amountSoFar += amount
+
+			var groups []types.TimeReportRow
+			switch query.GroupBy {
+			case types.ReportGroupByProject:
+				groups = append(groups, types.TimeReportRow{Key: strconv.Itoa(projectID), Label: te.projectName, ProjectID: &projectID})
+			case types.ReportGroupByTask:
+				groups = append(groups, types.TimeReportRow{Key: strconv.Itoa(taskID), Label: tasks[taskID].title, ProjectID: &projectID, TaskID: &taskID})
+			case types.ReportGroupByDay:
+				day := piece[0].In(loc).Format("2006-01-02")
+				groups = append(groups, types.TimeReportRow{Key: day, Label: day})
+			case types.ReportGroupByWeek:
+				key := StartOfWeek(piece[0], loc, query.WeekStart).Format("2006-01-02")
+				groups = append(groups, types.TimeReportRow{Key: key, Label: "Week of " + key})
+			case types.ReportGroupByTag:
+				for _, tag := range tags[taskID] {
+					groups = append(groups, types.TimeReportRow{Key: tag, Label: tag})
+				}
+				if len(groups) == 0 {
+					groups = append(groups, types.TimeReportRow{Key: "", Label: "Untagged"})
+				}
+			}
+
+			for _, group := range groups {
+				row, ok := rows[group.Key]
+				if !ok {
+					row = &types.TimeReportRow{Key: group.Key, Label: group.Label, ProjectID: group.ProjectID, TaskID: group.TaskID}
+					rows[group.Key] = row
+					rowEntries[group.Key] = make(map[int]bool)
+				}
+				row.Duration += duration
+				row.BillableDuration += billed
+				row.Amount += amount
+				rowEntries[group.Key][te.entry.ID] = true
+			}
+
+			report.TotalDuration += duration
+			report.TotalBillableDuration += billed
+			report.TotalAmount += amount
+		}
+
+		report.TotalEntries++
+	}
+
+	for key, row := range rows {
+		row.Entries = len(rowEntries[key])
+		report.Rows = append(report.Rows, *row)
+	}
+	sortReportRows(query.GroupBy, report.Rows)
+
+	return report, nil
+}
+
+// getReportTasks returns every task's parent and title keyed by task ID
+func getReportTasks(q querier) (map[int]reportTask, error) {
+	rows, err := q.Query(`SELECT id, parent_id, title FROM tasks`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make(map[int]reportTask)
+	for rows.Next() {
+		var id int
+		var task reportTask
+		if err := rows.Scan(&id, &task.parentID, &task.title); err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks[id] = task
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading task rows: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// rootTaskID follows parent links to the top-level task, stopping at cycles or missing parents
+func rootTaskID(tasks map[int]reportTask, taskID int) int {
+	visited := map[int]bool{taskID: true}
+	for {
+		task, ok := tasks[taskID]
+		if !ok || task.parentID == nil || visited[*task.parentID] {
+			return taskID
+		}
+		taskID = *task.parentID
+		visited[taskID] = true
+	}
+}
+
+// splitAtMidnight splits [start, end) into pieces that each fall within one day in loc
+func splitAtMidnight(start, end time.Time, loc *time.Location) [][2]time.Time {
+	var pieces [][2]time.Time
+	for start.Before(end) {
+		next := startOfDay(start, loc).AddDate(0, 0, 1)
+		if next.After(end) {
+			next = end
+		}
+		pieces = append(pieces, [2]time.Time{start, next})
+		start = next
+	}
+	return pieces
+}
+
+// sortReportRows orders date groups chronologically and all other groups by tracked time, most first
+func sortReportRows(groupBy types.ReportGroupBy, rows []types.TimeReportRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		if groupBy == types.ReportGroupByDay || groupBy == types.ReportGroupByWeek {
+			return rows[i].Key < rows[j].Key
+		}
+		if rows[i].Duration != rows[j].Duration {
+			return rows[i].Duration > rows[j].Duration
+		}
+		if rows[i].Label != rows[j].Label {
+			return rows[i].Label < rows[j].Label
+		}
+		return rows[i].Key < rows[j].Key
+	})
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// createStoppedEntry creates a stopped time entry on a task
+func createStoppedEntry(t *testing.T, s *Storage, taskID int, start time.Time, duration time.Duration) *types.TimeEntry {
+	end := start.Add(duration)
+	entry, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: taskID, StartTime: start, EndTime: &end})
+	if err != nil {
+		t.Fatalf("Failed to create time entry: %v", err)
+	}
+	return entry
+}
+
+func TestGetTimeReportGrouping(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	parent := createTestTask(t, s, project.ID)
+	child, err := s.CreateTask(types.CreateTaskRequest{ProjectID: project.ID, ParentID: &parent.ID, Title: "Subtask"})
+	if err != nil {
+		t.Fatalf("Failed to create subtask: %v", err)
+	}
+
+	// Yesterday 23:00 to today 01:00 crosses midnight
+	today := startOfDay(time.Now(), time.Local)
+	if time.Since(today) < 2*time.Hour {
+		today = today.AddDate(0, 0, -1)
+	}
+	createStoppedEntry(t, s, parent.ID, today.Add(-time.Hour), 2*time.Hour)
+	createStoppedEntry(t, s, child.ID, today.Add(time.Hour), 30*time.Minute)
+
+	if _, err := s.SetTaskTags(parent.ID, []string{"Client", "billable"}); err != nil {
+		t.Fatalf("Failed to set tags: %v", err)
+	}
+
+	query := types.TimeReportQuery{From: today.AddDate(0, 0, -1), To: today.AddDate(0, 0, 1), GroupBy: types.ReportGroupByTask}
+	report, err := s.GetTimeReport(query)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if report.TotalEntries != 2 || report.TotalDuration != 150*60 {
+		t.Fatalf("Expected 2 entries over 150 minutes, got %d entries over %d seconds", report.TotalEntries, report.TotalDuration)
+	}
+	if len(report.Rows) != 2 || *report.Rows[0].TaskID != parent.ID {
+		t.Errorf("Expected the parent task first among 2 rows, got %v", report.Rows)
+	}
+
+	// Rolling up attributes the subtask's time to its parent
+	query.Rollup = true
+	report, err = s.GetTimeReport(query)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if len(report.Rows) != 1 || report.Rows[0].Duration != 150*60 || report.Rows[0].Entries != 2 {
+		t.Errorf("Expected a single rolled up row of 150 minutes, got %v", report.Rows)
+	}
+
+	// Day grouping splits the entry at midnight
+	query = types.TimeReportQuery{From: today.AddDate(0, 0, -1), To: today.AddDate(0, 0, 1), GroupBy: types.ReportGroupByDay}
+	report, err = s.GetTimeReport(query)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if len(report.Rows) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(report.Rows))
+	}
+	if report.Rows[0].Key != today.AddDate(0, 0, -1).Format("2006-01-02") || report.Rows[0].Duration != 60*60 {
+		t.Errorf("Expected 60 minutes on the first day, got %d seconds on %s", report.Rows[0].Duration, report.Rows[0].Key)
+	}
+	if report.Rows[1].Duration != 90*60 || report.Rows[1].Entries != 2 {
+		t.Errorf("Expected 90 minutes over 2 entries on the second day, got %d seconds", report.Rows[1].Duration)
+	}
+
+	// The period clips entries at its boundaries
+	query.From = today
+	report, err = s.GetTimeReport(query)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if report.TotalDuration != 90*60 {
+		t.Errorf("Expected 90 clipped minutes, got %d seconds", report.TotalDuration)
+	}
+
+	// Tag grouping counts untagged tasks separately
+	query = types.TimeReportQuery{From: today.AddDate(0, 0, -1), To: today.AddDate(0, 0, 1), GroupBy: types.ReportGroupByTag}
+	report, err = s.GetTimeReport(query)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	byKey := make(map[string]types.TimeReportRow)
+	for _, row := range report.Rows {
+		byKey[row.Key] = row
+	}
+	if byKey["client"].Duration != 120*60 || byKey["billable"].Duration != 120*60 {
+		t.Errorf("Expected both tags to carry 120 minutes, got %v", report.Rows)
+	}
+	if byKey[""].Duration != 30*60 {
+		t.Errorf("Expected 30 untagged minutes, got %d seconds", byKey[""].Duration)
+	}
+
+	// Invalid grouping is rejected
+	query.GroupBy = "color"
+	if _, err := s.GetTimeReport(query); err == nil || !contains(err.Error(), "invalid report grouping") {
+		t.Errorf("Expected invalid grouping error, got %v", err)
+	}
+}
+
+func TestGetTimeReportRoundsOncePerEntry(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	rate := int64(6000)
+	if _, err := s.SetProjectRate(project.ID, &rate); err != nil {
+		t.Fatalf("Failed to set rate: %v", err)
+	}
+
+	// 7 minutes across midnight bill one 15 minute increment, not one for each day
+	today := startOfDay(time.Now(), time.Local)
+	if time.Since(today) < time.Hour {
+		today = today.AddDate(0, 0, -1)
+	}
+	createStoppedEntry(t, s, task.ID, today.Add(-3*time.Minute), 7*time.Minute)
+
+	rounding := types.RoundingRule{IncrementMinutes: 15, Mode: types.RoundingModeUp}
+	invoice, err := s.CreateInvoice(types.CreateInvoiceRequest{
+		ProjectID:   project.ID,
+		PeriodStart: today.AddDate(0, 0, -1),
+		PeriodEnd:   today.AddDate(0, 0, 1),
+		Rounding:    rounding,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create invoice: %v", err)
+	}
+
+	for _, groupBy := range []types.ReportGroupBy{types.ReportGroupByProject, types.ReportGroupByDay, types.ReportGroupByWeek} {
+		report, err := s.GetTimeReport(types.TimeReportQuery{
+			From:     today.AddDate(0, 0, -1),
+			To:       today.AddDate(0, 0, 1),
+			GroupBy:  groupBy,
+			Rounding: rounding,
+		})
+		if err != nil {
+			t.Fatalf("Failed to get report: %v", err)
+		}
+		if report.TotalBillableDuration != invoice.TotalDuration || report.TotalAmount != invoice.TotalAmount {
+			t.Errorf("Expected %s grouping to bill %d seconds for %d like the invoice, got %d seconds for %d",
+				groupBy, invoice.TotalDuration, invoice.TotalAmount, report.TotalBillableDuration, report.TotalAmount)
+		}
+
+		billed := 0
+		for _, row := range report.Rows {
+			billed += row.BillableDuration
+		}
+		if billed != report.TotalBillableDuration {
+			t.Errorf("Expected %s rows to add up to %d billed seconds, got %d", groupBy, report.TotalBillableDuration, billed)
+		}
+	}
+	if invoice.TotalDuration != 15*60 {
+		t.Errorf("Expected the invoice to bill 15 minutes, got %d seconds", invoice.TotalDuration)
+	}
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// defaultTimeEntryRules are the rules applied until SetTimeEntryRules is called
+var defaultTimeEntryRules = types.TimeEntryRules{
+	MaxBackdateDays:    30,
+	MaxDurationMinutes: 24 * 60,
+	MinDurationSeconds: 60,
+	ClockSkewSeconds:   5 * 60,
+}
+
+// TimeEntryRuleError reports which time entry rule a request broke
+type TimeEntryRuleError struct {
+	Code    types.TimeEntryRuleCode
+	Message string
+}
+
+// Error returns the human readable description of the broken rule
+func (e *TimeEntryRuleError) Error() string {
+	return e.Message
+}
+
+// newRuleError creates a TimeEntryRuleError with a formatted message
+func newRuleError(code types.TimeEntryRuleCode, format string, args ...interface{}) *TimeEntryRuleError {
+	return &TimeEntryRuleError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// GetProjectTimeEntryRules returns a project's rule overrides and the rules that apply to its tasks
+func (s *Storage) GetProjectTimeEntryRules(projectID int) (*types.ProjectTimeEntryRules, error) {
+	projectExists, err := s.projectExists(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+	}
+	if !projectExists {
+		return nil, fmt.Errorf("project with id %d does not exist", projectID)
+	}
+
+	override, err := getRulesOverride(s.db, `SELECT max_backdate_days, max_duration_minutes, min_duration_seconds, clock_skew_seconds
+			  FROM project_time_entry_rules WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ProjectTimeEntryRules{
+		ProjectID: projectID,
+		Overrides: override,
+		Effective: applyRulesOverride(s.rules, override),
+	}, nil
+}
+
+// SetProjectTimeEntryRules replaces a project's rule overrides. Clearing every field removes the overrides.
+func (s *Storage) SetProjectTimeEntryRules(projectID int, override types.TimeEntryRulesOverride) (*types.ProjectTimeEntryRules, error) {
+	projectExists, err := s.projectExists(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+	}
+	if !projectExists {
+		return nil, fmt.Errorf("project with id %d does not exist", projectID)
+	}
+
+	if override == (types.TimeEntryRulesOverride{}) {
+		_, err = s.db.Exec(`DELETE FROM project_time_entry_rules WHERE project_id = ?`, projectID)
+	} else {
+		_, err = s.db.Exec(`INSERT INTO project_time_entry_rules (project_id, max_backdate_days, max_duration_minutes, min_duration_seconds, clock_skew_seconds)
+				  VALUES (?, ?, ?, ?, ?)
+				  ON CONFLICT(project_id) DO UPDATE SET
+				      max_backdate_days = excluded.max_backdate_days,
+				      max_duration_minutes = excluded.max_duration_minutes,
+				      min_duration_seconds = excluded.min_duration_seconds,
+				      clock_skew_seconds = excluded.clock_skew_seconds`,
+			projectID, override.MaxBackdateDays, override.MaxDurationMinutes, override.MinDurationSeconds, override.ClockSkewSeconds)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set time entry rules: %w", err)
+	}
+
+	return &types.ProjectTimeEntryRules{
+		ProjectID: projectID,
+		Overrides: override,
+		Effective: applyRulesOverride(s.rules, override),
+	}, nil
+}
+
+// timeEntryRulesForTask returns the rules that apply to time entries on a task
+func (s *Storage) timeEntryRulesForTask(q querier, taskID int) (types.TimeEntryRules, error) {
+	override, err := getRulesOverride(q, `SELECT r.max_backdate_days, r.max_duration_minutes, r.min_duration_seconds, r.clock_skew_seconds
+			  FROM project_time_entry_rules r
+			  JOIN tasks t ON t.project_id = r.project_id
+			  WHERE t.id = ?`, taskID)
+	if err != nil {
+		return types.TimeEntryRules{}, err
+	}
+	return applyRulesOverride(s.rules, override), nil
+}
+
+// timeEntryRulesForProject returns the rules that apply to time entries on a project's tasks
+func (s *Storage) timeEntryRulesForProject(q querier, projectID int) (types.TimeEntryRules, error) {
+	override, err := getRulesOverride(q, `SELECT max_backdate_days, max_duration_minutes, min_duration_seconds, clock_skew_seconds
+			  FROM project_time_entry_rules WHERE project_id = ?`, projectID)
+	if err != nil {
+		return types.TimeEntryRules{}, err
+	}
+	return applyRulesOverride(s.rules, override), nil
+}
+
+// getRulesOverride loads the rule overrides selected by query, returning no overrides when there is no row
+func getRulesOverride(q querier, query string, id int) (types.TimeEntryRulesOverride, error) {
+	var override types.TimeEntryRulesOverride
+
+	rows, err := q.Query(query, id)
+	if err != nil {
+		return override, fmt.Errorf("failed to query time entry rules: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err := rows.Scan(&override.MaxBackdateDays, &override.MaxDurationMinutes, &override.MinDurationSeconds, &override.ClockSkewSeconds)
+		if err != nil {
+			return override, fmt.Errorf("failed to scan time entry rules: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return override, fmt.Errorf("error reading time entry rule rows: %w", err)
+	}
+
+	return override, nil
+}
+
+// applyRulesOverride replaces the default rules with every override that is set
+func applyRulesOverride(rules types.TimeEntryRules, override types.TimeEntryRulesOverride) types.TimeEntryRules {
+	if override.MaxBackdateDays != nil {
+		rules.MaxBackdateDays = *override.MaxBackdateDays
+	}
+	if override.MaxDurationMinutes != nil {
+		rules.MaxDurationMinutes = *override.MaxDurationMinutes
+	}
+	if override.MinDurationSeconds != nil {
+		rules.MinDurationSeconds = *override.MinDurationSeconds
+	}
+	if override.ClockSkewSeconds != nil {
+		rules.ClockSkewSeconds = *override.ClockSkewSeconds
+	}
+	return rules
+}
+
+// checkTimeEntryRules validates an entry's times against the rules. An override skips every
+// configurable rule but still rejects an end before the start.
+func checkTimeEntryRules(rules types.TimeEntryRules, startTime time.Time, endTime *time.Time, now time.Time, override bool) error {
+	if endTime != nil && endTime.Before(startTime) {
+		return newRuleError(types.TimeEntryRuleEndBeforeStart, "end time cannot be before start time")
+	}
+	if override {
+		return nil
+	}
+
+	// Allow a little clock skew between client and server
+	latest := now.Add(time.Duration(rules.ClockSkewSeconds) * time.Second)
+	if startTime.After(latest) {
+		return newRuleError(types.TimeEntryRuleStartInFuture, "start time cannot be in the future")
+	}
+
+	if rules.MaxBackdateDays > 0 && startTime.Before(now.AddDate(0, 0, -rules.MaxBackdateDays)) {
+		return newRuleError(types.TimeEntryRuleBackdateLimit, "start time cannot be more than %d days in the past", rules.MaxBackdateDays)
+	}
+
+	if endTime == nil {
+		return nil
+	}
+
+	duration := endTime.Sub(startTime)
+	maxDuration := time.Duration(rules.MaxDurationMinutes) * time.Minute
+	if maxDuration > 0 && duration > maxDuration {
+		return newRuleError(types.TimeEntryRuleMaxDuration, "time entry duration cannot exceed %s", describeRuleDuration(maxDuration))
+	}
+
+	minDuration := time.Duration(rules.MinDurationSeconds) * time.Second
+	if duration < minDuration {
+		return newRuleError(types.TimeEntryRuleMinDuration, "time entry duration must be at least %s", describeRuleDuration(minDuration))
+	}
+
+	if endTime.After(latest) {
+		return newRuleError(types.TimeEntryRuleEndInFuture, "end time cannot be in the future")
+	}
+
+	return nil
+}
+
+// describeRuleDuration formats a rule limit in the largest whole unit, e.g. "24 hours" or "1 minute"
+func describeRuleDuration(d time.Duration) string {
+	value, unit := int(d/time.Second), "second"
+	if d%time.Hour == 0 {
+		value, unit = int(d/time.Hour), "hour"
+	} else if d%time.Minute == 0 {
+		value, unit = int(d/time.Minute), "minute"
+	}
+	if value != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestCheckTimeEntryRules(t *testing.T) {
+	now := time.Now()
+	end := func(d time.Duration) *time.Time {
+		e := now.Add(-time.Hour).Add(d)
+		return &e
+	}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      *time.Time
+		override bool
+		code     types.TimeEntryRuleCode
+	}{
+		{name: "valid entry", start: now.Add(-time.Hour), end: end(30 * time.Minute)},
+		{name: "start in the future", start: now.Add(10 * time.Minute), code: types.TimeEntryRuleStartInFuture},
+		{name: "start within clock skew", start: now.Add(time.Minute)},
+		{name: "too far in the past", start: now.AddDate(0, 0, -31), code: types.TimeEntryRuleBackdateLimit},
+		{name: "end before start", start: now.Add(-time.Hour), end: end(-time.Minute), code: types.TimeEntryRuleEndBeforeStart},
+		{name: "too short", start: now.Add(-time.Hour), end: end(20 * time.Second), code: types.TimeEntryRuleMinDuration},
+		{name: "too long", start: now.Add(-26 * time.Hour), end: end(25 * time.Hour), code: types.TimeEntryRuleMaxDuration},
+		{name: "end in the future", start: now.Add(-time.Hour), end: end(2 * time.Hour), code: types.TimeEntryRuleEndInFuture},
+		{name: "override skips limits", start: now.AddDate(0, -3, 0), end: end(20 * time.Second), override: true},
+		{name: "override keeps end before start", start: now.Add(-time.Hour), end: end(-time.Minute), override: true, code: types.TimeEntryRuleEndBeforeStart},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTimeEntryRules(defaultTimeEntryRules, tt.start, tt.end, now, tt.override)
+			if tt.code == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var ruleErr *TimeEntryRuleError
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("Expected a rule error with code %s, got %v", tt.code, err)
+			}
+			if ruleErr.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, ruleErr.Code)
+			}
+		})
+	}
+
+	if msg := describeRuleDuration(24 * time.Hour); msg != "24 hours" {
+		t.Errorf("Expected 24 hours, got %s", msg)
+	}
+	if msg := describeRuleDuration(90 * time.Second); msg != "90 seconds" {
+		t.Errorf("Expected 90 seconds, got %s", msg)
+	}
+}
+
+func TestProjectTimeEntryRules(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	rules, err := s.GetProjectTimeEntryRules(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get rules: %v", err)
+	}
+	if rules.Effective != defaultTimeEntryRules {
+		t.Errorf("Expected the default rules without overrides, got %+v", rules.Effective)
+	}
+
+	// A 20 second interrupt entry breaks the default minimum
+	start := time.Now().Add(-time.Hour)
+	end := start.Add(20 * time.Second)
+	_, err = s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: start, EndTime: &end})
+	var ruleErr *TimeEntryRuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleMinDuration {
+		t.Fatalf("Expected a minimum duration error, got %v", err)
+	}
+
+	minSeconds, backdateDays := 10, 120
+	rules, err = s.SetProjectTimeEntryRules(project.ID, types.TimeEntryRulesOverride{MinDurationSeconds: &minSeconds, MaxBackdateDays: &backdateDays})
+	if err != nil {
+		t.Fatalf("Failed to set rules: %v", err)
+	}
+	if rules.Effective.MinDurationSeconds != 10 || rules.Effective.MaxBackdateDays != 120 || rules.Effective.MaxDurationMinutes != defaultTimeEntryRules.MaxDurationMinutes {
+		t.Errorf("Expected overrides merged with the defaults, got %+v", rules.Effective)
+	}
+
+	// The project's rules now allow the short entry and last quarter's logs
+	if _, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: start, EndTime: &end}); err != nil {
+		t.Errorf("Expected the short entry to be allowed, got %v", err)
+	}
+	oldStart := time.Now().AddDate(0, 0, -90)
+	oldEnd := oldStart.Add(time.Hour)
+	if _, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: oldStart, EndTime: &oldEnd}); err != nil {
+		t.Errorf("Expected the back-dated entry to be allowed, got %v", err)
+	}
+
+	// Clearing every override restores the defaults
+	rules, err = s.SetProjectTimeEntryRules(project.ID, types.TimeEntryRulesOverride{})
+	if err != nil {
+		t.Fatalf("Failed to clear rules: %v", err)
+	}
+	if rules.Effective != defaultTimeEntryRules {
+		t.Errorf("Expected the default rules after clearing, got %+v", rules.Effective)
+	}
+
+	// The override flag skips the rules, but not the overlap check
+	if _, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: oldStart.Add(-2 * time.Hour), EndTime: &oldStart, Override: true}); err != nil {
+		t.Errorf("Expected the override to skip the rules, got %v", err)
+	}
+	_, err = s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: oldStart, EndTime: &oldEnd, Override: true})
+	if !errors.As(err, &ruleErr) || ruleErr.Code != types.TimeEntryRuleOverlap {
+		t.Errorf("Expected an overlap error, got %v", err)
+	}
+
+	// Test nonexistent project
+	if _, err := s.GetProjectTimeEntryRules(99999); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("Expected does not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,423 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+const (
+	// scheduleSlot is the step proposals start on and the shortest block the scheduler plans
+	scheduleSlot = 15 * time.Minute
+
+	// defaultScheduleDays is how many days a proposal fills when the request does not say
+	defaultScheduleDays = 14
+
+	// scheduledBlockNote is the note of planned blocks created from a proposal
+	scheduledBlockNote = "Scheduled automatically"
+)
+
+// defaultWorkingHours returns weekdays from nine to five
+func defaultWorkingHours() types.WorkingHours {
+	var hours types.WorkingHours
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours[day] = []types.WorkPeriod{{Start: 9 * 60, End: 17 * 60}}
+	}
+	return hours
+}
+
+// scheduleTask is a task being placed by the scheduler
+type scheduleTask struct {
+	task      types.TaskWithProject
+	remaining time.Duration // Time still to be planned
+	deadline  *time.Time    // End of the day the task is due
+	subtasks  []*scheduleTask
+	visited   bool
+	placed    bool
+	finish    time.Time
+}
+
+// ProposeSchedule fills the free working time from now until the end of the requested number of days
+// with open tasks. Each task needs its estimate, or the default task duration, less the time already
+// tracked on it and the time already planned for it from now on. Free time is the configured working
+// hours outside time off, less existing planned blocks and timed calendar blocks.
+//
+// Tasks are placed greedily, earliest due first, then by priority and age, into the earliest free slots.
+// Open subtasks are placed before their parent, which starts only once they are finished. Tasks that do
+// not fit into the window are reported as unscheduled. Tasks finishing after the day they are due, and
+// unscheduled open tasks due within the window, are counted as late. With Commit set the proposed blocks
+// are saved as planned blocks.
+func (s *Storage) ProposeSchedule(req types.ProposeScheduleRequest, now time.Time) (*types.ScheduleProposal, error) {
+	days := req.Days
+	if days == 0 {
+		days = defaultScheduleDays
+	}
+	start := now.Truncate(scheduleSlot)
+	if start.Before(now) {
+		start = start.Add(scheduleSlot)
+	}
+	end := startOfDay(now, s.loc).AddDate(0, 0, days)
+
+	proposal := &types.ScheduleProposal{
+		Timezone:    s.loc.String(),
+		Start:       start,
+		End:         end,
+		Blocks:      []types.ProposedBlock{},
+		Tasks:       []types.ScheduledTask{},
+		Unscheduled: []types.UnscheduledTask{},
+	}
+
+	tasks, err := s.scheduleCandidates(req, proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	// Time already planned counts towards a task and keeps others out of its slot
+	planned, err := getPlannedBlocks(s.db, "pb.end_time > ?", now)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.GetCalendarBlocks(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var busy []interval
+	plannedUntil := make(map[int]time.Time)
+	for _, block := range planned {
+		busy = append(busy, interval{block.StartTime, block.EndTime})
+		if st, ok := tasks[block.TaskID]; ok {
+			from := block.StartTime
+			if from.Before(now) {
+				from = now
+			}
+			st.remaining -= block.EndTime.Sub(from)
+			if block.EndTime.After(plannedUntil[block.TaskID]) {
+				plannedUntil[block.TaskID] = block.EndTime
+			}
+		}
+	}
+	for _, block := range calendar {
+		if !block.AllDay {
+			busy = append(busy, interval{block.StartTime, block.EndTime})
+		}
+	}
+
+	working, err := s.workingTime(start, end)
+	if err != nil {
+		return nil, err
+	}
+	free := subtractIntervals(working, mergeIntervals(busy))
+	proposal.FreeMinutes = intervalSeconds(free) / 60
+
+	// Every candidate is open, so only open tasks' history needs summing
+	tracked, err := trackedSecondsByTask(s.db, time.Unix(0, 0), now, now, "t.status IN ('pending', 'in_progress')")
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*scheduleTask, 0, len(tasks))
+	for _, st := range tasks {
+		st.remaining -= time.Duration(tracked[st.task.ID]) * time.Second
+		// Plan whole minutes
+		if rest := st.remaining % time.Minute; rest > 0 {
+			st.remaining += time.Minute - rest
+		}
+		ordered = append(ordered, st)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if (a.deadline == nil) != (b.deadline == nil) {
+			return a.deadline != nil
+		}
+		if a.deadline != nil && !a.deadline.Equal(*b.deadline) {
+			return a.deadline.Before(*b.deadline)
+		}
+		if a.task.Priority != b.task.Priority {
+			return a.task.Priority > b.task.Priority
+		}
+		if !a.task.CreatedAt.Equal(b.task.CreatedAt) {
+			return a.task.CreatedAt.Before(b.task.CreatedAt)
+		}
+		return a.task.ID < b.task.ID
+	})
+	for _, st := range ordered {
+		if st.task.ParentID != nil {
+			if parent, ok := tasks[*st.task.ParentID]; ok {
+				parent.subtasks = append(parent.subtasks, st)
+			}
+		}
+	}
+
+	// An open task that cannot be placed is late when the window covers all the time before its deadline
+	unschedule := func(st *scheduleTask, reason string) {
+		proposal.Unscheduled = append(proposal.Unscheduled, unscheduledTask(st, reason))
+		if st.deadline != nil && !st.deadline.After(end) {
+			proposal.Late++
+		}
+	}
+
+	var place func(st *scheduleTask)
+	place = func(st *scheduleTask) {
+		if st.visited {
+			return
+		}
+		st.visited = true
+
+		earliest := start
+		for _, sub := range st.subtasks {
+			place(sub)
+			if !sub.placed {
+				unschedule(st, fmt.Sprintf("waits on subtask %d, which could not be scheduled", sub.task.ID))
+				return
+			}
+			if sub.finish.After(earliest) {
+				earliest = sub.finish
+			}
+		}
+
+		// Nothing left to plan, the task is done once its planned time is over
+		if st.remaining <= 0 {
+			st.placed = true
+			st.finish = earliest
+			if plannedUntil[st.task.ID].After(earliest) {
+				st.finish = plannedUntil[st.task.ID]
+			}
+			return
+		}
+
+		chunks, ok := allocateFreeTime(free, earliest, st.remaining)
+		if !ok {
+			unschedule(st, fmt.Sprintf("not enough free working time in the next %d days", days))
+			return
+		}
+		free = subtractIntervals(free, chunks)
+
+		st.placed = true
+		st.finish = chunks[len(chunks)-1].end
+		if plannedUntil[st.task.ID].After(st.finish) {
+			st.finish = plannedUntil[st.task.ID]
+		}
+		for _, chunk := range chunks {
+			proposal.Blocks = append(proposal.Blocks, types.ProposedBlock{
+				TaskID:    st.task.ID,
+				TaskTitle: st.task.Title,
+				ProjectID: st.task.ProjectID,
+				StartTime: chunk.start,
+				EndTime:   chunk.end,
+			})
+		}
+		proposal.Tasks = append(proposal.Tasks, types.ScheduledTask{
+			TaskID:    st.task.ID,
+			TaskTitle: st.task.Title,
+			ProjectID: st.task.ProjectID,
+			Priority:  st.task.Priority,
+			DueDate:   st.task.DueDate,
+			Minutes:   int(st.remaining / time.Minute),
+			Finish:    st.finish,
+			Late:      st.deadline != nil && st.finish.After(*st.deadline),
+		})
+	}
+	for _, st := range ordered {
+		place(st)
+	}
+
+	sort.Slice(proposal.Blocks, func(i, j int) bool {
+		return proposal.Blocks[i].StartTime.Before(proposal.Blocks[j].StartTime)
+	})
+	for _, task := range proposal.Tasks {
+		if task.Late {
+			proposal.Late++
+		}
+	}
+
+	if req.Commit {
+		if err := s.commitSchedule(proposal); err != nil {
+			return nil, err
+		}
+	}
+
+	return proposal, nil
+}
+
+// scheduleCandidates returns the open tasks a proposal considers by ID, with their full duration still
+// to plan. Requested tasks that are closed are added to the proposal as unscheduled.
+func (s *Storage) scheduleCandidates(req types.ProposeScheduleRequest, proposal *types.ScheduleProposal) (map[int]*scheduleTask, error) {
+	condition := "1 = 1"
+	var args []interface{}
+	if req.ProjectID != nil {
+		condition += " AND t.project_id = ?"
+		args = append(args, *req.ProjectID)
+	}
+	if len(req.TaskIDs) > 0 {
+		condition += " AND t.id IN (?" + strings.Repeat(", ?", len(req.TaskIDs)-1) + ")"
+		for _, id := range req.TaskIDs {
+			args = append(args, id)
+		}
+	} else {
+		condition += " AND t.status IN ('pending', 'in_progress')"
+	}
+
+	found, err := s.getTasksWithProjects(condition, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	estimates, err := getTaskEstimates(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[int]*scheduleTask)
+	for _, task := range found {
+		minutes, ok := estimates[task.ID]
+		if !ok {
+			minutes = s.taskMinutes
+		}
+		st := &scheduleTask{task: task, remaining: time.Duration(minutes) * time.Minute}
+		if task.DueDate != nil {
+			deadline := dueDeadline(*task.DueDate, s.loc)
+			st.deadline = &deadline
+		}
+
+		if task.Status == types.TaskStatusCompleted || task.Status == types.TaskStatusCancelled {
+			st.remaining = 0
+			proposal.Unscheduled = append(proposal.Unscheduled, unscheduledTask(st, fmt.Sprintf("task is %s", task.Status)))
+			continue
+		}
+		tasks[task.ID] = st
+	}
+
+	for _, id := range req.TaskIDs {
+		if !containsTask(found, id) {
+			return nil, fmt.Errorf("task with id %d does not exist", id)
+		}
+	}
+
+	return tasks, nil
+}
+
+// commitSchedule saves a proposal's blocks as planned blocks
+func (s *Storage) commitSchedule(proposal *types.ScheduleProposal) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	now := time.Now()
+	ids := make([]int, 0, len(proposal.Blocks))
+	for _, block := range proposal.Blocks {
+		req := types.CreatePlannedBlockRequest{TaskID: block.TaskID, StartTime: block.StartTime, EndTime: block.EndTime, Note: scheduledBlockNote}
+		if err := s.validatePlannedBlock(tx, req, 0); err != nil {
+			return err
+		}
+
+		var id int
+		err := tx.QueryRow(`INSERT INTO planned_blocks (task_id, start_time, end_time, note, created_at, updated_at)
+				  VALUES (?, ?, ?, ?, ?, ?)
+				  RETURNING id`,
+			req.TaskID, req.StartTime, req.EndTime, req.Note, now, now).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("failed to create planned block: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	proposal.Committed = []types.PlannedBlock{}
+	for _, id := range ids {
+		blocks, err := getPlannedBlocks(tx, "pb.id = ?", id)
+		if err != nil {
+			return err
+		}
+		proposal.Committed = append(proposal.Committed, blocks...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schedule transaction: %w", err)
+	}
+
+	return nil
+}
+
+// allocateFreeTime takes need from the earliest free intervals starting at earliest, skipping pieces
+// shorter than a slot unless they finish the task. It reports false when the free time does not suffice.
+func allocateFreeTime(free []interval, earliest time.Time, need time.Duration) ([]interval, bool) {
+	var chunks []interval
+	for _, iv := range free {
+		if need <= 0 {
+			break
+		}
+		if iv.start.Before(earliest) {
+			iv.start = earliest
+		}
+		length := iv.end.Sub(iv.start)
+		if length <= 0 || (length < scheduleSlot && length < need) {
+			continue
+		}
+		if length > need {
+			iv.end = iv.start.Add(need)
+		}
+		chunks = append(chunks, iv)
+		need -= iv.end.Sub(iv.start)
+	}
+	return chunks, need <= 0
+}
+
+// workingIntervals returns the working periods within [from, until) in loc, merged within each day.
+// Days in off, keyed by YYYY-MM-DD, have none.
+func workingIntervals(from, until time.Time, loc *time.Location, hours types.WorkingHours, off map[string]bool) []interval {
+	var working []interval
+	for day := startOfDay(from, loc); day.Before(until); day = day.AddDate(0, 0, 1) {
+		if off[day.Format("2006-01-02")] {
+			continue
+		}
+		var periods []interval
+		for _, period := range hours[day.Weekday()] {
+			periods = append(periods, interval{
+				time.Date(day.Year(), day.Month(), day.Day(), 0, period.Start, 0, 0, loc),
+				time.Date(day.Year(), day.Month(), day.Day(), 0, period.End, 0, 0, loc),
+			})
+		}
+		working = append(working, clipIntervals(mergeIntervals(periods), from, until)...)
+	}
+	return working
+}
+
+// dueDeadline returns when a task due at due must be finished. Due dates at midnight carry only a
+// date, so the task may take the whole day.
+func dueDeadline(due time.Time, loc *time.Location) time.Time {
+	if due.Equal(startOfDay(due, loc)) {
+		return due.In(loc).AddDate(0, 0, 1)
+	}
+	return due
+}
+
+// unscheduledTask describes a task the scheduler could not place
+func unscheduledTask(st *scheduleTask, reason string) types.UnscheduledTask {
+	minutes := 0
+	if st.remaining > 0 {
+		minutes = int(st.remaining / time.Minute)
+	}
+	return types.UnscheduledTask{
+		TaskID:    st.task.ID,
+		TaskTitle: st.task.Title,
+		ProjectID: st.task.ProjectID,
+		DueDate:   st.task.DueDate,
+		Minutes:   minutes,
+		Reason:    reason,
+	}
+}
+
+// containsTask reports whether tasks holds the task with the given ID
+func containsTask(tasks []types.TaskWithProject, id int) bool {
+	for _, task := range tasks {
+		if task.ID == id {
+			return true
+		}
+	}
+	return false
+}
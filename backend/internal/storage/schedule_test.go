@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestProposeSchedule(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.SetCalendar(time.UTC, time.Monday)
+
+	project := createTestProject(t, s)
+	monday := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return monday.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	newTask := func(title string, priority int, due *time.Time, parentID *int, minutes int) *types.Task {
+		task, err := s.CreateTask(types.CreateTaskRequest{ProjectID: project.ID, ParentID: parentID, Title: title, Priority: priority, DueDate: due})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if _, err := s.SetTaskEstimate(task.ID, &minutes); err != nil {
+			t.Fatalf("Failed to set estimate: %v", err)
+		}
+		return task
+	}
+
+	dueMorning := at(9, 0)
+	urgent := newTask("Urgent", 0, &dueMorning, nil, 60)
+	dueToday := monday
+	today := newTask("Today", 1, &dueToday, nil, 120)
+	parent := newTask("Parent", 9, nil, nil, 60)
+	child := newTask("Child", 0, nil, &parent.ID, 30)
+	planned := newTask("Planned", 0, nil, nil, 30)
+	huge := newTask("Huge", 0, nil, nil, 100000)
+
+	// Half an hour already tracked on Today and Planned's time already planned
+	createStoppedEntry(t, s, today.ID, time.Now().Add(-48*time.Hour), 30*time.Minute)
+	if _, err := s.CreatePlannedBlock(types.CreatePlannedBlockRequest{TaskID: planned.ID, StartTime: at(12, 30), EndTime: at(13, 0)}); err != nil {
+		t.Fatalf("Failed to create planned block: %v", err)
+	}
+	meeting := []types.CalendarBlock{{UID: "standup", Summary: "Meeting", StartTime: at(13, 0), EndTime: at(14, 0)}}
+	if _, err := s.ImportCalendarBlocks("work", meeting, monday, monday.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("Failed to import calendar blocks: %v", err)
+	}
+
+	proposal, err := s.ProposeSchedule(types.ProposeScheduleRequest{Days: 5}, at(8, 10))
+	if err != nil {
+		t.Fatalf("Failed to propose schedule: %v", err)
+	}
+
+	expected := []struct {
+		taskID     int
+		start, end time.Time
+	}{
+		{urgent.ID, at(9, 0), at(10, 0)},
+		{today.ID, at(10, 0), at(11, 30)},
+		{child.ID, at(11, 30), at(12, 0)},
+		{parent.ID, at(12, 0), at(12, 30)},
+		{parent.ID, at(14, 0), at(14, 30)},
+	}
+	if len(proposal.Blocks) != len(expected) {
+		t.Fatalf("Expected %d blocks, got %+v", len(expected), proposal.Blocks)
+	}
+	for i, e := range expected {
+		b := proposal.Blocks[i]
+		if b.TaskID != e.taskID || !b.StartTime.Equal(e.start) || !b.EndTime.Equal(e.end) {
+			t.Errorf("Block %d: expected task %d %v-%v, got %+v", i, e.taskID, e.start, e.end, b)
+		}
+	}
+
+	if len(proposal.Tasks) != 4 || proposal.Tasks[0].TaskID != urgent.ID || !proposal.Tasks[0].Late || proposal.Tasks[1].Late {
+		t.Errorf("Expected only the urgent task to be late, got %+v", proposal.Tasks)
+	}
+	if len(proposal.Unscheduled) != 1 || proposal.Unscheduled[0].TaskID != huge.ID || proposal.Late != 1 {
+		t.Errorf("Expected the huge task to be unscheduled, got %+v", proposal.Unscheduled)
+	}
+	if proposal.FreeMinutes != 5*8*60-30-60 {
+		t.Errorf("Expected free time without the planned and calendar blocks, got %d minutes", proposal.FreeMinutes)
+	}
+
+	// Committing saves the blocks, after which nothing is left to schedule but the huge task
+	committed, err := s.ProposeSchedule(types.ProposeScheduleRequest{Days: 5, Commit: true}, at(8, 10))
+	if err != nil {
+		t.Fatalf("Failed to commit schedule: %v", err)
+	}
+	if len(committed.Committed) != len(expected) || committed.Committed[0].Note != scheduledBlockNote {
+		t.Fatalf("Expected %d planned blocks, got %+v", len(expected), committed.Committed)
+	}
+
+	again, err := s.ProposeSchedule(types.ProposeScheduleRequest{Days: 5}, at(8, 10))
+	if err != nil {
+		t.Fatalf("Failed to propose schedule: %v", err)
+	}
+	if len(again.Blocks) != 0 || len(again.Unscheduled) != 1 {
+		t.Errorf("Expected nothing new to schedule, got %+v", again)
+	}
+
+	// Restricting to a closed or unknown task
+	if _, err := s.UpdateTaskStatus(urgent.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	closed, err := s.ProposeSchedule(types.ProposeScheduleRequest{TaskIDs: []int{urgent.ID}}, at(8, 10))
+	if err != nil || len(closed.Unscheduled) != 1 || closed.Unscheduled[0].Reason != "task is completed" {
+		t.Errorf("Expected the completed task to be unscheduled, got %+v, %v", closed, err)
+	}
+	if err == nil && closed.Late != 0 {
+		t.Errorf("Expected a completed task not to count as late, got %d", closed.Late)
+	}
+	if _, err := s.ProposeSchedule(types.ProposeScheduleRequest{TaskIDs: []int{9999}}, at(8, 10)); err == nil {
+		t.Error("Expected an error for an unknown task")
+	}
+}
@@ -3,19 +3,41 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+
+	"focused-todo/backend/pkg/types"
 )
 
 // Storage handles database operations
 type Storage struct {
-	db *sql.DB
+	db          *utcDB
+	globalTimer bool                 // Only one time entry may run at a time across all tasks
+	noOverlap   bool                 // Time entries may not overlap entries on other tasks
+	rules       types.TimeEntryRules // Default time entry rules, which projects may override
+	loc         *time.Location       // Timezone of weekly and monthly budget periods
+	weekStart   time.Weekday         // First day of weekly budget periods
+	thresholds  []int                // Default budget alert thresholds in percent
+	planSlots   int                  // Most tasks a daily plan may hold
+	hours       types.WorkingHours   // Working periods the scheduler fills
+	taskMinutes int                  // Time scheduled for tasks without an estimate
+	matrix      types.MatrixRules    // Classification of tasks the Eisenhower matrix falls back to
+	staleDays   int                  // Days without activity after which open tasks need review
+}
+
+// querier is satisfied by both *utcDB and *utcTx so helpers can run inside or outside a transaction
+// and always store time arguments in UTC
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
 // New creates a new Storage instance with connection pooling
 func New(dbPath string) (*Storage, error) {
 	// SQLite connection string with performance optimizations
-	dsn := dbPath + "?_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL&_cache_size=1000&_foreign_keys=on"
+	dsn := dbPath + "?_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL&_cache_size=1000&_foreign_keys=on&_loc=UTC"
 
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
@@ -33,7 +55,19 @@ func New(dbPath string) (*Storage, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	storage := &Storage{db: db}
+	storage := &Storage{
+		db:          &utcDB{DB: db},
+		globalTimer: true,
+		rules:       defaultTimeEntryRules,
+		loc:         time.Local,
+		weekStart:   time.Monday,
+		thresholds:  defaultBudgetThresholds,
+		planSlots:   defaultDailyPlanSlots,
+		hours:       defaultWorkingHours(),
+		taskMinutes: defaultTaskMinutes,
+		matrix:      defaultMatrixRules,
+		staleDays:   defaultStaleTaskDays,
+	}
 
 	// Run migrations
 	if err := storage.migrate(); err != nil {
@@ -43,6 +77,59 @@ func New(dbPath string) (*Storage, error) {
 	return storage, nil
 }
 
+// SetGlobalTimer enables or disables global timer mode, in which starting a
+// time entry stops any other running entry
+func (s *Storage) SetGlobalTimer(enabled bool) {
+	s.globalTimer = enabled
+}
+
+// SetNoOverlap enables or disables the global no-overlap policy, under which a time
+// entry may not overlap entries on any other task
+func (s *Storage) SetNoOverlap(enabled bool) {
+	s.noOverlap = enabled
+}
+
+// SetTimeEntryRules sets the default time entry rules used by projects without overrides
+func (s *Storage) SetTimeEntryRules(rules types.TimeEntryRules) {
+	s.rules = rules
+}
+
+// SetCalendar sets the timezone and first day of the week used for weekly and monthly budgets
+func (s *Storage) SetCalendar(loc *time.Location, weekStart time.Weekday) {
+	s.loc = loc
+	s.weekStart = weekStart
+}
+
+// SetBudgetThresholds sets the alert thresholds used by budgets without their own
+func (s *Storage) SetBudgetThresholds(thresholds []int) {
+	s.thresholds = normalizeThresholds(thresholds)
+}
+
+// SetDailyPlanSlots sets the most tasks a daily plan may hold
+func (s *Storage) SetDailyPlanSlots(slots int) {
+	s.planSlots = slots
+}
+
+// SetWorkingHours sets the working periods of each weekday in the calendar timezone
+func (s *Storage) SetWorkingHours(hours types.WorkingHours) {
+	s.hours = hours
+}
+
+// SetDefaultTaskMinutes sets how long the scheduler plans for tasks without an estimate
+func (s *Storage) SetDefaultTaskMinutes(minutes int) {
+	s.taskMinutes = minutes
+}
+
+// SetMatrixRules sets how tasks without an explicit classification are placed in the Eisenhower matrix
+func (s *Storage) SetMatrixRules(rules types.MatrixRules) {
+	s.matrix = rules
+}
+
+// SetStaleTaskDays sets how many days without activity make an open task due for review
+func (s *Storage) SetStaleTaskDays(days int) {
+	s.staleDays = days
+}
+
 // Close closes the database connection
 func (s *Storage) Close() error {
 	return s.db.Close()
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetTaskTags returns the tags of a task in alphabetical order
+func (s *Storage) GetTaskTags(taskID int) ([]string, error) {
+	exists, err := taskExists(s.db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify task existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("task with id %d not found", taskID)
+	}
+
+	tags, err := getAllTaskTags(s.db, "task_id = ?", taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tags[taskID] == nil {
+		return []string{}, nil
+	}
+	return tags[taskID], nil
+}
+
+// SetTaskTags replaces the tags of a task. Tags are trimmed, lowercased and deduplicated.
+func (s *Storage) SetTaskTags(taskID int, tags []string) ([]string, error) {
+	exists, err := taskExists(s.db, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify task existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("task with id %d not found", taskID)
+	}
+
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	if _, err := tx.Exec(`DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+		return nil, fmt.Errorf("failed to clear task tags: %w", err)
+	}
+
+	for _, tag := range normalized {
+		if _, err := tx.Exec(`INSERT INTO task_tags (task_id, tag) VALUES (?, ?)`, taskID, tag); err != nil {
+			return nil, fmt.Errorf("failed to add task tag: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit task tags transaction: %w", err)
+	}
+
+	return normalized, nil
+}
+
+// getAllTaskTags returns the tags matching the given condition keyed by task ID
+func getAllTaskTags(q querier, condition string, args ...interface{}) (map[int][]string, error) {
+	query := `SELECT task_id, tag FROM task_tags WHERE ` + condition + ` ORDER BY task_id, tag`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var taskID int
+		var tag string
+		if err := rows.Scan(&taskID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan task tag: %w", err)
+		}
+		tags[taskID] = append(tags[taskID], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading task tag rows: %w", err)
+	}
+
+	return tags, nil
+}
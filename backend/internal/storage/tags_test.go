@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestSetTaskTags(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	tags, err := s.GetTaskTags(task.ID)
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("Expected no tags initially, got %v", tags)
+	}
+
+	// Tags are normalized and deduplicated
+	tags, err = s.SetTaskTags(task.ID, []string{" Urgent", "client", "urgent", ""})
+	if err != nil {
+		t.Fatalf("Failed to set tags: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "client" || tags[1] != "urgent" {
+		t.Errorf("Expected [client urgent], got %v", tags)
+	}
+
+	// Setting tags replaces the previous set
+	if _, err := s.SetTaskTags(task.ID, []string{"later"}); err != nil {
+		t.Fatalf("Failed to set tags: %v", err)
+	}
+	tags, err = s.GetTaskTags(task.ID)
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "later" {
+		t.Errorf("Expected [later], got %v", tags)
+	}
+
+	// Test nonexistent task
+	_, err = s.SetTaskTags(99999, []string{"x"})
+	if err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// defaultStaleTaskDays is how many days without activity make an open task due for review
+const defaultStaleTaskDays = 14
+
+// GetReviewQueue returns the open tasks, optionally of one project, that have neither changed nor had time
+// tracked for days days, least recently active first. days falls back to the configured stale task days.
+// Tasks being tracked are left out, as are deferred tasks until their deferral ends and other reviewed tasks
+// until they have been inactive for days days again.
+func (s *Storage) GetReviewQueue(days int, projectID *int, now time.Time) (*types.TaskReviewQueue, error) {
+	if days <= 0 {
+		days = s.staleDays
+	}
+	cutoff := now.AddDate(0, 0, -days)
+
+	condition := `t.status IN ('pending', 'in_progress')
+		AND t.updated_at < ?
+		AND NOT EXISTS (SELECT 1 FROM time_entries te WHERE te.task_id = t.id AND (te.end_time IS NULL OR te.end_time >= ?))
+		AND NOT EXISTS (SELECT 1 FROM task_reviews tr WHERE tr.task_id = t.id
+			AND CASE WHEN tr.deferred_until IS NOT NULL THEN tr.deferred_until > ? ELSE tr.reviewed_at >= ? END)`
+	args := []interface{}{cutoff, cutoff, now, cutoff}
+	if projectID != nil {
+		condition += " AND t.project_id = ?"
+		args = append(args, *projectID)
+	}
+
+	// The latest stopped entry and the review are joined in, so each task takes a single row
+	query := `SELECT t.id, t.project_id, t.parent_id, t.title, t.description, t.status, t.priority, t.due_date, t.completed_at, t.created_at, t.updated_at,
+			         p.id, p.name, p.description, p.color, p.icon, p.created_at, p.updated_at,
+			         last.end_time, tr.reviewed_at
+			  FROM tasks t
+			  JOIN projects p ON t.project_id = p.id
+			  LEFT JOIN time_entries last ON last.id = (
+			      SELECT l.id FROM time_entries l WHERE l.task_id = t.id AND l.end_time IS NOT NULL ORDER BY l.end_time DESC LIMIT 1)
+			  LEFT JOIN task_reviews tr ON tr.task_id = t.id
+			  WHERE ` + condition + `
+			  ORDER BY t.priority DESC, t.created_at ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review queue: %w", err)
+	}
+	defer rows.Close()
+
+	queue := &types.TaskReviewQueue{StaleDays: days, Cutoff: cutoff, Tasks: []types.StaleTask{}}
+	for rows.Next() {
+		var stale types.StaleTask
+		err := rows.Scan(
+			&stale.ID,
+			&stale.ProjectID,
+			&stale.ParentID,
+			&stale.Title,
+			&stale.Description,
+			&stale.Status,
+			&stale.Priority,
+			&stale.DueDate,
+			&stale.CompletedAt,
+			&stale.CreatedAt,
+			&stale.UpdatedAt,
+			&stale.Project.ID,
+			&stale.Project.Name,
+			&stale.Project.Description,
+			&stale.Project.Color,
+			&stale.Project.Icon,
+			&stale.Project.CreatedAt,
+			&stale.Project.UpdatedAt,
+			&stale.LastTrackedAt,
+			&stale.LastReviewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stale task: %w", err)
+		}
+
+		stale.LastActivityAt = stale.UpdatedAt
+		if stale.LastTrackedAt != nil && stale.LastTrackedAt.After(stale.LastActivityAt) {
+			stale.LastActivityAt = *stale.LastTrackedAt
+		}
+		stale.IdleDays = int(now.Sub(stale.LastActivityAt).Hours() / 24)
+		queue.Tasks = append(queue.Tasks, stale)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading stale task rows: %w", err)
+	}
+
+	sort.SliceStable(queue.Tasks, func(i, j int) bool {
+		return queue.Tasks[i].LastActivityAt.Before(queue.Tasks[j].LastActivityAt)
+	})
+
+	return queue, nil
+}
+
+// ReviewTasks applies a review action to tasks in batch and records the review, so the tasks leave the
+// review queue until they have been inactive for the stale task days again
+func (s *Storage) ReviewTasks(req types.ReviewTasksRequest, now time.Time) (*types.ReviewTasksResult, error) {
+	var deferredUntil *time.Time
+	step := req.PriorityStep
+	switch req.Action {
+	case types.TaskReviewDefer:
+		if req.DeferUntil == nil {
+			return nil, fmt.Errorf("defer_until is required to defer tasks")
+		}
+		if !req.DeferUntil.After(now) {
+			return nil, fmt.Errorf("defer_until must be after now")
+		}
+		deferredUntil = req.DeferUntil
+	case types.TaskReviewBump:
+		if step == 0 {
+			step = 1
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	result := &types.ReviewTasksResult{Tasks: []types.Task{}}
+	seen := make(map[int]bool)
+	var ids []int
+	for _, taskID := range req.TaskIDs {
+		if seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+
+		if err := checkReviewable(tx, taskID); err != nil {
+			return nil, err
+		}
+
+		switch req.Action {
+		case types.TaskReviewCancel:
+			// A running timer is stopped first, so no time keeps being tracked on the cancelled task
+			entry, err := getActiveTimeEntry(tx, taskID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+			case err != nil:
+				return nil, err
+			default:
+				if _, err := stopTimeEntry(tx, entry, entry.Description, now); err != nil {
+					return nil, err
+				}
+				if err := s.raiseBudgetAlerts(tx, now, taskID); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := setTaskStatus(tx, taskID, types.TaskStatusCancelled, now); err != nil {
+				return nil, err
+			}
+		case types.TaskReviewBump:
+			if _, err := tx.Exec(`UPDATE tasks SET priority = MIN(priority + ?, 10), updated_at = ? WHERE id = ?`, step, now, taskID); err != nil {
+				return nil, fmt.Errorf("failed to bump task priority: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(`INSERT INTO task_reviews (task_id, reviewed_at, deferred_until) VALUES (?, ?, ?)
+				  ON CONFLICT(task_id) DO UPDATE SET reviewed_at = excluded.reviewed_at, deferred_until = excluded.deferred_until`,
+			taskID, now, deferredUntil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record task review: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO task_review_log (task_id, action, reviewed_at) VALUES (?, ?, ?)`, taskID, req.Action, now); err != nil {
+			return nil, fmt.Errorf("failed to log task review: %w", err)
+		}
+
+		ids = append(ids, taskID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit task review transaction: %w", err)
+	}
+
+	for _, taskID := range ids {
+		task, err := s.GetTask(taskID)
+		if err != nil {
+			return nil, err
+		}
+		result.Tasks = append(result.Tasks, *task)
+	}
+	result.Reviewed = len(ids)
+
+	return result, nil
+}
+
+// checkReviewable verifies that a task exists and is still open, so a review cannot reopen or cancel a
+// task that was already completed or cancelled
+func checkReviewable(q querier, taskID int) error {
+	var status types.TaskStatus
+	err := q.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task with id %d does not exist", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check task %d: %w", taskID, err)
+	}
+	if status == types.TaskStatusCompleted || status == types.TaskStatusCancelled {
+		return fmt.Errorf("task %d is %s and cannot be reviewed", taskID, status)
+	}
+	return nil
+}
+
+// GetReviewSummary summarises the reviews of the week starting at weekStart, in weekStart's location,
+// with the tasks created and completed during it and how many tasks wait for review at now
+func (s *Storage) GetReviewSummary(weekStart time.Time, now time.Time) (*types.TaskReviewSummary, error) {
+	loc := weekStart.Location()
+	weekStart = startOfDay(weekStart, loc)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	summary := &types.TaskReviewSummary{
+		Week:     weekLabel(weekStart),
+		Timezone: loc.String(),
+		Start:    weekStart,
+		End:      weekEnd,
+		Log:      []types.TaskReviewLogEntry{},
+	}
+
+	rows, err := s.db.Query(`SELECT l.id, l.task_id, t.title, t.project_id, l.action, l.reviewed_at
+			  FROM task_review_log l
+			  JOIN tasks t ON l.task_id = t.id
+			  WHERE l.reviewed_at >= ? AND l.reviewed_at < ?
+			  ORDER BY l.reviewed_at ASC, l.id ASC`, weekStart, weekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviewed := make(map[int]bool)
+	for rows.Next() {
+		var entry types.TaskReviewLogEntry
+		if err := rows.Scan(&entry.ID, &entry.TaskID, &entry.TaskTitle, &entry.ProjectID, &entry.Action, &entry.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task review: %w", err)
+		}
+		entry.ReviewedAt = entry.ReviewedAt.In(loc)
+
+		switch entry.Action {
+		case types.TaskReviewReviewed:
+			summary.Actions.Reviewed++
+		case types.TaskReviewDefer:
+			summary.Actions.Deferred++
+		case types.TaskReviewCancel:
+			summary.Actions.Cancelled++
+		case types.TaskReviewBump:
+			summary.Actions.Bumped++
+		}
+		reviewed[entry.TaskID] = true
+		summary.Log = append(summary.Log, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading task review rows: %w", err)
+	}
+	summary.TasksReviewed = len(reviewed)
+
+	err = s.db.QueryRow(`SELECT
+			  (SELECT COUNT(*) FROM tasks WHERE created_at >= ? AND created_at < ?),
+			  (SELECT COUNT(*) FROM tasks WHERE status = ? AND completed_at >= ? AND completed_at < ?)`,
+		weekStart, weekEnd, types.TaskStatusCompleted, weekStart, weekEnd).Scan(&summary.Created, &summary.Completed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count created and completed tasks: %w", err)
+	}
+
+	queue, err := s.GetReviewQueue(0, nil, now)
+	if err != nil {
+		return nil, err
+	}
+	summary.StaleNow = len(queue.Tasks)
+
+	return summary, nil
+}
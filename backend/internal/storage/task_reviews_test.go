@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestTaskReviews(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	newTask := func(title string) *types.Task {
+		task, err := s.CreateTask(types.CreateTaskRequest{ProjectID: project.ID, Title: title, Priority: 5})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return task
+	}
+
+	forgotten := newTask("Forgotten")
+	trackedLongAgo := newTask("Tracked long ago")
+	toBump := newTask("Bump")
+	toCancel := newTask("Cancel")
+	trackedRecently := newTask("Tracked recently")
+	running := newTask("Running")
+	newTask("Fresh")
+
+	createStoppedEntry(t, s, trackedLongAgo.ID, daysAgo(20), time.Hour)
+	createStoppedEntry(t, s, trackedRecently.ID, daysAgo(2), time.Hour)
+	if _, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: running.ID, StartTime: daysAgo(20)}); err != nil {
+		t.Fatalf("Failed to start time entry: %v", err)
+	}
+	for _, task := range []*types.Task{forgotten, trackedLongAgo, toBump, toCancel, trackedRecently, running} {
+		if _, err := s.db.Exec(`UPDATE tasks SET updated_at = ? WHERE id = ?`, daysAgo(30), task.ID); err != nil {
+			t.Fatalf("Failed to age task: %v", err)
+		}
+	}
+
+	queue, err := s.GetReviewQueue(0, nil, now)
+	if err != nil {
+		t.Fatalf("Failed to get review queue: %v", err)
+	}
+	if queue.StaleDays != defaultStaleTaskDays || len(queue.Tasks) != 4 {
+		t.Fatalf("Expected 4 stale tasks, got %+v", queue.Tasks)
+	}
+	if last := queue.Tasks[len(queue.Tasks)-1]; last.ID != trackedLongAgo.ID || last.LastTrackedAt == nil || last.IdleDays != 19 {
+		t.Errorf("Expected the task tracked long ago last, idle since its entry, got %+v", last)
+	}
+
+	deferUntil, yesterday := now.AddDate(0, 0, 30), daysAgo(1)
+	review := func(action types.TaskReviewAction, ids ...int) *types.ReviewTasksResult {
+		result, err := s.ReviewTasks(types.ReviewTasksRequest{TaskIDs: ids, Action: action, PriorityStep: 2, DeferUntil: &deferUntil}, now)
+		if err != nil {
+			t.Fatalf("Failed to %s tasks: %v", action, err)
+		}
+		return result
+	}
+	review(types.TaskReviewReviewed, forgotten.ID, forgotten.ID)
+	review(types.TaskReviewDefer, trackedLongAgo.ID)
+	if bumped := review(types.TaskReviewBump, toBump.ID); bumped.Tasks[0].Priority != 7 {
+		t.Errorf("Expected priority bumped to 7, got %d", bumped.Tasks[0].Priority)
+	}
+	if cancelled := review(types.TaskReviewCancel, toCancel.ID); cancelled.Tasks[0].Status != types.TaskStatusCancelled {
+		t.Errorf("Expected the task cancelled, got %s", cancelled.Tasks[0].Status)
+	}
+
+	queue, err = s.GetReviewQueue(0, nil, now)
+	if err != nil || len(queue.Tasks) != 0 {
+		t.Fatalf("Expected an empty queue after reviewing, got %+v, %v", queue, err)
+	}
+
+	// Two weeks later reviewed tasks are stale again along with the others, but deferred and running ones are not
+	queue, err = s.GetReviewQueue(0, nil, now.AddDate(0, 0, 15))
+	if err != nil {
+		t.Fatalf("Failed to get review queue: %v", err)
+	}
+	if len(queue.Tasks) != 4 || queue.Tasks[0].ID != forgotten.ID || queue.Tasks[0].LastReviewedAt == nil {
+		t.Errorf("Expected the reviewed task back first among 4, got %+v", queue.Tasks)
+	}
+	for _, task := range queue.Tasks {
+		if task.ID == trackedLongAgo.ID || task.ID == running.ID {
+			t.Errorf("Expected task %d to stay out of the queue", task.ID)
+		}
+	}
+
+	summary, err := s.GetReviewSummary(StartOfWeek(now, time.UTC, time.Monday), now)
+	if err != nil {
+		t.Fatalf("Failed to get review summary: %v", err)
+	}
+	expected := types.TaskReviewCounts{Reviewed: 1, Deferred: 1, Cancelled: 1, Bumped: 1}
+	if summary.Actions != expected || summary.TasksReviewed != 4 || len(summary.Log) != 4 || summary.Created != 7 || summary.StaleNow != 0 {
+		t.Errorf("Unexpected review summary %+v", summary)
+	}
+
+	// Cancelling a task being tracked stops its timer
+	review(types.TaskReviewCancel, running.ID)
+	if _, err := s.GetActiveTimeEntry(running.ID); err == nil {
+		t.Errorf("Expected the timer of the cancelled task to be stopped")
+	}
+
+	tests := []struct {
+		name string
+		req  types.ReviewTasksRequest
+		err  string
+	}{
+		{"defer without date", types.ReviewTasksRequest{TaskIDs: []int{forgotten.ID}, Action: types.TaskReviewDefer}, "required"},
+		{"defer into the past", types.ReviewTasksRequest{TaskIDs: []int{forgotten.ID}, Action: types.TaskReviewDefer, DeferUntil: &yesterday}, "must be after"},
+		{"unknown task", types.ReviewTasksRequest{TaskIDs: []int{9999}, Action: types.TaskReviewReviewed}, "does not exist"},
+		{"cancel a cancelled task", types.ReviewTasksRequest{TaskIDs: []int{toCancel.ID}, Action: types.TaskReviewCancel}, "cannot be reviewed"},
+	}
+	for _, tt := range tests {
+		if _, err := s.ReviewTasks(tt.req, now); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestTaskReviewShortDefer(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	now := time.Now()
+	if _, err := s.db.Exec(`UPDATE tasks SET updated_at = ? WHERE id = ?`, now.AddDate(0, 0, -30), task.ID); err != nil {
+		t.Fatalf("Failed to age task: %v", err)
+	}
+
+	deferUntil := now.AddDate(0, 0, 3)
+	req := types.ReviewTasksRequest{TaskIDs: []int{task.ID}, Action: types.TaskReviewDefer, DeferUntil: &deferUntil}
+	if _, err := s.ReviewTasks(req, now); err != nil {
+		t.Fatalf("Failed to defer task: %v", err)
+	}
+
+	// A deferral shorter than the stale task days ends when asked, not after a full review period
+	for _, tt := range []struct {
+		at       time.Time
+		expected int
+	}{
+		{now.AddDate(0, 0, 2), 0},
+		{now.AddDate(0, 0, 4), 1},
+	} {
+		queue, err := s.GetReviewQueue(0, nil, tt.at)
+		if err != nil {
+			t.Fatalf("Failed to get review queue: %v", err)
+		}
+		if len(queue.Tasks) != tt.expected {
+			t.Errorf("Expected %d tasks in the queue at %v, got %d", tt.expected, tt.at, len(queue.Tasks))
+		}
+	}
+
+	// A completed task cannot be cancelled by a review
+	if _, err := s.UpdateTaskStatus(task.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if _, err := s.ReviewTasks(types.ReviewTasksRequest{TaskIDs: []int{task.ID}, Action: types.TaskReviewCancel}, now); err == nil || !strings.Contains(err.Error(), "cannot be reviewed") {
+		t.Errorf("Expected cancelling a completed task to fail, got %v", err)
+	}
+	completed, err := s.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if completed.Status != types.TaskStatusCompleted || completed.CompletedAt == nil {
+		t.Errorf("Expected the task to stay completed, got %s", completed.Status)
+	}
+}
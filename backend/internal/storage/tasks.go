@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
 	"focused-todo/backend/pkg/types"
@@ -35,7 +36,7 @@ func (s *Storage) CreateTask(req types.CreateTaskRequest) (*types.Task, error) {
 
 	query := `INSERT INTO tasks (project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at) 
 			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) 
-			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at`
+			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at`
 
 	now := time.Now()
 
@@ -50,6 +51,7 @@ func (s *Storage) CreateTask(req types.CreateTaskRequest) (*types.Task, error) {
 		&task.Status,
 		&task.Priority,
 		&task.DueDate,
+		&task.CompletedAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -63,12 +65,17 @@ func (s *Storage) CreateTask(req types.CreateTaskRequest) (*types.Task, error) {
 
 // GetTask retrieves a task by ID
 func (s *Storage) GetTask(id int) (*types.Task, error) {
-	query := `SELECT id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at 
+	return getTask(s.db, id)
+}
+
+// getTask retrieves a task by ID using the given querier
+func getTask(q querier, id int) (*types.Task, error) {
+	query := `SELECT id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at 
 			  FROM tasks 
 			  WHERE id = ?`
 
 	var task types.Task
-	err := s.db.QueryRow(query, id).Scan(
+	err := q.QueryRow(query, id).Scan(
 		&task.ID,
 		&task.ProjectID,
 		&task.ParentID,
@@ -77,6 +84,7 @@ func (s *Storage) GetTask(id int) (*types.Task, error) {
 		&task.Status,
 		&task.Priority,
 		&task.DueDate,
+		&task.CompletedAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -93,29 +101,58 @@ func (s *Storage) GetTask(id int) (*types.Task, error) {
 
 // GetTasksByProject retrieves all tasks for a specific project
 func (s *Storage) GetTasksByProject(projectID int) ([]types.Task, error) {
+	page, err := s.ListTasksByProject(projectID, types.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// ListTasksByProject retrieves one page of a project's tasks in list order
+func (s *Storage) ListTasksByProject(projectID int, page types.PageRequest) (types.Page[types.Task], error) {
+	if err := validatePageRequest(page); err != nil {
+		return types.Page[types.Task]{}, err
+	}
+
+	cursor, err := decodeCursor(page.Cursor, 2)
+	if err != nil {
+		return types.Page[types.Task]{}, err
+	}
+
 	// First verify that the project exists
 	projectExists, err := s.projectExists(projectID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+		return types.Page[types.Task]{}, fmt.Errorf("failed to verify project existence: %w", err)
 	}
 	if !projectExists {
-		return nil, fmt.Errorf("project with id %d does not exist", projectID)
+		return types.Page[types.Task]{}, fmt.Errorf("project with id %d does not exist", projectID)
 	}
 
-	query := `SELECT id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at 
+	query := `SELECT id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at, CAST(created_at AS TEXT) 
 			  FROM tasks 
-			  WHERE project_id = ? 
-			  ORDER BY priority DESC, created_at ASC`
+			  WHERE project_id = ?`
+	args := []interface{}{projectID}
+	if cursor != nil {
+		query += ` AND (priority < ? OR (priority = ? AND (created_at > ? OR (created_at = ? AND id > ?))))`
+		args = append(args, cursor.Sort[0], cursor.Sort[0], cursor.Sort[1], cursor.Sort[1], cursor.ID)
+	}
+	query += ` ORDER BY priority DESC, created_at ASC, id ASC`
+	if page.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, page.Limit+1) // One extra row tells us whether another page exists
+	}
 
-	rows, err := s.db.Query(query, projectID)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks: %w", err)
+		return types.Page[types.Task]{}, fmt.Errorf("failed to query tasks: %w", err)
 	}
 	defer rows.Close()
 
-	var tasks []types.Task
+	tasks := []types.Task{}
+	var cursors []pageCursor
 	for rows.Next() {
 		var task types.Task
+		var createdAtKey string
 		err := rows.Scan(
 			&task.ID,
 			&task.ProjectID,
@@ -125,25 +162,43 @@ func (s *Storage) GetTasksByProject(projectID int) ([]types.Task, error) {
 			&task.Status,
 			&task.Priority,
 			&task.DueDate,
+			&task.CompletedAt,
 			&task.CreatedAt,
 			&task.UpdatedAt,
+			&createdAtKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+			return types.Page[types.Task]{}, fmt.Errorf("failed to scan task: %w", err)
 		}
 		tasks = append(tasks, task)
+		cursors = append(cursors, pageCursor{Sort: []string{strconv.Itoa(task.Priority), createdAtKey}, ID: task.ID})
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading task rows: %w", err)
+		return types.Page[types.Task]{}, fmt.Errorf("error reading task rows: %w", err)
 	}
 
-	return tasks, nil
+	result := finishPage(tasks, cursors, page.Limit)
+	if page.IncludeTotal {
+		result.Total, err = s.countRows(`SELECT COUNT(*) FROM tasks WHERE project_id = ?`, projectID)
+		if err != nil {
+			return types.Page[types.Task]{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// IterateTasksByProject calls fn for every task of a project in list order without loading them all into memory
+func (s *Storage) IterateTasksByProject(projectID int, fn func(types.Task) error) error {
+	return iteratePages(func(cursor string) (types.Page[types.Task], error) {
+		return s.ListTasksByProject(projectID, types.PageRequest{Limit: iterateBatchSize, Cursor: cursor})
+	}, fn)
 }
 
 // GetSubtasks retrieves all subtasks for a parent task
 func (s *Storage) GetSubtasks(parentID int) ([]types.Task, error) {
-	query := `SELECT id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at 
+	query := `SELECT id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at 
 			  FROM tasks 
 			  WHERE parent_id = ? 
 			  ORDER BY priority DESC, created_at ASC`
@@ -166,6 +221,7 @@ func (s *Storage) GetSubtasks(parentID int) ([]types.Task, error) {
 			&task.Status,
 			&task.Priority,
 			&task.DueDate,
+			&task.CompletedAt,
 			&task.CreatedAt,
 			&task.UpdatedAt,
 		)
@@ -223,7 +279,7 @@ func (s *Storage) UpdateTask(id int, req types.CreateTaskRequest) (*types.Task,
 	query := `UPDATE tasks 
 			  SET project_id = ?, parent_id = ?, title = ?, description = ?, priority = ?, due_date = ?, updated_at = ? 
 			  WHERE id = ?
-			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at`
+			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at`
 
 	now := time.Now()
 
@@ -238,6 +294,7 @@ func (s *Storage) UpdateTask(id int, req types.CreateTaskRequest) (*types.Task,
 		&task.Status,
 		&task.Priority,
 		&task.DueDate,
+		&task.CompletedAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -252,17 +309,59 @@ func (s *Storage) UpdateTask(id int, req types.CreateTaskRequest) (*types.Task,
 	return &task, nil
 }
 
-// UpdateTaskStatus updates the status of a task
+// UpdateTaskStatus updates the status of a task, moving it to the end of its new board column
 func (s *Storage) UpdateTaskStatus(id int, status types.TaskStatus) (*types.Task, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	task, err := setTaskStatus(tx, id, status, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit status transaction: %w", err)
+	}
+
+	return task, nil
+}
+
+// setTaskStatus changes a task's status through the board so that it leaves its old column for the end of
+// the new one. A task keeping its status keeps its board position.
+func setTaskStatus(q querier, id int, status types.TaskStatus, now time.Time) (*types.Task, error) {
+	var current types.TaskStatus
+	if err := q.QueryRow(`SELECT status FROM tasks WHERE id = ?`, id).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get task status: %w", err)
+	}
+
+	if current == status {
+		return updateTaskStatus(q, id, status, now)
+	}
+	return moveTask(q, id, status, -1, now)
+}
+
+// updateTaskStatus applies a status change and its side effects using the given querier
+func updateTaskStatus(q querier, id int, status types.TaskStatus, now time.Time) (*types.Task, error) {
 	query := `UPDATE tasks 
-			  SET status = ?, updated_at = ? 
+			  SET status = ?, completed_at = CASE WHEN status = ? THEN completed_at ELSE ? END, updated_at = ? 
 			  WHERE id = ?
-			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at`
+			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at`
 
-	now := time.Now()
+	// Completion timestamp is only kept while the task stays completed, and saving the same status again
+	// keeps the original timestamp
+	var completedAt *time.Time
+	if status == types.TaskStatusCompleted {
+		completedAt = &now
+	}
 
 	var task types.Task
-	err := s.db.QueryRow(query, status, now, id).Scan(
+	err := q.QueryRow(query, status, status, completedAt, now, id).Scan(
 		&task.ID,
 		&task.ProjectID,
 		&task.ParentID,
@@ -271,6 +370,7 @@ func (s *Storage) UpdateTaskStatus(id int, status types.TaskStatus) (*types.Task
 		&task.Status,
 		&task.Priority,
 		&task.DueDate,
+		&task.CompletedAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -290,7 +390,7 @@ func (s *Storage) UpdateTaskPriority(id int, priority int) (*types.Task, error)
 	query := `UPDATE tasks 
 			  SET priority = ?, updated_at = ? 
 			  WHERE id = ?
-			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, created_at, updated_at`
+			  RETURNING id, project_id, parent_id, title, description, status, priority, due_date, completed_at, created_at, updated_at`
 
 	now := time.Now()
 
@@ -304,6 +404,7 @@ func (s *Storage) UpdateTaskPriority(id int, priority int) (*types.Task, error)
 		&task.Status,
 		&task.Priority,
 		&task.DueDate,
+		&task.CompletedAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -330,10 +431,18 @@ func (s *Storage) ReorderTasks(taskOrders []types.TaskOrder) error {
 	now := time.Now()
 
 	for _, order := range taskOrders {
-		_, err := tx.Exec(query, order.Priority, now, order.TaskID)
+		result, err := tx.Exec(query, order.Priority, now, order.TaskID)
 		if err != nil {
 			return fmt.Errorf("failed to update task %d priority: %w", order.TaskID, err)
 		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("task with id %d not found", order.TaskID)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
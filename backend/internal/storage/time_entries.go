@@ -5,22 +5,43 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mattn/go-sqlite3"
+
 	"focused-todo/backend/pkg/types"
 )
 
-// CreateTimeEntry creates a new time entry in the database
+// CreateTimeEntry creates a new time entry in the database.
+// An entry without an end time is running, so in global timer mode it stops the entries of other tasks.
 func (s *Storage) CreateTimeEntry(req types.CreateTimeEntryRequest) (*types.TimeEntry, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
 	// First verify that the task exists
-	taskExists, err := s.taskExists(req.TaskID)
+	exists, err := taskExists(tx, req.TaskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify task existence: %w", err)
 	}
-	if !taskExists {
+	if !exists {
 		return nil, fmt.Errorf("task with id %d does not exist", req.TaskID)
 	}
 
+	now := time.Now()
+
 	// Validate business logic
-	if err := s.validateTimeEntryBusinessLogic(req.TaskID, req.StartTime, req.EndTime); err != nil {
+	if err := s.validateTimeEntryBusinessLogic(tx, req.TaskID, req.StartTime, req.EndTime, now, req.Override); err != nil {
+		return nil, err
+	}
+
+	// Entries stopped here no longer count as overlapping the new one
+	if req.EndTime == nil {
+		if _, err := s.stopOtherTimers(tx, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validateNoCrossTaskOverlap(tx, req.TaskID, req.StartTime, req.EndTime, 0); err != nil {
 		return nil, err
 	}
 
@@ -38,10 +59,8 @@ func (s *Storage) CreateTimeEntry(req types.CreateTimeEntryRequest) (*types.Time
 			  VALUES (?, ?, ?, ?, ?, ?) 
 			  RETURNING id, task_id, start_time, end_time, duration, description, created_at`
 
-	now := time.Now()
-
 	var timeEntry types.TimeEntry
-	err = s.db.QueryRow(query, req.TaskID, req.StartTime, req.EndTime, duration, req.Description, now).Scan(
+	err = tx.QueryRow(query, req.TaskID, req.StartTime, req.EndTime, duration, req.Description, now).Scan(
 		&timeEntry.ID,
 		&timeEntry.TaskID,
 		&timeEntry.StartTime,
@@ -55,27 +74,48 @@ func (s *Storage) CreateTimeEntry(req types.CreateTimeEntryRequest) (*types.Time
 		return nil, fmt.Errorf("failed to create time entry: %w", err)
 	}
 
+	if err := s.raiseBudgetAlerts(tx, now, req.TaskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit create transaction: %w", err)
+	}
+
 	return &timeEntry, nil
 }
 
-// StartTimeEntry starts a new time entry for a task
-func (s *Storage) StartTimeEntry(req types.StartTimeEntryRequest) (*types.TimeEntry, error) {
+// StartTimeEntry starts a new time entry for a task.
+// In global timer mode any entry running on another task is stopped in the same transaction,
+// and the response warns about exhausted budgets the task's time counts against.
+func (s *Storage) StartTimeEntry(req types.StartTimeEntryRequest) (*types.StartTimeEntryResponse, error) {
+	return s.StartTimeEntryAt(req, time.Now())
+}
+
+// StartTimeEntryAt starts a new time entry for a task at the given time
+func (s *Storage) StartTimeEntryAt(req types.StartTimeEntryRequest, now time.Time) (*types.StartTimeEntryResponse, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
 	// First verify that the task exists
-	taskExists, err := s.taskExists(req.TaskID)
+	exists, err := taskExists(tx, req.TaskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify task existence: %w", err)
 	}
-	if !taskExists {
+	if !exists {
 		return nil, fmt.Errorf("task with id %d does not exist", req.TaskID)
 	}
 
 	// Validate that task is in a trackable state
-	if err := s.validateTaskTrackable(req.TaskID); err != nil {
+	if err := validateTaskTrackable(tx, req.TaskID); err != nil {
 		return nil, err
 	}
 
 	// Check if there's already an active time entry for this task
-	activeEntry, err := s.getActiveTimeEntry(req.TaskID)
+	activeEntry, err := getActiveTimeEntry(tx, req.TaskID)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, fmt.Errorf("failed to check for active time entry: %w", err)
 	}
@@ -83,9 +123,25 @@ func (s *Storage) StartTimeEntry(req types.StartTimeEntryRequest) (*types.TimeEn
 		return nil, fmt.Errorf("task %d already has an active time entry", req.TaskID)
 	}
 
-	now := time.Now()
 	// Validate business logic for start time
-	if err := s.validateTimeEntryBusinessLogic(req.TaskID, now, nil); err != nil {
+	if err := s.validateTimeEntryBusinessLogic(tx, req.TaskID, now, nil, now, false); err != nil {
+		return nil, err
+	}
+
+	// Starting on an exhausted budget is allowed but reported
+	warnings, err := s.budgetWarningsForTask(tx, req.TaskID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.StartTimeEntryResponse{BudgetWarnings: warnings}
+	response.Stopped, err = s.stopOtherTimers(tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries stopped above no longer count as overlapping the new one
+	if err := s.validateNoCrossTaskOverlap(tx, req.TaskID, now, nil, 0); err != nil {
 		return nil, err
 	}
 
@@ -93,28 +149,65 @@ func (s *Storage) StartTimeEntry(req types.StartTimeEntryRequest) (*types.TimeEn
 			  VALUES (?, ?, ?, ?) 
 			  RETURNING id, task_id, start_time, end_time, duration, description, created_at`
 
-	var timeEntry types.TimeEntry
-	err = s.db.QueryRow(query, req.TaskID, now, req.Description, now).Scan(
-		&timeEntry.ID,
-		&timeEntry.TaskID,
-		&timeEntry.StartTime,
-		&timeEntry.EndTime,
-		&timeEntry.Duration,
-		&timeEntry.Description,
-		&timeEntry.CreatedAt,
+	err = tx.QueryRow(query, req.TaskID, now, req.Description, now).Scan(
+		&response.ID,
+		&response.TaskID,
+		&response.StartTime,
+		&response.EndTime,
+		&response.Duration,
+		&response.Description,
+		&response.CreatedAt,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to start time entry: %w", err)
 	}
 
-	return &timeEntry, nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit start transaction: %w", err)
+	}
+
+	return response, nil
+}
+
+// stopOtherTimers stops every running time entry in global timer mode, so a new one can be the only
+// one running, and raises the budget alerts for the time they recorded
+func (s *Storage) stopOtherTimers(q querier, now time.Time) ([]types.TimeEntry, error) {
+	if !s.globalTimer {
+		return nil, nil
+	}
+
+	stopped, err := stopRunningTimeEntries(q, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var stoppedTaskIDs []int
+	for _, entry := range stopped {
+		stoppedTaskIDs = append(stoppedTaskIDs, entry.TaskID)
+	}
+	if err := s.raiseBudgetAlerts(q, now, stoppedTaskIDs...); err != nil {
+		return nil, err
+	}
+
+	return stopped, nil
 }
 
 // StopTimeEntry stops an active time entry for a task
 func (s *Storage) StopTimeEntry(taskID int, req types.StopTimeEntryRequest) (*types.TimeEntry, error) {
+	return s.StopTimeEntryAt(taskID, req, time.Now())
+}
+
+// StopTimeEntryAt stops an active time entry for a task at the given time
+func (s *Storage) StopTimeEntryAt(taskID int, req types.StopTimeEntryRequest, now time.Time) (*types.TimeEntry, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
 	// Get the active time entry for this task
-	activeEntry, err := s.getActiveTimeEntry(taskID)
+	activeEntry, err := getActiveTimeEntry(tx, taskID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("no active time entry found for task %d", taskID)
@@ -122,22 +215,59 @@ func (s *Storage) StopTimeEntry(taskID int, req types.StopTimeEntryRequest) (*ty
 		return nil, fmt.Errorf("failed to get active time entry: %w", err)
 	}
 
-	now := time.Now()
-	duration := int(now.Sub(activeEntry.StartTime).Seconds())
-
 	// Update description if provided
 	description := activeEntry.Description
 	if req.Description != "" {
 		description = req.Description
 	}
 
+	if now.Before(activeEntry.StartTime) {
+		return nil, fmt.Errorf("end time cannot be before start time")
+	}
+
+	entry, err := stopTimeEntry(tx, activeEntry, description, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stopping a flagged entry by hand settles its review
+	if _, err := resolveTimeEntryReview(tx, entry.ID, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.raiseBudgetAlerts(tx, now, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit stop transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+// stopTimeEntry closes a running time entry at the given time using the given querier.
+// An open pause is closed as well and paused time is left out of the duration.
+// Changing an entry that was stopped before counts as a new revision of it.
+func stopTimeEntry(q querier, entry *types.TimeEntry, description string, now time.Time) (*types.TimeEntry, error) {
+	if err := closeOpenPause(q, entry.ID, now); err != nil {
+		return nil, err
+	}
+
+	pauses, err := getTimeEntryPauses(q, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := int(now.Sub(entry.StartTime).Seconds()) - pausedSeconds(pauses, entry.StartTime, now)
+
 	query := `UPDATE time_entries 
-			  SET end_time = ?, duration = ?, description = ? 
+			  SET end_time = ?, duration = ?, description = ?, updated_at = ?, revision = revision + (end_time IS NOT NULL)
 			  WHERE id = ?
 			  RETURNING id, task_id, start_time, end_time, duration, description, created_at`
 
 	var timeEntry types.TimeEntry
-	err = s.db.QueryRow(query, now, duration, description, activeEntry.ID).Scan(
+	err = q.QueryRow(query, now, duration, description, time.Now(), entry.ID).Scan(
 		&timeEntry.ID,
 		&timeEntry.TaskID,
 		&timeEntry.StartTime,
@@ -149,7 +279,7 @@ func (s *Storage) StopTimeEntry(taskID int, req types.StopTimeEntryRequest) (*ty
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("time entry with id %d not found", activeEntry.ID)
+			return nil, fmt.Errorf("time entry with id %d not found", entry.ID)
 		}
 		return nil, fmt.Errorf("failed to stop time entry: %w", err)
 	}
@@ -159,12 +289,17 @@ func (s *Storage) StopTimeEntry(taskID int, req types.StopTimeEntryRequest) (*ty
 
 // GetTimeEntry retrieves a time entry by ID
 func (s *Storage) GetTimeEntry(id int) (*types.TimeEntry, error) {
+	return getTimeEntry(s.db, id)
+}
+
+// getTimeEntry retrieves a time entry by ID using the given querier
+func getTimeEntry(q querier, id int) (*types.TimeEntry, error) {
 	query := `SELECT id, task_id, start_time, end_time, duration, description, created_at 
 			  FROM time_entries 
 			  WHERE id = ?`
 
 	var timeEntry types.TimeEntry
-	err := s.db.QueryRow(query, id).Scan(
+	err := q.QueryRow(query, id).Scan(
 		&timeEntry.ID,
 		&timeEntry.TaskID,
 		&timeEntry.StartTime,
@@ -186,77 +321,100 @@ func (s *Storage) GetTimeEntry(id int) (*types.TimeEntry, error) {
 
 // GetTimeEntriesByTask retrieves all time entries for a specific task
 func (s *Storage) GetTimeEntriesByTask(taskID int) ([]types.TimeEntry, error) {
-	// First verify that the task exists
-	taskExists, err := s.taskExists(taskID)
+	page, err := s.ListTimeEntriesByTask(taskID, types.PageRequest{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify task existence: %w", err)
-	}
-	if !taskExists {
-		return nil, fmt.Errorf("task with id %d does not exist", taskID)
+		return nil, err
 	}
+	return page.Items, nil
+}
 
-	query := `SELECT id, task_id, start_time, end_time, duration, description, created_at 
-			  FROM time_entries 
-			  WHERE task_id = ? 
-			  ORDER BY start_time DESC`
-
-	rows, err := s.db.Query(query, taskID)
+// ListTimeEntriesByTask retrieves one page of a task's time entries, newest first
+func (s *Storage) ListTimeEntriesByTask(taskID int, page types.PageRequest) (types.Page[types.TimeEntry], error) {
+	// First verify that the task exists
+	exists, err := taskExists(s.db, taskID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query time entries: %w", err)
+		return types.Page[types.TimeEntry]{}, fmt.Errorf("failed to verify task existence: %w", err)
 	}
-	defer rows.Close()
-
-	timeEntries := []types.TimeEntry{}
-	for rows.Next() {
-		var timeEntry types.TimeEntry
-		err := rows.Scan(
-			&timeEntry.ID,
-			&timeEntry.TaskID,
-			&timeEntry.StartTime,
-			&timeEntry.EndTime,
-			&timeEntry.Duration,
-			&timeEntry.Description,
-			&timeEntry.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan time entry: %w", err)
-		}
-		timeEntries = append(timeEntries, timeEntry)
+	if !exists {
+		return types.Page[types.TimeEntry]{}, fmt.Errorf("task with id %d does not exist", taskID)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading time entry rows: %w", err)
-	}
+	return s.listTimeEntries(`te.task_id = ?`, []interface{}{taskID}, page)
+}
 
-	return timeEntries, nil
+// IterateTimeEntriesByTask calls fn for every time entry of a task, newest first, without loading them all into memory
+func (s *Storage) IterateTimeEntriesByTask(taskID int, fn func(types.TimeEntry) error) error {
+	return iteratePages(func(cursor string) (types.Page[types.TimeEntry], error) {
+		return s.ListTimeEntriesByTask(taskID, types.PageRequest{Limit: iterateBatchSize, Cursor: cursor})
+	}, fn)
 }
 
 // GetTimeEntriesByProject retrieves all time entries for tasks in a specific project
 func (s *Storage) GetTimeEntriesByProject(projectID int) ([]types.TimeEntry, error) {
+	page, err := s.ListTimeEntriesByProject(projectID, types.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// ListTimeEntriesByProject retrieves one page of the time entries for tasks in a project, newest first
+func (s *Storage) ListTimeEntriesByProject(projectID int, page types.PageRequest) (types.Page[types.TimeEntry], error) {
 	// First verify that the project exists
 	projectExists, err := s.projectExists(projectID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify project existence: %w", err)
+		return types.Page[types.TimeEntry]{}, fmt.Errorf("failed to verify project existence: %w", err)
 	}
 	if !projectExists {
-		return nil, fmt.Errorf("project with id %d does not exist", projectID)
+		return types.Page[types.TimeEntry]{}, fmt.Errorf("project with id %d does not exist", projectID)
+	}
+
+	return s.listTimeEntries(`te.task_id IN (SELECT id FROM tasks WHERE project_id = ?)`, []interface{}{projectID}, page)
+}
+
+// IterateTimeEntriesByProject calls fn for every time entry in a project, newest first, without loading them all into memory
+func (s *Storage) IterateTimeEntriesByProject(projectID int, fn func(types.TimeEntry) error) error {
+	return iteratePages(func(cursor string) (types.Page[types.TimeEntry], error) {
+		return s.ListTimeEntriesByProject(projectID, types.PageRequest{Limit: iterateBatchSize, Cursor: cursor})
+	}, fn)
+}
+
+// listTimeEntries retrieves one page of time entries matching a SQL condition, newest first
+func (s *Storage) listTimeEntries(condition string, conditionArgs []interface{}, page types.PageRequest) (types.Page[types.TimeEntry], error) {
+	if err := validatePageRequest(page); err != nil {
+		return types.Page[types.TimeEntry]{}, err
 	}
 
-	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at 
+	cursor, err := decodeCursor(page.Cursor, 1)
+	if err != nil {
+		return types.Page[types.TimeEntry]{}, err
+	}
+
+	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at, CAST(te.start_time AS TEXT) 
 			  FROM time_entries te
-			  JOIN tasks t ON te.task_id = t.id
-			  WHERE t.project_id = ? 
-			  ORDER BY te.start_time DESC`
+			  WHERE ` + condition
+	args := append([]interface{}{}, conditionArgs...)
+	if cursor != nil {
+		query += ` AND (te.start_time < ? OR (te.start_time = ? AND te.id < ?))`
+		args = append(args, cursor.Sort[0], cursor.Sort[0], cursor.ID)
+	}
+	query += ` ORDER BY te.start_time DESC, te.id DESC`
+	if page.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, page.Limit+1) // One extra row tells us whether another page exists
+	}
 
-	rows, err := s.db.Query(query, projectID)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query time entries: %w", err)
+		return types.Page[types.TimeEntry]{}, fmt.Errorf("failed to query time entries: %w", err)
 	}
 	defer rows.Close()
 
 	timeEntries := []types.TimeEntry{}
+	var cursors []pageCursor
 	for rows.Next() {
 		var timeEntry types.TimeEntry
+		var startTimeKey string
 		err := rows.Scan(
 			&timeEntry.ID,
 			&timeEntry.TaskID,
@@ -265,81 +423,88 @@ func (s *Storage) GetTimeEntriesByProject(projectID int) ([]types.TimeEntry, err
 			&timeEntry.Duration,
 			&timeEntry.Description,
 			&timeEntry.CreatedAt,
+			&startTimeKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan time entry: %w", err)
+			return types.Page[types.TimeEntry]{}, fmt.Errorf("failed to scan time entry: %w", err)
 		}
 		timeEntries = append(timeEntries, timeEntry)
+		cursors = append(cursors, pageCursor{Sort: []string{startTimeKey}, ID: timeEntry.ID})
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading time entry rows: %w", err)
+		return types.Page[types.TimeEntry]{}, fmt.Errorf("error reading time entry rows: %w", err)
 	}
 
-	return timeEntries, nil
+	result := finishPage(timeEntries, cursors, page.Limit)
+	if page.IncludeTotal {
+		result.Total, err = s.countRows(`SELECT COUNT(*) FROM time_entries te WHERE `+condition, conditionArgs...)
+		if err != nil {
+			return types.Page[types.TimeEntry]{}, err
+		}
+	}
+
+	return result, nil
 }
 
 // GetActiveTimeEntry retrieves the active time entry for a task (if any)
 func (s *Storage) GetActiveTimeEntry(taskID int) (*types.TimeEntry, error) {
-	return s.getActiveTimeEntry(taskID)
+	return getActiveTimeEntry(s.db, taskID)
 }
 
 // UpdateTimeEntry updates an existing time entry
 func (s *Storage) UpdateTimeEntry(id int, req types.CreateTimeEntryRequest) (*types.TimeEntry, error) {
-	// Verify the time entry exists
-	_, err := s.GetTimeEntry(id)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	// Verify the time entry exists and has not been invoiced
+	billing, err := getTimeEntryBilling(tx, id)
 	if err != nil {
 		return nil, err
 	}
+	if billing.InvoiceID != nil {
+		return nil, fmt.Errorf("time entry %d is invoiced and cannot be changed", id)
+	}
 
 	// Verify the task exists
-	taskExists, err := s.taskExists(req.TaskID)
+	exists, err := taskExists(tx, req.TaskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify task existence: %w", err)
 	}
-	if !taskExists {
+	if !exists {
 		return nil, fmt.Errorf("task with id %d does not exist", req.TaskID)
 	}
 
-	// Validate business logic for times (but skip overlap check since we'll do it separately)
-	now := time.Now()
-	if req.StartTime.After(now.Add(5 * time.Minute)) {
-		return nil, fmt.Errorf("start time cannot be in the future")
-	}
-
-	thirtyDaysAgo := now.AddDate(0, 0, -30)
-	if req.StartTime.Before(thirtyDaysAgo) {
-		return nil, fmt.Errorf("start time cannot be more than 30 days in the past")
+	// Validate the configured rules (but skip overlap check since we'll do it separately)
+	rules, err := s.timeEntryRulesForTask(tx, req.TaskID)
+	if err != nil {
+		return nil, err
 	}
-
-	if req.EndTime != nil {
-		if req.EndTime.Before(req.StartTime) {
-			return nil, fmt.Errorf("end time cannot be before start time")
-		}
-
-		duration := req.EndTime.Sub(req.StartTime)
-		if duration > 24*time.Hour {
-			return nil, fmt.Errorf("time entry duration cannot exceed 24 hours")
-		}
-
-		if duration < time.Minute {
-			return nil, fmt.Errorf("time entry duration must be at least 1 minute")
-		}
-
-		if req.EndTime.After(now.Add(5 * time.Minute)) {
-			return nil, fmt.Errorf("end time cannot be in the future")
-		}
+	now := time.Now()
+	if err := checkTimeEntryRules(rules, req.StartTime, req.EndTime, now, req.Override); err != nil {
+		return nil, err
 	}
 
 	// Check for overlapping entries (excluding the current entry being updated)
-	if err := s.validateNoOverlappingTimeEntries(req.TaskID, req.StartTime, req.EndTime, id); err != nil {
+	if err := checkNoOverlappingTimeEntries(tx, req.TaskID, req.StartTime, req.EndTime, id); err != nil {
+		return nil, err
+	}
+	if err := s.validateNoCrossTaskOverlap(tx, req.TaskID, req.StartTime, req.EndTime, id); err != nil {
 		return nil, err
 	}
 
-	// Calculate duration if both start and end times are provided
+	// Calculate duration if both start and end times are provided, leaving out paused time
 	var duration *int
 	if req.EndTime != nil {
-		durationSeconds := int(req.EndTime.Sub(req.StartTime).Seconds())
+		pauses, err := getTimeEntryPauses(tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		durationSeconds := int(req.EndTime.Sub(req.StartTime).Seconds()) - pausedSeconds(pauses, req.StartTime, *req.EndTime)
 		if durationSeconds < 0 {
 			return nil, fmt.Errorf("end time cannot be before start time")
 		}
@@ -347,12 +512,13 @@ func (s *Storage) UpdateTimeEntry(id int, req types.CreateTimeEntryRequest) (*ty
 	}
 
 	query := `UPDATE time_entries 
-			  SET task_id = ?, start_time = ?, end_time = ?, duration = ?, description = ? 
+			  SET task_id = ?, start_time = ?, end_time = ?, duration = ?, description = ?, updated_at = ?,
+			      revision = revision + (end_time IS NOT NULL)
 			  WHERE id = ?
 			  RETURNING id, task_id, start_time, end_time, duration, description, created_at`
 
 	var timeEntry types.TimeEntry
-	err = s.db.QueryRow(query, req.TaskID, req.StartTime, req.EndTime, duration, req.Description, id).Scan(
+	err = tx.QueryRow(query, req.TaskID, req.StartTime, req.EndTime, duration, req.Description, now, id).Scan(
 		&timeEntry.ID,
 		&timeEntry.TaskID,
 		&timeEntry.StartTime,
@@ -369,21 +535,31 @@ func (s *Storage) UpdateTimeEntry(id int, req types.CreateTimeEntryRequest) (*ty
 		return nil, fmt.Errorf("failed to update time entry: %w", err)
 	}
 
+	// Correcting a reconciled entry settles its review
+	if _, err := resolveTimeEntryReview(tx, id, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.raiseBudgetAlerts(tx, now, req.TaskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit update transaction: %w", err)
+	}
+
 	return &timeEntry, nil
 }
 
 // DeleteTimeEntry deletes a time entry
 func (s *Storage) DeleteTimeEntry(id int) error {
-	// Check if time entry exists first
-	var exists bool
-	checkQuery := `SELECT EXISTS(SELECT 1 FROM time_entries WHERE id = ?)`
-	err := s.db.QueryRow(checkQuery, id).Scan(&exists)
+	// Check if time entry exists first and has not been invoiced
+	billing, err := getTimeEntryBilling(s.db, id)
 	if err != nil {
-		return fmt.Errorf("failed to check time entry existence: %w", err)
+		return err
 	}
-
-	if !exists {
-		return fmt.Errorf("time entry with id %d not found", id)
+	if billing.InvoiceID != nil {
+		return fmt.Errorf("time entry %d is invoiced and cannot be deleted", id)
 	}
 
 	// Delete the time entry
@@ -406,13 +582,13 @@ func (s *Storage) DeleteTimeEntry(id int) error {
 }
 
 // GetTaskTimeStatistics returns time statistics for a task
-func (s *Storage) GetTaskTimeStatistics(taskID int) (map[string]interface{}, error) {
+func (s *Storage) GetTaskTimeStatistics(taskID int) (*types.TaskTimeStatistics, error) {
 	// First verify that the task exists
-	taskExists, err := s.taskExists(taskID)
+	exists, err := taskExists(s.db, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify task existence: %w", err)
 	}
-	if !taskExists {
+	if !exists {
 		return nil, fmt.Errorf("task with id %d does not exist", taskID)
 	}
 
@@ -425,12 +601,12 @@ func (s *Storage) GetTaskTimeStatistics(taskID int) (map[string]interface{}, err
 	FROM time_entries 
 	WHERE task_id = ? AND end_time IS NOT NULL`
 
-	var totalEntries int
 	var totalDuration, avgDuration float64
 	var firstEntry, lastEntry sql.NullString
+	stats := &types.TaskTimeStatistics{TaskID: taskID}
 
 	err = s.db.QueryRow(query, taskID).Scan(
-		&totalEntries,
+		&stats.TotalEntries,
 		&totalDuration,
 		&avgDuration,
 		&firstEntry,
@@ -441,40 +617,41 @@ func (s *Storage) GetTaskTimeStatistics(taskID int) (map[string]interface{}, err
 		return nil, fmt.Errorf("failed to get time statistics: %w", err)
 	}
 
-	// Parse the datetime strings to time.Time if they exist
-	var firstTime, lastTime *time.Time
+	stats.TotalDuration = int(totalDuration)
+	stats.AvgDuration = int(avgDuration)
+
+	// Aggregates come back as text, so parse the datetime strings to time.Time if they exist
 	if firstEntry.Valid {
-		if parsed, err := time.Parse("2006-01-02 15:04:05", firstEntry.String); err == nil {
-			firstTime = &parsed
-		}
+		stats.FirstEntry = parseStoredTime(firstEntry.String)
 	}
 	if lastEntry.Valid {
-		if parsed, err := time.Parse("2006-01-02 15:04:05", lastEntry.String); err == nil {
-			lastTime = &parsed
-		}
-	}
-
-	stats := map[string]interface{}{
-		"total_entries":  totalEntries,
-		"total_duration": int(totalDuration),
-		"avg_duration":   int(avgDuration),
-		"first_entry":    firstTime,
-		"last_entry":     lastTime,
+		stats.LastEntry = parseStoredTime(lastEntry.String)
 	}
 
 	return stats, nil
 }
 
+// parseStoredTime parses a timestamp in any of the formats the SQLite driver writes, or returns nil
+func parseStoredTime(value string) *time.Time {
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if parsed, err := time.Parse(format, value); err == nil {
+			parsed = parsed.UTC()
+			return &parsed
+		}
+	}
+	return nil
+}
+
 // Helper function to check if a task exists
-func (s *Storage) taskExists(taskID int) (bool, error) {
+func taskExists(q querier, taskID int) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM tasks WHERE id = ?)`
 	var exists bool
-	err := s.db.QueryRow(query, taskID).Scan(&exists)
+	err := q.QueryRow(query, taskID).Scan(&exists)
 	return exists, err
 }
 
 // Helper function to get active time entry for a task
-func (s *Storage) getActiveTimeEntry(taskID int) (*types.TimeEntry, error) {
+func getActiveTimeEntry(q querier, taskID int) (*types.TimeEntry, error) {
 	query := `SELECT id, task_id, start_time, end_time, duration, description, created_at 
 			  FROM time_entries 
 			  WHERE task_id = ? AND end_time IS NULL 
@@ -482,7 +659,7 @@ func (s *Storage) getActiveTimeEntry(taskID int) (*types.TimeEntry, error) {
 			  LIMIT 1`
 
 	var timeEntry types.TimeEntry
-	err := s.db.QueryRow(query, taskID).Scan(
+	err := q.QueryRow(query, taskID).Scan(
 		&timeEntry.ID,
 		&timeEntry.TaskID,
 		&timeEntry.StartTime,
@@ -499,46 +676,19 @@ func (s *Storage) getActiveTimeEntry(taskID int) (*types.TimeEntry, error) {
 	return &timeEntry, nil
 }
 
-// validateTimeEntryBusinessLogic validates business rules for time entries
-func (s *Storage) validateTimeEntryBusinessLogic(taskID int, startTime time.Time, endTime *time.Time) error {
-	// Validate start time is not in the future (allow 5 minute buffer for clock skew)
-	now := time.Now()
-	if startTime.After(now.Add(5 * time.Minute)) {
-		return fmt.Errorf("start time cannot be in the future")
-	}
-
-	// Validate start time is not too far in the past (more than 30 days)
-	thirtyDaysAgo := now.AddDate(0, 0, -30)
-	if startTime.Before(thirtyDaysAgo) {
-		return fmt.Errorf("start time cannot be more than 30 days in the past")
+// validateTimeEntryBusinessLogic validates a time entry against its project's rules as of now and against
+// other entries. An override skips the configurable rules but not the overlap check.
+func (s *Storage) validateTimeEntryBusinessLogic(q querier, taskID int, startTime time.Time, endTime *time.Time, now time.Time, override bool) error {
+	rules, err := s.timeEntryRulesForTask(q, taskID)
+	if err != nil {
+		return err
 	}
-
-	// Validate end time if provided
-	if endTime != nil {
-		// End time cannot be before start time
-		if endTime.Before(startTime) {
-			return fmt.Errorf("end time cannot be before start time")
-		}
-
-		// Duration cannot be more than 24 hours
-		duration := endTime.Sub(startTime)
-		if duration > 24*time.Hour {
-			return fmt.Errorf("time entry duration cannot exceed 24 hours")
-		}
-
-		// Duration must be at least 1 minute
-		if duration < time.Minute {
-			return fmt.Errorf("time entry duration must be at least 1 minute")
-		}
-
-		// End time cannot be in the future
-		if endTime.After(now.Add(5 * time.Minute)) {
-			return fmt.Errorf("end time cannot be in the future")
-		}
+	if err := checkTimeEntryRules(rules, startTime, endTime, now, override); err != nil {
+		return err
 	}
 
 	// Check for overlapping time entries
-	if err := s.validateNoOverlappingTimeEntries(taskID, startTime, endTime, 0); err != nil {
+	if err := checkNoOverlappingTimeEntries(q, taskID, startTime, endTime, 0); err != nil {
 		return err
 	}
 
@@ -546,8 +696,8 @@ func (s *Storage) validateTimeEntryBusinessLogic(taskID int, startTime time.Time
 }
 
 // validateTaskTrackable checks if a task is in a state that allows time tracking
-func (s *Storage) validateTaskTrackable(taskID int) error {
-	task, err := s.GetTask(taskID)
+func validateTaskTrackable(q querier, taskID int) error {
+	task, err := getTask(q, taskID)
 	if err != nil {
 		return fmt.Errorf("failed to get task: %w", err)
 	}
@@ -563,16 +713,19 @@ func (s *Storage) validateTaskTrackable(taskID int) error {
 	return nil
 }
 
-// validateNoOverlappingTimeEntries checks for overlapping time entries for the same task
-func (s *Storage) validateNoOverlappingTimeEntries(taskID int, startTime time.Time, endTime *time.Time, excludeEntryID int) error {
+// checkNoOverlappingTimeEntries checks for overlapping time entries for the same task using the given querier,
+// so changes made earlier in a transaction are taken into account
+func checkNoOverlappingTimeEntries(q querier, taskID int, startTime time.Time, endTime *time.Time, excludeEntryID int) error {
 	// If no end time, only check if there's another active entry
 	if endTime == nil {
-		activeEntry, err := s.getActiveTimeEntry(taskID)
+		var activeID int
+		err := q.QueryRow(`SELECT id FROM time_entries WHERE task_id = ? AND end_time IS NULL AND id != ? LIMIT 1`,
+			taskID, excludeEntryID).Scan(&activeID)
 		if err != nil && err != sql.ErrNoRows {
 			return fmt.Errorf("failed to check for active time entry: %w", err)
 		}
-		if activeEntry != nil && activeEntry.ID != excludeEntryID {
-			return fmt.Errorf("task already has an active time entry")
+		if err == nil {
+			return newRuleError(types.TimeEntryRuleOverlap, "task already has an active time entry")
 		}
 		return nil
 	}
@@ -587,7 +740,7 @@ func (s *Storage) validateNoOverlappingTimeEntries(taskID int, startTime time.Ti
 				  (start_time >= ? AND start_time < ?)
 			  )`
 
-	rows, err := s.db.Query(query, taskID, excludeEntryID,
+	rows, err := q.Query(query, taskID, excludeEntryID,
 		startTime, startTime,
 		*endTime, *endTime,
 		startTime, *endTime)
@@ -597,8 +750,39 @@ func (s *Storage) validateNoOverlappingTimeEntries(taskID int, startTime time.Ti
 	defer rows.Close()
 
 	if rows.Next() {
-		return fmt.Errorf("time entry overlaps with existing time entry for this task")
+		return newRuleError(types.TimeEntryRuleOverlap, "time entry overlaps with existing time entry for this task")
 	}
 
 	return nil
 }
+
+// validateNoCrossTaskOverlap enforces the global no-overlap policy when it is enabled
+func (s *Storage) validateNoCrossTaskOverlap(q querier, taskID int, startTime time.Time, endTime *time.Time, excludeEntryID int) error {
+	if !s.noOverlap {
+		return nil
+	}
+	return checkNoCrossTaskOverlap(q, taskID, startTime, endTime, excludeEntryID)
+}
+
+// checkNoCrossTaskOverlap checks that a time entry does not overlap an entry on any other task.
+// Running entries, including the checked one when it has no end time, are treated as open-ended.
+func checkNoCrossTaskOverlap(q querier, taskID int, startTime time.Time, endTime *time.Time, excludeEntryID int) error {
+	query := `SELECT id, task_id
+			  FROM time_entries
+			  WHERE task_id != ? AND id != ?
+			  AND (end_time IS NULL OR end_time > ?)
+			  AND (? IS NULL OR start_time < ?)
+			  ORDER BY start_time ASC
+			  LIMIT 1`
+
+	var otherID, otherTaskID int
+	err := q.QueryRow(query, taskID, excludeEntryID, startTime, endTime, endTime).Scan(&otherID, &otherTaskID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check for overlapping time entries on other tasks: %w", err)
+	}
+
+	return newRuleError(types.TimeEntryRuleCrossOverlap, "time entry overlaps with time entry %d on task %d", otherID, otherTaskID)
+}
@@ -532,14 +532,13 @@ func TestGetTaskTimeStatistics(t *testing.T) {
 		t.Fatalf("Failed to get time statistics: %v", err)
 	}
 
-	totalEntries, ok := stats["total_entries"].(int)
-	if !ok || totalEntries != 3 {
-		t.Errorf("Expected 3 total entries, got %v", stats["total_entries"])
+	if stats.TotalEntries != 3 {
+		t.Errorf("Expected 3 total entries, got %d", stats.TotalEntries)
 	}
 
-	totalDuration, ok := stats["total_duration"].(int)
-	if !ok || totalDuration == 0 {
-		t.Errorf("Expected non-zero total duration, got %v", stats["total_duration"])
+	totalDuration := stats.TotalDuration
+	if totalDuration == 0 {
+		t.Errorf("Expected non-zero total duration, got %d", totalDuration)
 	}
 
 	// Verify total duration is sum of individual durations (in seconds)
@@ -547,6 +546,10 @@ func TestGetTaskTimeStatistics(t *testing.T) {
 	if totalDuration != expectedTotal {
 		t.Errorf("Expected total duration %d seconds, got %d", expectedTotal, totalDuration)
 	}
+
+	if stats.FirstEntry == nil || !stats.FirstEntry.Equal(baseTime) {
+		t.Errorf("Expected first entry at %v, got %v", baseTime, stats.FirstEntry)
+	}
 }
 
 func TestValidateTaskTrackable(t *testing.T) {
@@ -557,7 +560,7 @@ func TestValidateTaskTrackable(t *testing.T) {
 	task := createTestTask(t, s, project.ID)
 
 	// Test with pending task (should be trackable)
-	err := s.validateTaskTrackable(task.ID)
+	err := validateTaskTrackable(s.db, task.ID)
 	if err != nil {
 		t.Errorf("Pending task should be trackable, got error: %v", err)
 	}
@@ -569,7 +572,7 @@ func TestValidateTaskTrackable(t *testing.T) {
 	}
 
 	// Test with completed task (should not be trackable)
-	err = s.validateTaskTrackable(task.ID)
+	err = validateTaskTrackable(s.db, task.ID)
 	if err == nil {
 		t.Errorf("Completed task should not be trackable")
 	}
@@ -581,7 +584,7 @@ func TestValidateTaskTrackable(t *testing.T) {
 	}
 
 	// Test with cancelled task (should not be trackable)
-	err = s.validateTaskTrackable(task.ID)
+	err = validateTaskTrackable(s.db, task.ID)
 	if err == nil {
 		t.Errorf("Cancelled task should not be trackable")
 	}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// maxTimeOffDays is the longest a single time off entry may last
+const maxTimeOffDays = 366
+
+// CreateTimeOff adds holidays or leave covering whole days from the start to the end date inclusive
+func (s *Storage) CreateTimeOff(req types.CreateTimeOffRequest) (*types.TimeOff, error) {
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q: must be YYYY-MM-DD", req.StartDate)
+	}
+	end := start
+	if req.EndDate != "" {
+		end, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date %q: must be YYYY-MM-DD", req.EndDate)
+		}
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if end.Sub(start) >= maxTimeOffDays*24*time.Hour {
+		return nil, fmt.Errorf("time off must not be longer than %d days", maxTimeOffDays)
+	}
+
+	timeOff := &types.TimeOff{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		Note:      req.Note,
+		CreatedAt: time.Now(),
+	}
+	err = s.db.QueryRow(`INSERT INTO time_off (start_date, end_date, note, created_at) VALUES (?, ?, ?, ?) RETURNING id`,
+		timeOff.StartDate, timeOff.EndDate, timeOff.Note, timeOff.CreatedAt).Scan(&timeOff.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create time off: %w", err)
+	}
+
+	return timeOff, nil
+}
+
+// GetTimeOff returns the time off overlapping the dates from and until inclusive, ordered by start date.
+// Empty bounds are open.
+func (s *Storage) GetTimeOff(from, until string) ([]types.TimeOff, error) {
+	query := `SELECT id, start_date, end_date, note, created_at
+			  FROM time_off
+			  WHERE (? = '' OR end_date >= ?) AND (? = '' OR start_date <= ?)
+			  ORDER BY start_date ASC, id ASC`
+
+	rows, err := s.db.Query(query, from, from, until, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time off: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []types.TimeOff{}
+	for rows.Next() {
+		var timeOff types.TimeOff
+		if err := rows.Scan(&timeOff.ID, &timeOff.StartDate, &timeOff.EndDate, &timeOff.Note, &timeOff.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan time off: %w", err)
+		}
+		entries = append(entries, timeOff)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading time off rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteTimeOff deletes a time off entry
+func (s *Storage) DeleteTimeOff(id int) error {
+	result, err := s.db.Exec(`DELETE FROM time_off WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete time off: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("time off with id %d not found", id)
+	}
+
+	return nil
+}
+
+// workingTime returns the working periods within [from, until) in the calendar timezone, leaving out days off
+func (s *Storage) workingTime(from, until time.Time) ([]interval, error) {
+	off, err := s.timeOffDates(from, until)
+	if err != nil {
+		return nil, err
+	}
+	return workingIntervals(from, until, s.loc, s.hours, off), nil
+}
+
+// timeOffDates returns the dates, as YYYY-MM-DD, within [from, until) in the calendar timezone that are taken off
+func (s *Storage) timeOffDates(from, until time.Time) (map[string]bool, error) {
+	entries, err := s.GetTimeOff(startOfDay(from, s.loc).Format("2006-01-02"), until.In(s.loc).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	off := make(map[string]bool)
+	for _, timeOff := range entries {
+		day, _ := time.Parse("2006-01-02", timeOff.StartDate)
+		for date := timeOff.StartDate; date <= timeOff.EndDate; date = day.Format("2006-01-02") {
+			off[date] = true
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	return off, nil
+}
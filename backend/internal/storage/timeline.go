@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// timelineEntry is a time entry with the task and project details shown on the timeline
+type timelineEntry struct {
+	entry        types.TimeEntry
+	taskTitle    string
+	projectID    int
+	projectName  string
+	projectColor string
+	billable     bool
+	pauses       []types.TimeEntryPause
+}
+
+// span returns the part of the entry within [from, until), counting a running entry up to now.
+// ok is false when the entry does not reach into the range.
+func (te timelineEntry) span(from, until, now time.Time) (start, end time.Time, ok bool) {
+	start, end = te.entry.StartTime, now
+	if te.entry.EndTime != nil {
+		end = *te.entry.EndTime
+	}
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(until) {
+		end = until
+	}
+	return start, end, end.After(start)
+}
+
+// trackedSeconds returns the time tracked within [start, end), leaving out paused time
+func (te timelineEntry) trackedSeconds(start, end time.Time) int {
+	return int(end.Sub(start).Seconds()) - pausedSeconds(te.pauses, start, end)
+}
+
+// GetWeekTimeline returns the time tracked during the week starting at weekStart.
+// Day boundaries follow weekStart's location, entries crossing midnight are split
+// per day and running entries are counted up to now. Imported calendar blocks are
+// listed on each day they overlap.
+func (s *Storage) GetWeekTimeline(weekStart time.Time, now time.Time) (*types.Timeline, error) {
+	loc := weekStart.Location()
+	weekStart = startOfDay(weekStart, loc)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	entries, err := s.getTimelineEntries(weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &types.Timeline{
+		Week:     weekLabel(weekStart),
+		Timezone: loc.String(),
+		Start:    weekStart,
+		End:      weekEnd,
+		Days:     make([]types.TimelineDay, 7),
+		Totals:   []types.TimelineProject{},
+	}
+
+	dayProjects := make([]map[int]*types.TimelineProject, 7)
+	for i := range timeline.Days {
+		timeline.Days[i].Date = weekStart.AddDate(0, 0, i).Format("2006-01-02")
+		timeline.Days[i].Projects = []types.TimelineProject{}
+		timeline.Days[i].Blocks = []types.CalendarBlock{}
+		dayProjects[i] = make(map[int]*types.TimelineProject)
+	}
+	weekProjects := make(map[int]*types.TimelineProject)
+
+	for _, te := range entries {
+		for i := range timeline.Days {
+			segStart, segEnd, ok := te.span(weekStart.AddDate(0, 0, i), weekStart.AddDate(0, 0, i+1), now)
+			if !ok {
+				continue
+			}
+
+			segment := types.TimelineSegment{
+				TimeEntryID: te.entry.ID,
+				TaskID:      te.entry.TaskID,
+				TaskTitle:   te.taskTitle,
+				Start:       segStart.In(loc),
+				End:         segEnd.In(loc),
+				Duration:    te.trackedSeconds(segStart, segEnd),
+				Running:     te.entry.EndTime == nil,
+			}
+
+			project, ok := dayProjects[i][te.projectID]
+			if !ok {
+				project = &types.TimelineProject{ProjectID: te.projectID, Name: te.projectName, Color: te.projectColor}
+				dayProjects[i][te.projectID] = project
+			}
+			project.Segments = append(project.Segments, segment)
+			project.Total += segment.Duration
+			timeline.Days[i].Total += segment.Duration
+
+			total, ok := weekProjects[te.projectID]
+			if !ok {
+				total = &types.TimelineProject{ProjectID: te.projectID, Name: te.projectName, Color: te.projectColor}
+				weekProjects[te.projectID] = total
+			}
+			total.Total += segment.Duration
+			timeline.Total += segment.Duration
+		}
+	}
+
+	for i := range timeline.Days {
+		timeline.Days[i].Projects = sortTimelineProjects(dayProjects[i])
+	}
+
+	// Imported meetings are shown per day with the time they block, which is not tracked time
+	blocks, err := s.GetCalendarBlocks(weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+	for i := range timeline.Days {
+		dayStart := weekStart.AddDate(0, 0, i)
+		dayEnd := weekStart.AddDate(0, 0, i+1)
+		for _, block := range blocks {
+			if block.StartTime.Before(dayEnd) && block.EndTime.After(dayStart) {
+				block.StartTime, block.EndTime = block.StartTime.In(loc), block.EndTime.In(loc)
+				timeline.Days[i].Blocks = append(timeline.Days[i].Blocks, block)
+			}
+		}
+		timeline.Days[i].Blocked = blockedSeconds(timeline.Days[i].Blocks, dayStart, dayEnd)
+	}
+	timeline.Totals = sortTimelineProjects(weekProjects)
+
+	return timeline, nil
+}
+
+// getTimelineEntries returns the time entries overlapping [from, until) with their task and project details
+// and pauses, loading the pauses of all entries in a single query
+func (s *Storage) getTimelineEntries(from, until time.Time) ([]timelineEntry, error) {
+	// Stored timestamps start with the date, so comparing against padded date strings
+	// narrows the scan; the exact overlap is checked per day afterwards
+	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at,
+			         t.title, p.id, p.name, p.color, te.billable
+			  FROM time_entries te
+			  JOIN tasks t ON te.task_id = t.id
+			  JOIN projects p ON t.project_id = p.id
+			  WHERE te.start_time < ? AND (te.end_time IS NULL OR te.end_time >= ?)
+			  ORDER BY te.start_time ASC, te.id ASC`
+
+	upper := until.AddDate(0, 0, 2).Format("2006-01-02")
+	lower := from.AddDate(0, 0, -2).Format("2006-01-02")
+
+	rows, err := s.db.Query(query, upper, lower)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []timelineEntry
+	for rows.Next() {
+		var te timelineEntry
+		err := rows.Scan(
+			&te.entry.ID,
+			&te.entry.TaskID,
+			&te.entry.StartTime,
+			&te.entry.EndTime,
+			&te.entry.Duration,
+			&te.entry.Description,
+			&te.entry.CreatedAt,
+			&te.taskTitle,
+			&te.projectID,
+			&te.projectName,
+			&te.projectColor,
+			&te.billable,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timeline entry: %w", err)
+		}
+		entries = append(entries, te)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading timeline entry rows: %w", err)
+	}
+
+	pauses, err := getPausesByEntry(s.db, `time_entry_id IN (
+				  SELECT id FROM time_entries WHERE start_time < ? AND (end_time IS NULL OR end_time >= ?))`, upper, lower)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].pauses = pauses[entries[i].entry.ID]
+	}
+
+	return entries, nil
+}
+
+// trackedSecondsByTask sums the time tracked per task within [from, until) in a single query, counting
+// running entries up to now and leaving out paused time. The condition filters entries as te and their
+// task as t. Pauses of an entry do not overlap, so their clipped lengths are simply added up.
+func trackedSecondsByTask(q querier, from, until, now time.Time, condition string, args ...interface{}) (map[int]int, error) {
+	query := `WITH clipped AS (
+				  SELECT te.id, te.task_id,
+				         MAX(julianday(te.start_time), julianday(?)) AS lo,
+				         MIN(julianday(COALESCE(te.end_time, ?)), julianday(?)) AS hi
+				  FROM time_entries te
+				  JOIN tasks t ON te.task_id = t.id
+				  WHERE te.start_time < ? AND (te.end_time IS NULL OR te.end_time > ?) AND (` + condition + `)
+			  )
+			  SELECT c.task_id, CAST(ROUND(SUM(c.hi - c.lo - COALESCE((
+			             SELECT SUM(MAX(0, MIN(julianday(COALESCE(p.resumed_at, ?)), c.hi) - MAX(julianday(p.paused_at), c.lo)))
+			             FROM time_entry_pauses p
+			             WHERE p.time_entry_id = c.id), 0)) * 86400) AS INTEGER)
+			  FROM clipped c
+			  WHERE c.hi > c.lo
+			  GROUP BY c.task_id`
+
+	queryArgs := append([]interface{}{from, now, until, until, from}, args...)
+	queryArgs = append(queryArgs, now)
+
+	rows, err := q.Query(query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum tracked time: %w", err)
+	}
+	defer rows.Close()
+
+	tracked := make(map[int]int)
+	for rows.Next() {
+		var taskID, seconds int
+		if err := rows.Scan(&taskID, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan tracked time: %w", err)
+		}
+		tracked[taskID] = seconds
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading tracked time rows: %w", err)
+	}
+
+	return tracked, nil
+}
+
+// sortTimelineProjects orders projects by tracked time, most first, then by name
+func sortTimelineProjects(projects map[int]*types.TimelineProject) []types.TimelineProject {
+	sorted := make([]types.TimelineProject, 0, len(projects))
+	for _, project := range projects {
+		sorted = append(sorted, *project)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Total != sorted[j].Total {
+			return sorted[i].Total > sorted[j].Total
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].ProjectID < sorted[j].ProjectID
+	})
+
+	return sorted
+}
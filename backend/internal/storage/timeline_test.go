@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestGetWeekTimelineSplitsAtMidnight(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	first := createTestProject(t, s)
+	second := createTestProject(t, s)
+	firstTask := createTestTask(t, s, first.ID)
+	secondTask := createTestTask(t, s, second.ID)
+
+	// Monday of last week in loc
+	lastWeek := startOfDay(time.Now().AddDate(0, 0, -7), loc)
+	weekStart := lastWeek.AddDate(0, 0, -(int(lastWeek.Weekday())+6)%7)
+
+	// Tuesday 22:00 to Wednesday 02:00 crosses midnight
+	crossStart := weekStart.AddDate(0, 0, 1).Add(22 * time.Hour)
+	crossEnd := crossStart.Add(4 * time.Hour)
+	_, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: firstTask.ID, StartTime: crossStart, EndTime: &crossEnd})
+	if err != nil {
+		t.Fatalf("Failed to create time entry: %v", err)
+	}
+
+	// Wednesday 10:00 to 11:00 on the second project
+	wedStart := weekStart.AddDate(0, 0, 2).Add(10 * time.Hour)
+	wedEnd := wedStart.Add(time.Hour)
+	_, err = s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: secondTask.ID, StartTime: wedStart, EndTime: &wedEnd})
+	if err != nil {
+		t.Fatalf("Failed to create time entry: %v", err)
+	}
+
+	timeline, err := s.GetWeekTimeline(weekStart, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to get timeline: %v", err)
+	}
+
+	if len(timeline.Days) != 7 {
+		t.Fatalf("Expected 7 days, got %d", len(timeline.Days))
+	}
+	if timeline.Days[0].Date != weekStart.Format("2006-01-02") {
+		t.Errorf("Expected week to start on %s, got %s", weekStart.Format("2006-01-02"), timeline.Days[0].Date)
+	}
+
+	tuesday, wednesday := timeline.Days[1], timeline.Days[2]
+	if tuesday.Total != 2*60*60 || len(tuesday.Projects) != 1 {
+		t.Errorf("Expected 2 hours on Tuesday, got %d seconds", tuesday.Total)
+	}
+	if wednesday.Total != 3*60*60 || len(wednesday.Projects) != 2 {
+		t.Fatalf("Expected 3 hours over 2 projects on Wednesday, got %d seconds", wednesday.Total)
+	}
+
+	// The midnight segment is clipped to the day boundary
+	segment := wednesday.Projects[0].Segments[0]
+	if !segment.Start.Equal(weekStart.AddDate(0, 0, 2)) || segment.TaskTitle != firstTask.Title {
+		t.Errorf("Expected Wednesday segment to start at midnight, got %v", segment.Start)
+	}
+	if wednesday.Projects[0].Color != first.Color {
+		t.Errorf("Expected project color %s, got %s", first.Color, wednesday.Projects[0].Color)
+	}
+
+	if timeline.Total != 5*60*60 || len(timeline.Totals) != 2 {
+		t.Fatalf("Expected 5 hours over 2 projects, got %d seconds", timeline.Total)
+	}
+	if timeline.Totals[0].ProjectID != first.ID || timeline.Totals[0].Total != 4*60*60 {
+		t.Errorf("Expected the first project to lead with 4 hours, got %d seconds", timeline.Totals[0].Total)
+	}
+}
+
+func TestGetWeekTimelineIncludesRunningTimer(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	entry := createDanglingEntry(t, s, task.ID, 10*time.Minute)
+
+	weekStart := startOfDay(entry.StartTime, time.Local)
+	weekStart = weekStart.AddDate(0, 0, -(int(weekStart.Weekday())+6)%7)
+
+	timeline, err := s.GetWeekTimeline(weekStart, entry.StartTime.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to get timeline: %v", err)
+	}
+
+	if timeline.Total != 10*60 {
+		t.Errorf("Expected 10 minutes of running time, got %d seconds", timeline.Total)
+	}
+
+	var running bool
+	for _, day := range timeline.Days {
+		for _, p := range day.Projects {
+			for _, segment := range p.Segments {
+				running = running || segment.Running
+			}
+		}
+	}
+	if !running {
+		t.Errorf("Expected a running segment")
+	}
+}
@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// GetRunningTimer returns the running time entry with its task and project, or nil when no timer runs.
+// If several entries run at once (global timer mode disabled) the most recently started one is returned.
+func (s *Storage) GetRunningTimer() (*types.ActiveTimer, error) {
+	return getRunningTimer(s.db, time.Now())
+}
+
+// PauseTimer pauses the running timer so that time stops accruing until it is resumed
+func (s *Storage) PauseTimer() (*types.ActiveTimer, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	now := time.Now()
+	timer, err := getRunningTimer(tx, now)
+	if err != nil {
+		return nil, err
+	}
+	if timer == nil {
+		return nil, fmt.Errorf("no running timer found")
+	}
+	if timer.Paused {
+		return nil, fmt.Errorf("timer is already paused")
+	}
+
+	query := `INSERT INTO time_entry_pauses (time_entry_id, paused_at) VALUES (?, ?)`
+	if _, err := tx.Exec(query, timer.TimeEntry.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to pause timer: %w", err)
+	}
+
+	timer, err = getRunningTimer(tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pause transaction: %w", err)
+	}
+
+	return timer, nil
+}
+
+// ResumeTimer resumes the paused running timer
+func (s *Storage) ResumeTimer() (*types.ActiveTimer, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	now := time.Now()
+	timer, err := getRunningTimer(tx, now)
+	if err != nil {
+		return nil, err
+	}
+	if timer == nil {
+		return nil, fmt.Errorf("no running timer found")
+	}
+	if !timer.Paused {
+		return nil, fmt.Errorf("timer is not paused")
+	}
+
+	if err := closeOpenPause(tx, timer.TimeEntry.ID, now); err != nil {
+		return nil, err
+	}
+
+	timer, err = getRunningTimer(tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit resume transaction: %w", err)
+	}
+
+	return timer, nil
+}
+
+// getRunningTimer loads the most recently started running timer and its pause state as of now
+func getRunningTimer(q querier, now time.Time) (*types.ActiveTimer, error) {
+	query := `SELECT te.id, te.task_id, te.start_time, te.end_time, te.duration, te.description, te.created_at,
+			         t.id, t.project_id, t.parent_id, t.title, t.description, t.status, t.priority, t.due_date, t.completed_at, t.created_at, t.updated_at,
+			         p.id, p.name, p.description, p.color, p.icon, p.created_at, p.updated_at
+			  FROM time_entries te
+			  JOIN tasks t ON te.task_id = t.id
+			  JOIN projects p ON t.project_id = p.id
+			  WHERE te.end_time IS NULL
+			  ORDER BY te.start_time DESC
+			  LIMIT 1`
+
+	var timer types.ActiveTimer
+	err := q.QueryRow(query).Scan(
+		&timer.TimeEntry.ID,
+		&timer.TimeEntry.TaskID,
+		&timer.TimeEntry.StartTime,
+		&timer.TimeEntry.EndTime,
+		&timer.TimeEntry.Duration,
+		&timer.TimeEntry.Description,
+		&timer.TimeEntry.CreatedAt,
+		&timer.Task.ID,
+		&timer.Task.ProjectID,
+		&timer.Task.ParentID,
+		&timer.Task.Title,
+		&timer.Task.Description,
+		&timer.Task.Status,
+		&timer.Task.Priority,
+		&timer.Task.DueDate,
+		&timer.Task.CompletedAt,
+		&timer.Task.CreatedAt,
+		&timer.Task.UpdatedAt,
+		&timer.Project.ID,
+		&timer.Project.Name,
+		&timer.Project.Description,
+		&timer.Project.Color,
+		&timer.Project.Icon,
+		&timer.Project.CreatedAt,
+		&timer.Project.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get running timer: %w", err)
+	}
+
+	pauses, err := getTimeEntryPauses(q, timer.TimeEntry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pause := range pauses {
+		if pause.ResumedAt == nil {
+			pausedAt := pause.PausedAt
+			timer.Paused = true
+			timer.PausedAt = &pausedAt
+		}
+	}
+
+	timer.PausedSeconds = pausedSeconds(pauses, timer.TimeEntry.StartTime, now)
+	timer.Elapsed = int(now.Sub(timer.TimeEntry.StartTime).Seconds()) - timer.PausedSeconds
+
+	timer.IdleSpans, err = getIdleSpans(q, "time_entry_id = ? AND resolution IS NULL", timer.TimeEntry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &timer, nil
+}
+
+// getTimeEntryPauses returns the pause intervals of a time entry in chronological order
+func getTimeEntryPauses(q querier, timeEntryID int) ([]types.TimeEntryPause, error) {
+	pauses, err := getPausesByEntry(q, "time_entry_id = ?", timeEntryID)
+	if err != nil {
+		return nil, err
+	}
+	return pauses[timeEntryID], nil
+}
+
+// getPausesByEntry loads the pauses matching the given condition in one query, keyed by time entry
+// and in chronological order
+func getPausesByEntry(q querier, condition string, args ...interface{}) (map[int][]types.TimeEntryPause, error) {
+	query := `SELECT id, time_entry_id, paused_at, resumed_at
+			  FROM time_entry_pauses
+			  WHERE ` + condition + `
+			  ORDER BY paused_at ASC`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time entry pauses: %w", err)
+	}
+	defer rows.Close()
+
+	pauses := make(map[int][]types.TimeEntryPause)
+	for rows.Next() {
+		var pause types.TimeEntryPause
+		if err := rows.Scan(&pause.ID, &pause.TimeEntryID, &pause.PausedAt, &pause.ResumedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan time entry pause: %w", err)
+		}
+		pauses[pause.TimeEntryID] = append(pauses[pause.TimeEntryID], pause)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading time entry pause rows: %w", err)
+	}
+
+	return pauses, nil
+}
+
+// closeOpenPause ends the open pause interval of a time entry, if any
+func closeOpenPause(q querier, timeEntryID int, now time.Time) error {
+	query := `UPDATE time_entry_pauses SET resumed_at = ? WHERE time_entry_id = ? AND resumed_at IS NULL`
+	if _, err := q.Exec(query, now, timeEntryID); err != nil {
+		return fmt.Errorf("failed to close pause: %w", err)
+	}
+	return nil
+}
+
+// pausedSeconds sums the paused time falling between from and until, counting time covered by
+// overlapping pauses once. Pauses that are still open are counted up to until.
+func pausedSeconds(pauses []types.TimeEntryPause, from, until time.Time) int {
+	return intervalSeconds(clipIntervals(pauseIntervals(pauses, until), from, until))
+}
+
+// pauseIntervals returns the merged spans covered by pauses, with open pauses ending at until
+func pauseIntervals(pauses []types.TimeEntryPause, until time.Time) []interval {
+	var paused []interval
+	for _, pause := range pauses {
+		resumed := until
+		if pause.ResumedAt != nil && pause.ResumedAt.Before(until) {
+			resumed = *pause.ResumedAt
+		}
+		paused = append(paused, interval{pause.PausedAt, resumed})
+	}
+	return mergeIntervals(paused)
+}
+
+// getRunningTimeEntries returns every time entry without an end time
+func getRunningTimeEntries(q querier) ([]types.TimeEntry, error) {
+	query := `SELECT id, task_id, start_time, end_time, duration, description, created_at
+			  FROM time_entries
+			  WHERE end_time IS NULL
+			  ORDER BY start_time DESC`
+
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query running time entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []types.TimeEntry
+	for rows.Next() {
+		var timeEntry types.TimeEntry
+		err := rows.Scan(
+			&timeEntry.ID,
+			&timeEntry.TaskID,
+			&timeEntry.StartTime,
+			&timeEntry.EndTime,
+			&timeEntry.Duration,
+			&timeEntry.Description,
+			&timeEntry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan running time entry: %w", err)
+		}
+		entries = append(entries, timeEntry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading running time entry rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// stopRunningTimeEntries stops every running time entry at the given time and returns the stopped entries.
+// Dangling entries awaiting review are left running: stopping them now would record the whole gap.
+func stopRunningTimeEntries(q querier, now time.Time) ([]types.TimeEntry, error) {
+	running, err := getRunningTimeEntries(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var stopped []types.TimeEntry
+	for i := range running {
+		flagged, err := hasOpenTimeEntryReview(q, running[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if flagged {
+			continue
+		}
+
+		entry, err := stopTimeEntry(q, &running[i], running[i].Description, now)
+		if err != nil {
+			return nil, err
+		}
+		stopped = append(stopped, *entry)
+	}
+
+	return stopped, nil
+}
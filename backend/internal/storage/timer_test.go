@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestGlobalTimerSwitching(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+
+	// No timer is running initially
+	timer, err := s.GetRunningTimer()
+	if err != nil {
+		t.Fatalf("Failed to get running timer: %v", err)
+	}
+	if timer != nil {
+		t.Errorf("Expected no running timer, got entry %d", timer.TimeEntry.ID)
+	}
+
+	started, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: first.ID})
+	if err != nil {
+		t.Fatalf("Failed to start first timer: %v", err)
+	}
+	if len(started.Stopped) != 0 {
+		t.Errorf("Expected no stopped entries, got %d", len(started.Stopped))
+	}
+
+	// Starting a second task stops the first one
+	switched, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: second.ID})
+	if err != nil {
+		t.Fatalf("Failed to start second timer: %v", err)
+	}
+	if len(switched.Stopped) != 1 || switched.Stopped[0].ID != started.ID {
+		t.Fatalf("Expected entry %d to be stopped, got %v", started.ID, switched.Stopped)
+	}
+	if switched.Stopped[0].EndTime == nil || switched.Stopped[0].Duration == nil {
+		t.Errorf("Expected stopped entry to have end time and duration")
+	}
+
+	timer, err = s.GetRunningTimer()
+	if err != nil {
+		t.Fatalf("Failed to get running timer: %v", err)
+	}
+	if timer == nil || timer.TimeEntry.ID != switched.ID {
+		t.Fatalf("Expected running timer %d", switched.ID)
+	}
+	if timer.Task.ID != second.ID || timer.Project.ID != project.ID {
+		t.Errorf("Expected running timer to embed task %d and project %d", second.ID, project.ID)
+	}
+}
+
+func TestGlobalTimerCreateRunningEntry(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+
+	started, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: first.ID})
+	if err != nil {
+		t.Fatalf("Failed to start first timer: %v", err)
+	}
+
+	// Creating an entry without an end time starts a timer, which stops the first one
+	created, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: second.ID, StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create running time entry: %v", err)
+	}
+
+	stopped, err := s.GetTimeEntry(started.ID)
+	if err != nil {
+		t.Fatalf("Failed to get time entry: %v", err)
+	}
+	if stopped.EndTime == nil {
+		t.Errorf("Expected the first timer to be stopped")
+	}
+
+	timer, err := s.GetRunningTimer()
+	if err != nil {
+		t.Fatalf("Failed to get running timer: %v", err)
+	}
+	if timer == nil || timer.TimeEntry.ID != created.ID {
+		t.Errorf("Expected the created entry to be the only running timer")
+	}
+}
+
+func TestGlobalTimerDisabled(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	s.SetGlobalTimer(false)
+
+	project := createTestProject(t, s)
+	first := createTestTask(t, s, project.ID)
+	second := createTestTask(t, s, project.ID)
+
+	if _, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: first.ID}); err != nil {
+		t.Fatalf("Failed to start first timer: %v", err)
+	}
+	switched, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: second.ID})
+	if err != nil {
+		t.Fatalf("Failed to start second timer: %v", err)
+	}
+	if len(switched.Stopped) != 0 {
+		t.Errorf("Expected no stopped entries with global timer disabled")
+	}
+
+	active, err := s.GetActiveTimeEntry(first.ID)
+	if err != nil || active == nil {
+		t.Errorf("Expected first timer to keep running: %v", err)
+	}
+}
+
+func TestStartTimeEntryAtUsesGivenTime(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	// The rules are checked as of the given time, so a start beyond the backdate limit of
+	// the wall clock is fine when that is the current time of the caller
+	at := time.Now().AddDate(0, 0, -40).Truncate(time.Second)
+	started, err := s.StartTimeEntryAt(types.StartTimeEntryRequest{TaskID: task.ID}, at)
+	if err != nil {
+		t.Fatalf("Failed to start timer at the given time: %v", err)
+	}
+	if !started.StartTime.Equal(at) {
+		t.Errorf("Expected the timer to start at %v, got %v", at, started.StartTime)
+	}
+}
+
+func TestPauseResumeTimer(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	// Pausing without a running timer fails
+	_, err := s.PauseTimer()
+	if err == nil || !contains(err.Error(), "no running timer") {
+		t.Errorf("Expected no running timer error, got %v", err)
+	}
+
+	if _, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: task.ID}); err != nil {
+		t.Fatalf("Failed to start timer: %v", err)
+	}
+
+	paused, err := s.PauseTimer()
+	if err != nil {
+		t.Fatalf("Failed to pause timer: %v", err)
+	}
+	if !paused.Paused || paused.PausedAt == nil {
+		t.Errorf("Expected timer to be paused")
+	}
+
+	_, err = s.PauseTimer()
+	if err == nil || !contains(err.Error(), "already paused") {
+		t.Errorf("Expected already paused error, got %v", err)
+	}
+
+	resumed, err := s.ResumeTimer()
+	if err != nil {
+		t.Fatalf("Failed to resume timer: %v", err)
+	}
+	if resumed.Paused {
+		t.Errorf("Expected timer to be running after resume")
+	}
+
+	_, err = s.ResumeTimer()
+	if err == nil || !contains(err.Error(), "not paused") {
+		t.Errorf("Expected not paused error, got %v", err)
+	}
+}
+
+func TestPausedTimeExcludedFromDuration(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	// A timer running for 30 minutes with a 10 minute pause in the middle
+	now := time.Now()
+	entry, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: now.Add(-30 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Failed to create running entry: %v", err)
+	}
+
+	_, err = s.db.Exec(`INSERT INTO time_entry_pauses (time_entry_id, paused_at, resumed_at) VALUES (?, ?, ?)`,
+		entry.ID, now.Add(-20*time.Minute), now.Add(-10*time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to insert pause: %v", err)
+	}
+
+	timer, err := s.GetRunningTimer()
+	if err != nil {
+		t.Fatalf("Failed to get running timer: %v", err)
+	}
+	if timer.PausedSeconds < 599 || timer.PausedSeconds > 601 {
+		t.Errorf("Expected about 600 paused seconds, got %d", timer.PausedSeconds)
+	}
+
+	stopped, err := s.StopTimeEntry(task.ID, types.StopTimeEntryRequest{})
+	if err != nil {
+		t.Fatalf("Failed to stop entry: %v", err)
+	}
+	if *stopped.Duration < 1195 || *stopped.Duration > 1205 {
+		t.Errorf("Expected about 1200 seconds excluding the pause, got %d", *stopped.Duration)
+	}
+
+	stats, err := s.GetTaskTimeStatistics(task.ID)
+	if err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+	if stats.TotalDuration != *stopped.Duration {
+		t.Errorf("Expected statistics to report %d seconds, got %d", *stopped.Duration, stats.TotalDuration)
+	}
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// utcDB wraps the database so every time argument is stored in UTC. The driver writes
+// times with their own offset, which would break ordering and date comparisons in SQL.
+type utcDB struct {
+	*sql.DB
+}
+
+// utcTx wraps a transaction so every time argument is stored in UTC
+type utcTx struct {
+	*sql.Tx
+}
+
+// Begin starts a transaction that stores time arguments in UTC
+func (db *utcDB) Begin() (*utcTx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &utcTx{Tx: tx}, nil
+}
+
+// Exec executes a query with its time arguments converted to UTC
+func (db *utcDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(query, utcArgs(args)...)
+}
+
+// Query runs a query with its time arguments converted to UTC
+func (db *utcDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(query, utcArgs(args)...)
+}
+
+// QueryRow runs a single row query with its time arguments converted to UTC
+func (db *utcDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(query, utcArgs(args)...)
+}
+
+// Exec executes a query with its time arguments converted to UTC
+func (tx *utcTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(query, utcArgs(args)...)
+}
+
+// Query runs a query with its time arguments converted to UTC
+func (tx *utcTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(query, utcArgs(args)...)
+}
+
+// QueryRow runs a single row query with its time arguments converted to UTC
+func (tx *utcTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(query, utcArgs(args)...)
+}
+
+// utcArgs returns args with every time and non-nil time pointer converted to UTC
+func utcArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC()
+			} else {
+				converted[i] = arg
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+// timestampColumns lists the timestamp columns written before times were stored in UTC
+var timestampColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"projects", []string{"created_at", "updated_at"}},
+	{"tasks", []string{"due_date", "completed_at", "created_at", "updated_at"}},
+	{"time_entries", []string{"start_time", "end_time", "last_heartbeat_at", "created_at"}},
+	{"time_entry_pauses", []string{"paused_at", "resumed_at"}},
+	{"time_entry_reviews", []string{"detected_at", "resolved_at"}},
+	{"time_entry_idle_spans", []string{"started_at", "ended_at", "resolved_at"}},
+	{"invoices", []string{"period_start", "period_end", "created_at"}},
+}
+
+// normalizeTimestampsToUTC rewrites every stored timestamp in UTC. The values are read and written back
+// through the driver, so they end up in exactly the text format of newly written rows and keep matching
+// equality lookups. Values the driver cannot parse are left as they are.
+func normalizeTimestampsToUTC(q querier) error {
+	for _, table := range timestampColumns {
+		for _, column := range table.columns {
+			if err := normalizeTimestampColumn(q, table.table, column); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeTimestampColumn rewrites the parseable timestamps of one column in UTC
+func normalizeTimestampColumn(q querier, table, column string) error {
+	rows, err := q.Query(`SELECT rowid, ` + column + ` FROM ` + table + ` WHERE ` + column + ` IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query %s.%s: %w", table, column, err)
+	}
+
+	values := make(map[int64]time.Time)
+	for rows.Next() {
+		var rowID int64
+		var value interface{}
+		if err := rows.Scan(&rowID, &value); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan %s.%s: %w", table, column, err)
+		}
+		if t, ok := value.(time.Time); ok {
+			values[rowID] = t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error reading %s.%s rows: %w", table, column, err)
+	}
+	rows.Close()
+
+	query := `UPDATE ` + table + ` SET ` + column + ` = ? WHERE rowid = ?`
+	for rowID, t := range values {
+		if _, err := q.Exec(query, t.UTC(), rowID); err != nil {
+			return fmt.Errorf("failed to normalize %s.%s: %w", table, column, err)
+		}
+	}
+	return nil
+}
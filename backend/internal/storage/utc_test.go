@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestTimestampsStoredInUTC(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second).In(zone)
+	entry := createStoppedEntry(t, s, task.ID, start, time.Hour)
+
+	var stored string
+	if err := s.db.QueryRow(`SELECT CAST(start_time AS TEXT) FROM time_entries WHERE id = ?`, entry.ID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored start time: %v", err)
+	}
+	if expected := start.UTC().Format(time.DateTime) + "+00:00"; stored != expected {
+		t.Errorf("Expected start time stored as %s, got %s", expected, stored)
+	}
+
+	retrieved, err := s.GetTimeEntry(entry.ID)
+	if err != nil {
+		t.Fatalf("Failed to get time entry: %v", err)
+	}
+	if !retrieved.StartTime.Equal(start) || retrieved.StartTime.Location() != time.UTC {
+		t.Errorf("Expected %v read back in UTC, got %v", start, retrieved.StartTime)
+	}
+}
+
+func TestNormalizeTimestampsMigration(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	task := createTestTask(t, s, project.ID)
+	entry, err := s.CreateTimeEntry(types.CreateTimeEntryRequest{TaskID: task.ID, StartTime: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create time entry: %v", err)
+	}
+
+	// Rows written before the migration carry the server's offset
+	if _, err := s.db.DB.Exec(`UPDATE time_entries SET start_time = '2024-03-10 01:30:00.5-05:00' WHERE id = ?`, entry.ID); err != nil {
+		t.Fatalf("Failed to write legacy timestamp: %v", err)
+	}
+
+	var migration Migration
+	for _, m := range migrations {
+		if m.Name == "normalize_timestamps_to_utc" {
+			migration = m
+		}
+	}
+	if err := migration.UpFunc(s.db); err != nil {
+		t.Fatalf("Failed to run migration: %v", err)
+	}
+
+	// Migrated rows are written in the driver's format, so equality lookups find them
+	var stored string
+	if err := s.db.QueryRow(`SELECT CAST(start_time AS TEXT) FROM time_entries WHERE id = ?`, entry.ID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored start time: %v", err)
+	}
+	if stored != "2024-03-10 06:30:00.5+00:00" {
+		t.Errorf("Expected the timestamp normalized to UTC, got %s", stored)
+	}
+
+	var matches int
+	lookup := `SELECT COUNT(*) FROM time_entries WHERE start_time = ?`
+	if err := s.db.QueryRow(lookup, time.Date(2024, time.March, 10, 1, 30, 0, 500000000, time.FixedZone("EST", -5*60*60))).Scan(&matches); err != nil {
+		t.Fatalf("Failed to look up migrated entry: %v", err)
+	}
+	if matches != 1 {
+		t.Errorf("Expected the migrated entry to match its start time exactly, got %d matches", matches)
+	}
+
+	retrieved, err := s.GetTimeEntry(entry.ID)
+	if err != nil {
+		t.Fatalf("Failed to read normalized entry: %v", err)
+	}
+	if expected := time.Date(2024, time.March, 10, 6, 30, 0, 500000000, time.UTC); !retrieved.StartTime.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, retrieved.StartTime)
+	}
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+// timerActivity summarizes the most recent time tracking on a task
+type timerActivity struct {
+	LastStartedAt time.Time
+	Tracking      bool
+}
+
+// GetSmartView returns the tasks belonging to a built-in view with their project embedded.
+// Day boundaries are computed in loc, and days sets the window of the upcoming and completed views.
+func (s *Storage) GetSmartView(view types.SmartView, now time.Time, loc *time.Location, days int) ([]types.TaskWithProject, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive")
+	}
+
+	var condition string
+	switch view {
+	case types.SmartViewOverdue, types.SmartViewToday, types.SmartViewUpcoming:
+		condition = `t.due_date IS NOT NULL AND t.status IN ('pending', 'in_progress')`
+	case types.SmartViewInProgress:
+		condition = `t.status = 'in_progress' OR EXISTS(SELECT 1 FROM time_entries te WHERE te.task_id = t.id AND te.end_time IS NULL)`
+	case types.SmartViewCompleted:
+		condition = `t.status = 'completed' AND t.completed_at IS NOT NULL`
+	default:
+		return nil, fmt.Errorf("unknown view %q", view)
+	}
+
+	candidates, err := s.getTasksWithProjects(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := s.getTimerActivity()
+	if err != nil {
+		return nil, err
+	}
+
+	todayStart := startOfDay(now, loc)
+	tomorrowStart := todayStart.AddDate(0, 0, 1)
+
+	tasks := []types.TaskWithProject{}
+	for _, task := range candidates {
+		if a, ok := activity[task.ID]; ok {
+			lastStarted := a.LastStartedAt
+			task.LastStartedAt = &lastStarted
+			task.Tracking = a.Tracking
+		}
+
+		include := false
+		switch view {
+		case types.SmartViewOverdue:
+			include = task.DueDate.Before(todayStart)
+		case types.SmartViewToday:
+			include = !task.DueDate.Before(todayStart) && task.DueDate.Before(tomorrowStart)
+		case types.SmartViewUpcoming:
+			include = !task.DueDate.Before(tomorrowStart) && task.DueDate.Before(tomorrowStart.AddDate(0, 0, days))
+		case types.SmartViewInProgress:
+			include = true
+		case types.SmartViewCompleted:
+			include = !task.CompletedAt.Before(todayStart.AddDate(0, 0, -(days - 1)))
+		}
+
+		if include {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sortSmartView(view, tasks)
+	return tasks, nil
+}
+
+// sortSmartView orders view results so the most actionable tasks come first
+func sortSmartView(view types.SmartView, tasks []types.TaskWithProject) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		switch view {
+		case types.SmartViewInProgress:
+			if a.LastStartedAt == nil || b.LastStartedAt == nil {
+				return a.LastStartedAt != nil
+			}
+			return a.LastStartedAt.After(*b.LastStartedAt)
+		case types.SmartViewCompleted:
+			return a.CompletedAt.After(*b.CompletedAt)
+		default:
+			if !a.DueDate.Equal(*b.DueDate) {
+				return a.DueDate.Before(*b.DueDate)
+			}
+			return a.Priority > b.Priority
+		}
+	})
+}
+
+// getTasksWithProjects retrieves tasks joined with their project matching a SQL condition
+func (s *Storage) getTasksWithProjects(condition string, args ...interface{}) ([]types.TaskWithProject, error) {
+	query := `SELECT t.id, t.project_id, t.parent_id, t.title, t.description, t.status, t.priority, t.due_date, t.completed_at, t.created_at, t.updated_at,
+			         p.id, p.name, p.description, p.color, p.icon, p.created_at, p.updated_at
+			  FROM tasks t
+			  JOIN projects p ON t.project_id = p.id
+			  WHERE (` + condition + `)
+			  ORDER BY t.priority DESC, t.created_at ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks with projects: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []types.TaskWithProject
+	for rows.Next() {
+		var task types.TaskWithProject
+		err := rows.Scan(
+			&task.ID,
+			&task.ProjectID,
+			&task.ParentID,
+			&task.Title,
+			&task.Description,
+			&task.Status,
+			&task.Priority,
+			&task.DueDate,
+			&task.CompletedAt,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&task.Project.ID,
+			&task.Project.Name,
+			&task.Project.Description,
+			&task.Project.Color,
+			&task.Project.Icon,
+			&task.Project.CreatedAt,
+			&task.Project.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task with project: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading task rows: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// getTimerActivity returns the latest timer start and tracking state for every task with time entries
+func (s *Storage) getTimerActivity() (map[int]timerActivity, error) {
+	query := `SELECT te.task_id, te.start_time,
+			         EXISTS(SELECT 1 FROM time_entries a WHERE a.task_id = te.task_id AND a.end_time IS NULL)
+			  FROM time_entries te
+			  WHERE te.id = (SELECT l.id FROM time_entries l WHERE l.task_id = te.task_id ORDER BY l.start_time DESC LIMIT 1)`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timer activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := make(map[int]timerActivity)
+	for rows.Next() {
+		var taskID int
+		var a timerActivity
+		if err := rows.Scan(&taskID, &a.LastStartedAt, &a.Tracking); err != nil {
+			return nil, fmt.Errorf("failed to scan timer activity: %w", err)
+		}
+		activity[taskID] = a
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading timer activity rows: %w", err)
+	}
+
+	return activity, nil
+}
+
+// startOfDay returns midnight of the day containing t in the given location
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// StartOfWeek returns midnight on the first day of the week containing t in the given location
+func StartOfWeek(t time.Time, loc *time.Location, firstDay time.Weekday) time.Time {
+	midnight := startOfDay(t, loc)
+	return midnight.AddDate(0, 0, -(int(midnight.Weekday())-int(firstDay)+7)%7)
+}
+
+// weekLabel labels the week starting at weekStart as YYYY-Www. Weeks may start on any day,
+// so they are labelled by the ISO week of their middle day.
+func weekLabel(weekStart time.Time) string {
+	year, week := weekStart.AddDate(0, 0, 3).ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"focused-todo/backend/pkg/types"
+)
+
+func TestGetSmartView(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	project := createTestProject(t, s)
+	now := time.Now()
+	loc := time.Local
+	todayStart := startOfDay(now, loc)
+
+	createDueTask := func(title string, due time.Time) *types.Task {
+		task, err := s.CreateTask(types.CreateTaskRequest{
+			ProjectID: project.ID,
+			Title:     title,
+			DueDate:   &due,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create task %s: %v", title, err)
+		}
+		return task
+	}
+
+	overdue := createDueTask("Overdue", todayStart.Add(-time.Hour))
+	today := createDueTask("Today", todayStart.Add(12*time.Hour))
+	upcoming := createDueTask("Upcoming", todayStart.AddDate(0, 0, 3))
+	createDueTask("Far future", todayStart.AddDate(0, 0, 30))
+
+	done := createDueTask("Done", todayStart.Add(-time.Hour))
+	if _, err := s.UpdateTaskStatus(done.ID, types.TaskStatusCompleted); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	tracked := createTestTask(t, s, project.ID)
+	if _, err := s.StartTimeEntry(types.StartTimeEntryRequest{TaskID: tracked.ID}); err != nil {
+		t.Fatalf("Failed to start time entry: %v", err)
+	}
+
+	tests := []struct {
+		view     types.SmartView
+		expected []int
+	}{
+		{types.SmartViewOverdue, []int{overdue.ID}},
+		{types.SmartViewToday, []int{today.ID}},
+		{types.SmartViewUpcoming, []int{upcoming.ID}},
+		{types.SmartViewInProgress, []int{tracked.ID}},
+		{types.SmartViewCompleted, []int{done.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.view), func(t *testing.T) {
+			tasks, err := s.GetSmartView(tt.view, now, loc, 7)
+			if err != nil {
+				t.Fatalf("Failed to get view: %v", err)
+			}
+
+			if len(tasks) != len(tt.expected) {
+				t.Fatalf("Expected %d tasks, got %d", len(tt.expected), len(tasks))
+			}
+
+			for i, id := range tt.expected {
+				if tasks[i].ID != id {
+					t.Errorf("Expected task %d at position %d, got %d", id, i, tasks[i].ID)
+				}
+				if tasks[i].Project.ID != project.ID {
+					t.Errorf("Expected embedded project %d, got %d", project.ID, tasks[i].Project.ID)
+				}
+			}
+		})
+	}
+
+	// The tracked task should report its running timer
+	tasks, err := s.GetSmartView(types.SmartViewInProgress, now, loc, 7)
+	if err != nil {
+		t.Fatalf("Failed to get in-progress view: %v", err)
+	}
+	if !tasks[0].Tracking || tasks[0].LastStartedAt == nil {
+		t.Errorf("Expected in-progress task to be tracking with a last start time")
+	}
+
+	// Unknown views are rejected
+	_, err = s.GetSmartView("someday", now, loc, 7)
+	if err == nil {
+		t.Errorf("Expected error for unknown view")
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+
+	// Wednesday 2024-01-03 02:00 UTC is still Tuesday evening in New York
+	moment := time.Date(2024, time.January, 3, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		firstDay time.Weekday
+		expected time.Time
+	}{
+		{time.Monday, time.Date(2024, time.January, 1, 0, 0, 0, 0, loc)},
+		{time.Sunday, time.Date(2023, time.December, 31, 0, 0, 0, 0, loc)},
+		{time.Wednesday, time.Date(2023, time.December, 27, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		if got := StartOfWeek(moment, loc, tt.firstDay); !got.Equal(tt.expected) {
+			t.Errorf("StartOfWeek with %s = %v, expected %v", tt.firstDay, got, tt.expected)
+		}
+	}
+}
+
+func TestWeekLabel(t *testing.T) {
+	tests := []struct {
+		weekStart time.Time
+		expected  string
+	}{
+		{time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), "2024-W01"},
+		// A Sunday-start week crossing the new year belongs to the ISO week of its Wednesday
+		{time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC), "2024-W01"},
+		{time.Date(2020, time.December, 28, 0, 0, 0, 0, time.UTC), "2020-W53"},
+	}
+
+	for _, tt := range tests {
+		if got := weekLabel(tt.weekStart); got != tt.expected {
+			t.Errorf("weekLabel(%s) = %s, expected %s", tt.weekStart.Format("2006-01-02"), got, tt.expected)
+		}
+	}
+}
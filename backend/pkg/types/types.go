@@ -41,6 +41,7 @@ type Task struct {
 	Status      TaskStatus `json:"status" db:"status"`
 	Priority    int        `json:"priority" db:"priority"`
 	DueDate     *time.Time `json:"due_date,omitempty" db:"due_date"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -55,6 +56,49 @@ type CreateTaskRequest struct {
 	DueDate     *time.Time `json:"due_date,omitempty"`
 }
 
+// BoardColumn represents one status column of a project's kanban board
+type BoardColumn struct {
+	Status   TaskStatus `json:"status"`
+	WIPLimit *int       `json:"wip_limit,omitempty"`
+	Tasks    []Task     `json:"tasks"`
+}
+
+// Board represents a project's tasks grouped into status columns
+type Board struct {
+	ProjectID int           `json:"project_id"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// MoveTaskRequest represents the request payload for moving a task on the board
+type MoveTaskRequest struct {
+	Status   TaskStatus `json:"status" validate:"required,oneof=pending in_progress completed cancelled"`
+	Position int        `json:"position" validate:"min=0"`
+}
+
+// SetWIPLimitRequest represents the request payload for setting a board column's WIP limit
+type SetWIPLimitRequest struct {
+	WIPLimit *int `json:"wip_limit" validate:"omitempty,gt=0"`
+}
+
+// SmartView identifies a built-in computed view over tasks
+type SmartView string
+
+const (
+	SmartViewOverdue    SmartView = "overdue"
+	SmartViewToday      SmartView = "today"
+	SmartViewUpcoming   SmartView = "upcoming"
+	SmartViewInProgress SmartView = "in-progress"
+	SmartViewCompleted  SmartView = "completed"
+)
+
+// TaskWithProject represents a task with its project info embedded
+type TaskWithProject struct {
+	Task
+	Project       Project    `json:"project"`
+	LastStartedAt *time.Time `json:"last_started_at,omitempty"`
+	Tracking      bool       `json:"tracking"`
+}
+
 // TimeEntry represents a time tracking entry
 type TimeEntry struct {
 	ID          int        `json:"id" db:"id"`
@@ -72,6 +116,7 @@ type CreateTimeEntryRequest struct {
 	StartTime   time.Time  `json:"start_time" validate:"required"`
 	EndTime     *time.Time `json:"end_time,omitempty"`
 	Description string     `json:"description,omitempty" validate:"max=500"`
+	Override    bool       `json:"override,omitempty"` // Skip the configurable time entry rules, e.g. for bulk imports
 }
 
 // StartTimeEntryRequest represents the request payload for starting a time entry
@@ -80,6 +125,34 @@ type StartTimeEntryRequest struct {
 	Description string `json:"description,omitempty" validate:"max=500"`
 }
 
+// StartTimeEntryResponse represents the result of starting a time entry
+type StartTimeEntryResponse struct {
+	TimeEntry
+	Stopped        []TimeEntry     `json:"stopped,omitempty"`         // Entries stopped by global timer mode
+	BudgetWarnings []BudgetWarning `json:"budget_warnings,omitempty"` // Exhausted budgets the new entry counts against
+}
+
+// ActiveTimer represents the running time entry with its task and project
+type ActiveTimer struct {
+	TimeEntry     TimeEntry  `json:"time_entry"`
+	Task          Task       `json:"task"`
+	Project       Project    `json:"project"`
+	Elapsed       int        `json:"elapsed"` // Tracked seconds, excluding paused time
+	Paused        bool       `json:"paused"`  // Whether the timer is currently paused
+	PausedAt      *time.Time `json:"paused_at,omitempty"`
+	PausedSeconds int        `json:"paused_seconds"` // Total paused seconds so far
+
+	IdleSpans []TimeEntryIdleSpan `json:"idle_spans,omitempty"` // Unresolved idle spans of the running entry
+}
+
+// TimeEntryPause represents an interval during which a running time entry was paused
+type TimeEntryPause struct {
+	ID          int        `json:"id" db:"id"`
+	TimeEntryID int        `json:"time_entry_id" db:"time_entry_id"`
+	PausedAt    time.Time  `json:"paused_at" db:"paused_at"`
+	ResumedAt   *time.Time `json:"resumed_at,omitempty" db:"resumed_at"`
+}
+
 // StopTimeEntryRequest represents the request payload for stopping a time entry
 type StopTimeEntryRequest struct {
 	Description string `json:"description,omitempty" validate:"max=500"`
@@ -90,6 +163,409 @@ type UpdateTimeEntryRequest struct {
 	StartTime   *time.Time `json:"start_time,omitempty"`
 	EndTime     *time.Time `json:"end_time,omitempty"`
 	Description *string    `json:"description,omitempty" validate:"omitempty,max=500"`
+	Override    bool       `json:"override,omitempty"` // Skip the configurable time entry rules
+}
+
+// SplitTimeEntryRequest represents the request payload for splitting a time entry in two
+type SplitTimeEntryRequest struct {
+	At       time.Time `json:"at" validate:"required"` // The first part ends and the second part starts here
+	Override bool      `json:"override,omitempty"`     // Skip the configurable time entry rules
+}
+
+// MergeTimeEntriesRequest represents the request payload for merging consecutive time entries of one task
+type MergeTimeEntriesRequest struct {
+	TimeEntryIDs []int `json:"time_entry_ids" validate:"required,min=2,max=100,dive,gt=0"`
+	Override     bool  `json:"override,omitempty"` // Skip the configurable time entry rules
+}
+
+// MoveTimeEntriesRequest represents the request payload for reassigning time entries to another task
+type MoveTimeEntriesRequest struct {
+	TimeEntryIDs []int `json:"time_entry_ids" validate:"required,min=1,max=100,dive,gt=0"`
+	TaskID       int   `json:"task_id" validate:"required,gt=0"`
+	Override     bool  `json:"override,omitempty"` // Skip the configurable time entry rules
+}
+
+// TimeEntryCorrection represents the result of splitting, merging or moving time entries as one change
+type TimeEntryCorrection struct {
+	TimeEntries []TimeEntry `json:"time_entries"` // Entries created or changed
+	DeletedIDs  []int       `json:"deleted_ids,omitempty"`
+}
+
+// HeartbeatRequest represents the periodic heartbeat a client sends for the running timer
+type HeartbeatRequest struct {
+	IdleSeconds int `json:"idle_seconds" validate:"min=0,max=604800"` // Seconds since the user's last input, at most a week
+}
+
+// IdleResolution represents how an idle span of a time entry is settled
+type IdleResolution string
+
+const (
+	IdleResolutionKeep    IdleResolution = "keep"    // Count the idle time as work
+	IdleResolutionDiscard IdleResolution = "discard" // Leave the idle time out of the entry
+	IdleResolutionSplit   IdleResolution = "split"   // End the entry at the idle start and continue in a new entry
+)
+
+// TimeEntryIdleSpan represents a span during which the user was idle while a timer ran
+type TimeEntryIdleSpan struct {
+	ID          int             `json:"id" db:"id"`
+	TimeEntryID int             `json:"time_entry_id" db:"time_entry_id"`
+	StartedAt   time.Time       `json:"started_at" db:"started_at"`
+	EndedAt     time.Time       `json:"ended_at" db:"ended_at"`
+	Resolution  *IdleResolution `json:"resolution,omitempty" db:"resolution"`
+	ResolvedAt  *time.Time      `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// ResolveIdleSpanRequest represents the request payload for resolving an idle span
+type ResolveIdleSpanRequest struct {
+	Resolution IdleResolution `json:"resolution" validate:"required,oneof=keep discard split"`
+}
+
+// IdleSpanResolution represents a resolved idle span with the time entries it changed
+type IdleSpanResolution struct {
+	IdleSpan    TimeEntryIdleSpan `json:"idle_span"`
+	TimeEntries []TimeEntry       `json:"time_entries"`
+}
+
+// TimelineSegment represents the part of a time entry that falls within one day
+type TimelineSegment struct {
+	TimeEntryID int       `json:"time_entry_id"`
+	TaskID      int       `json:"task_id"`
+	TaskTitle   string    `json:"task_title"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Duration    int       `json:"duration"` // Tracked seconds, excluding paused time
+	Running     bool      `json:"running"`
+}
+
+// TimelineProject represents a project's tracked time within a day or week
+type TimelineProject struct {
+	ProjectID int               `json:"project_id"`
+	Name      string            `json:"name"`
+	Color     string            `json:"color"`
+	Total     int               `json:"total"` // Seconds
+	Segments  []TimelineSegment `json:"segments,omitempty"`
+}
+
+// TimelineDay represents the tracked time of one day grouped by project
+type TimelineDay struct {
+	Date     string            `json:"date"` // YYYY-MM-DD in the timeline's timezone
+	Total    int               `json:"total"`
+	Projects []TimelineProject `json:"projects"`
+	Blocks   []CalendarBlock   `json:"blocks"`  // Imported calendar events overlapping the day
+	Blocked  int               `json:"blocked"` // Seconds of the day covered by blocks, counting overlaps once
+}
+
+// Timeline represents a week of tracked time for the Gantt view
+type Timeline struct {
+	Week     string            `json:"week"` // ISO week holding most of the days, e.g. 2024-W05
+	Timezone string            `json:"timezone"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Days     []TimelineDay     `json:"days"`
+	Totals   []TimelineProject `json:"totals"` // Week totals per project
+	Total    int               `json:"total"`
+}
+
+// TaskTimeStatistics represents the tracked time totals of a task
+type TaskTimeStatistics struct {
+	TaskID        int        `json:"task_id"`
+	TotalEntries  int        `json:"total_entries"`
+	TotalDuration int        `json:"total_duration"` // Seconds
+	AvgDuration   int        `json:"avg_duration"`   // Seconds
+	FirstEntry    *time.Time `json:"first_entry,omitempty"`
+	LastEntry     *time.Time `json:"last_entry,omitempty"`
+}
+
+// SetTaskTagsRequest represents the request payload for replacing a task's tags
+type SetTaskTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+// ReportGroupBy represents how tracked time is grouped in a report
+type ReportGroupBy string
+
+const (
+	ReportGroupByProject ReportGroupBy = "project"
+	ReportGroupByTask    ReportGroupBy = "task"
+	ReportGroupByDay     ReportGroupBy = "day"
+	ReportGroupByWeek    ReportGroupBy = "week"
+	ReportGroupByTag     ReportGroupBy = "tag"
+)
+
+// TimeReportQuery represents the parameters of a time report
+type TimeReportQuery struct {
+	From      time.Time // Inclusive
+	To        time.Time // Exclusive
+	GroupBy   ReportGroupBy
+	Rollup    bool         // Attribute subtask time to the top-level parent task
+	ProjectID *int         // Limit the report to one project
+	Rounding  RoundingRule // Applied to each billable entry
+	WeekStart time.Weekday // First day of the week for week groups, Sunday when unset
+}
+
+// TimeReportRow represents the tracked time of one report group
+type TimeReportRow struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	ProjectID *int   `json:"project_id,omitempty"`
+	TaskID    *int   `json:"task_id,omitempty"`
+	Entries   int    `json:"entries"`
+	Duration  int    `json:"duration"` // Seconds
+
+	BillableDuration int   `json:"billable_duration"` // Rounded seconds of billable entries
+	Amount           int64 `json:"amount"`            // Minor currency units
+}
+
+// TimeReport represents tracked time over a period grouped by one dimension.
+// With tag grouping an entry counts toward each of its task's tags, so rows may add up to more than the total.
+type TimeReport struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Timezone      string          `json:"timezone"`
+	GroupBy       ReportGroupBy   `json:"group_by"`
+	Rollup        bool            `json:"rollup"`
+	Rows          []TimeReportRow `json:"rows"`
+	TotalEntries  int             `json:"total_entries"`
+	TotalDuration int             `json:"total_duration"` // Seconds
+
+	Rounding              RoundingRule `json:"rounding"`
+	TotalBillableDuration int          `json:"total_billable_duration"` // Rounded seconds
+	TotalAmount           int64        `json:"total_amount"`            // Minor currency units
+}
+
+// RoundingMode represents the direction in which billed durations are rounded
+type RoundingMode string
+
+const (
+	RoundingModeNearest RoundingMode = "nearest"
+	RoundingModeUp      RoundingMode = "up"
+	RoundingModeDown    RoundingMode = "down"
+)
+
+// RoundingRule represents how each time entry's duration is rounded for billing
+type RoundingRule struct {
+	IncrementMinutes int          `json:"increment_minutes" validate:"omitempty,oneof=1 6 15 30"` // 0 disables rounding
+	Mode             RoundingMode `json:"mode,omitempty" validate:"omitempty,oneof=nearest up down"`
+}
+
+// SetHourlyRateRequest represents the request payload for setting a project or task hourly rate
+type SetHourlyRateRequest struct {
+	HourlyRate *int64 `json:"hourly_rate" validate:"omitempty,min=0"` // Minor currency units per hour, null clears the rate
+}
+
+// HourlyRate represents the hourly rate configured on a project or task
+type HourlyRate struct {
+	ProjectID  *int   `json:"project_id,omitempty"`
+	TaskID     *int   `json:"task_id,omitempty"`
+	HourlyRate *int64 `json:"hourly_rate"` // Minor currency units per hour
+}
+
+// SetBillableRequest represents the request payload for marking a time entry billable or not
+type SetBillableRequest struct {
+	Billable *bool `json:"billable" validate:"required"`
+}
+
+// TimeEntryBilling represents the billing state of a time entry
+type TimeEntryBilling struct {
+	TimeEntryID int  `json:"time_entry_id"`
+	Billable    bool `json:"billable"`
+	InvoiceID   *int `json:"invoice_id,omitempty"`
+}
+
+// CreateInvoiceRequest represents the request payload for drafting an invoice
+type CreateInvoiceRequest struct {
+	ProjectID   int          `json:"project_id" validate:"required,gt=0"`
+	PeriodStart time.Time    `json:"period_start" validate:"required"`
+	PeriodEnd   time.Time    `json:"period_end" validate:"required,gtfield=PeriodStart"`
+	Rounding    RoundingRule `json:"rounding"`
+}
+
+// InvoiceLine represents the billed time of one task on an invoice
+type InvoiceLine struct {
+	ID          int    `json:"id" db:"id"`
+	InvoiceID   int    `json:"invoice_id" db:"invoice_id"`
+	TaskID      *int   `json:"task_id,omitempty" db:"task_id"`
+	Description string `json:"description" db:"description"`
+	Entries     int    `json:"entries" db:"entries"`
+	Duration    int    `json:"duration" db:"duration"`       // Rounded seconds
+	HourlyRate  int64  `json:"hourly_rate" db:"hourly_rate"` // Minor currency units per hour
+	Amount      int64  `json:"amount" db:"amount"`           // Minor currency units
+}
+
+// Invoice represents an invoice draft for a project's unbilled time in a period
+type Invoice struct {
+	ID            int           `json:"id" db:"id"`
+	ProjectID     int           `json:"project_id" db:"project_id"`
+	PeriodStart   time.Time     `json:"period_start" db:"period_start"`
+	PeriodEnd     time.Time     `json:"period_end" db:"period_end"`
+	Rounding      RoundingRule  `json:"rounding"`
+	TotalDuration int           `json:"total_duration" db:"total_duration"` // Rounded seconds
+	TotalAmount   int64         `json:"total_amount" db:"total_amount"`     // Minor currency units
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	Lines         []InvoiceLine `json:"lines"`
+}
+
+// TimeEntryRuleCode identifies the time entry rule a request broke
+type TimeEntryRuleCode string
+
+const (
+	TimeEntryRuleStartInFuture  TimeEntryRuleCode = "start_in_future"
+	TimeEntryRuleEndInFuture    TimeEntryRuleCode = "end_in_future"
+	TimeEntryRuleBackdateLimit  TimeEntryRuleCode = "backdate_limit_exceeded"
+	TimeEntryRuleEndBeforeStart TimeEntryRuleCode = "end_before_start"
+	TimeEntryRuleMaxDuration    TimeEntryRuleCode = "duration_too_long"
+	TimeEntryRuleMinDuration    TimeEntryRuleCode = "duration_too_short"
+	TimeEntryRuleOverlap        TimeEntryRuleCode = "overlapping_entry"
+	TimeEntryRuleCrossOverlap   TimeEntryRuleCode = "cross_task_overlap"
+)
+
+// ConflictAction represents a proposed way to resolve overlapping time entries
+type ConflictAction string
+
+const (
+	ConflictActionTrimFirst    ConflictAction = "trim_first"    // End the earlier entry where the later one starts
+	ConflictActionTrimSecond   ConflictAction = "trim_second"   // Start the later entry where the earlier one ends
+	ConflictActionDeleteSecond ConflictAction = "delete_second" // Delete the later entry, which lies within the earlier one
+)
+
+// ConflictEntry represents one of two overlapping time entries with its task
+type ConflictEntry struct {
+	TimeEntryID int        `json:"time_entry_id"`
+	TaskID      int        `json:"task_id"`
+	TaskTitle   string     `json:"task_title"`
+	ProjectID   int        `json:"project_id"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"` // Nil while running
+}
+
+// ConflictResolution represents a proposed change to one time entry that removes an overlap
+type ConflictResolution struct {
+	Action      ConflictAction `json:"action"`
+	TimeEntryID int            `json:"time_entry_id"`
+	StartTime   *time.Time     `json:"start_time,omitempty"` // Proposed times, absent for deletions
+	EndTime     *time.Time     `json:"end_time,omitempty"`
+}
+
+// TimeEntryConflict represents two time entries on different tasks that overlap
+type TimeEntryConflict struct {
+	First        ConflictEntry        `json:"first"` // Starts first
+	Second       ConflictEntry        `json:"second"`
+	OverlapStart time.Time            `json:"overlap_start"`
+	OverlapEnd   time.Time            `json:"overlap_end"`
+	Overlap      int                  `json:"overlap"` // Seconds
+	Resolutions  []ConflictResolution `json:"resolutions"`
+}
+
+// TimeEntryRules represents the limits time entries must satisfy
+type TimeEntryRules struct {
+	MaxBackdateDays    int `json:"max_backdate_days"`    // 0 allows entries to start any time in the past
+	MaxDurationMinutes int `json:"max_duration_minutes"` // 0 allows any duration
+	MinDurationSeconds int `json:"min_duration_seconds"`
+	ClockSkewSeconds   int `json:"clock_skew_seconds"` // Tolerance for times slightly in the future
+}
+
+// TimeEntryRulesOverride represents a project's overrides of the default time entry rules.
+// Nil fields keep the default.
+type TimeEntryRulesOverride struct {
+	MaxBackdateDays    *int `json:"max_backdate_days" validate:"omitempty,min=0"`
+	MaxDurationMinutes *int `json:"max_duration_minutes" validate:"omitempty,min=0"`
+	MinDurationSeconds *int `json:"min_duration_seconds" validate:"omitempty,min=0"`
+	ClockSkewSeconds   *int `json:"clock_skew_seconds" validate:"omitempty,min=0"`
+}
+
+// ProjectTimeEntryRules represents a project's rule overrides and the rules that apply as a result
+type ProjectTimeEntryRules struct {
+	ProjectID int                    `json:"project_id"`
+	Overrides TimeEntryRulesOverride `json:"overrides"`
+	Effective TimeEntryRules         `json:"effective"`
+}
+
+// DanglingPolicy determines how time entries left running after a crash are reconciled
+type DanglingPolicy string
+
+const (
+	DanglingPolicyHeartbeat DanglingPolicy = "heartbeat" // Close at the last heartbeat
+	DanglingPolicyCap       DanglingPolicy = "cap"       // Close after a fixed maximum duration
+	DanglingPolicyReview    DanglingPolicy = "review"    // Leave running and flag for review
+)
+
+// ReconcileOptions configures startup reconciliation of dangling time entries
+type ReconcileOptions struct {
+	Policy    DanglingPolicy
+	Threshold time.Duration // Entries without activity for longer than this are dangling
+	Cap       time.Duration // Maximum duration used by the cap policy
+}
+
+// ReviewAction records what reconciliation did with a dangling time entry
+type ReviewAction string
+
+const (
+	ReviewActionClosedAtHeartbeat ReviewAction = "closed_at_heartbeat"
+	ReviewActionClosedAtCap       ReviewAction = "closed_at_cap"
+	ReviewActionFlagged           ReviewAction = "flagged"
+)
+
+// TimeEntryReview represents a dangling time entry awaiting user review
+type TimeEntryReview struct {
+	TimeEntry       TimeEntry    `json:"time_entry"`
+	Action          ReviewAction `json:"action"`
+	LastHeartbeatAt *time.Time   `json:"last_heartbeat_at,omitempty"`
+	DetectedAt      time.Time    `json:"detected_at"`
+}
+
+// PomodoroPhase represents the current interval of a pomodoro session
+type PomodoroPhase string
+
+const (
+	PomodoroPhaseWork       PomodoroPhase = "work"
+	PomodoroPhaseShortBreak PomodoroPhase = "short_break"
+	PomodoroPhaseLongBreak  PomodoroPhase = "long_break"
+)
+
+// PomodoroSettings represents the interval configuration of a pomodoro session
+type PomodoroSettings struct {
+	WorkMinutes       int  `json:"work_minutes" validate:"min=1,max=180"`
+	ShortBreakMinutes int  `json:"short_break_minutes" validate:"min=1,max=60"`
+	LongBreakMinutes  int  `json:"long_break_minutes" validate:"min=1,max=120"`
+	LongBreakEvery    int  `json:"long_break_every" validate:"min=1,max=12"` // Work intervals before a long break
+	AutoStart         bool `json:"auto_start"`                               // Start the next interval automatically
+}
+
+// PomodoroSession represents the state of a task's pomodoro session
+type PomodoroSession struct {
+	TaskID             int              `json:"task_id"`
+	Phase              PomodoroPhase    `json:"phase"`
+	Running            bool             `json:"running"` // False while the next interval awaits a manual start
+	PhaseStartedAt     *time.Time       `json:"phase_started_at,omitempty"`
+	PhaseEndsAt        *time.Time       `json:"phase_ends_at,omitempty"`
+	Remaining          int              `json:"remaining"` // Seconds left in the running interval
+	CompletedPomodoros int              `json:"completed_pomodoros"`
+	Settings           PomodoroSettings `json:"settings"`
+}
+
+// PomodoroEventType identifies a pomodoro interval transition
+type PomodoroEventType string
+
+const (
+	PomodoroEventPhaseStarted   PomodoroEventType = "phase_started"
+	PomodoroEventPhaseCompleted PomodoroEventType = "phase_completed"
+	PomodoroEventBreakSkipped   PomodoroEventType = "break_skipped"
+	PomodoroEventSessionStopped PomodoroEventType = "session_stopped"
+)
+
+// PomodoroEvent represents a pomodoro interval transition that clients can poll for
+type PomodoroEvent struct {
+	Seq    int               `json:"seq"`
+	TaskID int               `json:"task_id"`
+	Type   PomodoroEventType `json:"type"`
+	Phase  PomodoroPhase     `json:"phase"`
+	At     time.Time         `json:"at"`
+}
+
+// StartPomodoroRequest represents the request payload for starting a pomodoro session
+type StartPomodoroRequest struct {
+	TaskID   int               `json:"task_id" validate:"required,gt=0"`
+	Settings *PomodoroSettings `json:"settings,omitempty"` // Overrides the configured defaults
 }
 
 // TaskOrder represents task ID and its new priority for reordering
@@ -105,9 +581,31 @@ type ReorderTasksRequest struct {
 
 // APIResponse represents a standard API response wrapper
 type APIResponse[T any] struct {
-	Data    T      `json:"data"`
-	Success bool   `json:"success"`
-	Message string `json:"message,omitempty"`
+	Data    T             `json:"data"`
+	Success bool          `json:"success"`
+	Message string        `json:"message,omitempty"`
+	Meta    *ResponseMeta `json:"meta,omitempty"`
+}
+
+// ResponseMeta carries optional metadata about an API response
+type ResponseMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+	Timezone   string `json:"timezone,omitempty"` // Timezone used for day and week boundaries
+}
+
+// PageRequest describes a cursor-paginated list request
+type PageRequest struct {
+	Limit        int    // Maximum number of items, 0 for no limit
+	Cursor       string // Opaque cursor returned with the previous page
+	IncludeTotal bool   // Whether to count all matching items
+}
+
+// Page represents one page of a cursor-paginated list
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      *int
 }
 
 // ErrorResponse represents an error response
@@ -155,6 +653,32 @@ func NewAPIResponseWithMessage[T any](data T, message string) APIResponse[T] {
 	}
 }
 
+// NewPaginatedAPIResponse creates a new successful API response for one page of a list
+func NewPaginatedAPIResponse[T any](page Page[T]) APIResponse[[]T] {
+	return APIResponse[[]T]{
+		Data:    page.Items,
+		Success: true,
+		Meta: &ResponseMeta{
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
+		},
+	}
+}
+
+// WithTimezone returns a copy of the response whose meta records the timezone used to build it.
+// A timezone that is already set is kept.
+func (r APIResponse[T]) WithTimezone(timezone string) interface{} {
+	meta := ResponseMeta{}
+	if r.Meta != nil {
+		meta = *r.Meta
+	}
+	if meta.Timezone == "" {
+		meta.Timezone = timezone
+	}
+	r.Meta = &meta
+	return r
+}
+
 // NewErrorResponse creates a new error response
 func NewErrorResponse(message string) ErrorResponse {
 	return ErrorResponse{
@@ -178,3 +702,575 @@ func NewErrorResponseWithDetails(message, code string, details map[string]string
 		Details: details,
 	}
 }
+
+// BudgetPeriod represents how often a budget resets
+type BudgetPeriod string
+
+const (
+	BudgetPeriodTotal BudgetPeriod = "total"
+	BudgetPeriodWeek  BudgetPeriod = "week"
+	BudgetPeriodMonth BudgetPeriod = "month"
+)
+
+// Budget represents a limit on the hours tracked on a project or task
+type Budget struct {
+	ID         int          `json:"id" db:"id"`
+	ProjectID  *int         `json:"project_id,omitempty" db:"project_id"`
+	TaskID     *int         `json:"task_id,omitempty" db:"task_id"` // Includes time tracked on subtasks
+	Period     BudgetPeriod `json:"period" db:"period"`
+	Hours      float64      `json:"hours" db:"limit_seconds"`
+	Thresholds []int        `json:"thresholds" db:"thresholds"` // Percentages that raise alerts
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// CreateBudgetRequest represents the request payload for creating a budget on either a project or a task
+type CreateBudgetRequest struct {
+	ProjectID  *int         `json:"project_id,omitempty" validate:"required_without=TaskID,excluded_with=TaskID,omitempty,gt=0"`
+	TaskID     *int         `json:"task_id,omitempty" validate:"omitempty,gt=0"`
+	Period     BudgetPeriod `json:"period" validate:"required,oneof=total week month"`
+	Hours      float64      `json:"hours" validate:"gt=0"`
+	Thresholds []int        `json:"thresholds,omitempty" validate:"omitempty,max=10,dive,min=1,max=1000"` // Defaults to the configured thresholds
+}
+
+// UpdateBudgetRequest represents the request payload for updating a budget
+type UpdateBudgetRequest struct {
+	Period     BudgetPeriod `json:"period" validate:"required,oneof=total week month"`
+	Hours      float64      `json:"hours" validate:"gt=0"`
+	Thresholds []int        `json:"thresholds,omitempty" validate:"omitempty,max=10,dive,min=1,max=1000"`
+}
+
+// BudgetStatus represents a budget's consumption in its current period
+type BudgetStatus struct {
+	Budget
+	PeriodLabel string     `json:"period_label"` // "total", the week's start date or the month, e.g. 2024-05
+	PeriodStart *time.Time `json:"period_start,omitempty"`
+	PeriodEnd   *time.Time `json:"period_end,omitempty"`
+	Limit       int        `json:"limit"`     // Seconds
+	Consumed    int        `json:"consumed"`  // Seconds, including running entries
+	Remaining   int        `json:"remaining"` // Seconds, negative once the budget is exceeded
+	UsedPercent float64    `json:"used_percent"`
+	Exhausted   bool       `json:"exhausted"`
+}
+
+// BudgetAlert represents a budget crossing one of its thresholds in a period
+type BudgetAlert struct {
+	ID             int        `json:"id" db:"id"`
+	BudgetID       int        `json:"budget_id" db:"budget_id"`
+	ProjectID      *int       `json:"project_id,omitempty"`
+	TaskID         *int       `json:"task_id,omitempty"`
+	Threshold      int        `json:"threshold" db:"threshold"` // Percent
+	PeriodLabel    string     `json:"period_label" db:"period"`
+	Consumed       int        `json:"consumed" db:"consumed"` // Seconds when the alert was raised
+	Limit          int        `json:"limit" db:"limit_seconds"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+}
+
+// BudgetWarning represents an exhausted budget reported when starting a timer
+type BudgetWarning struct {
+	BudgetID int    `json:"budget_id"`
+	Message  string `json:"message"`
+}
+
+// ImportSource represents the tracker a time log export comes from
+type ImportSource string
+
+const (
+	ImportSourceToggl    ImportSource = "toggl"
+	ImportSourceClockify ImportSource = "clockify"
+)
+
+// ImportRecord represents one time entry read from an export
+type ImportRecord struct {
+	Line        int       `json:"line"` // Line in the export, counting the header as line 1
+	Client      string    `json:"client,omitempty"`
+	Project     string    `json:"project,omitempty"`
+	Task        string    `json:"task,omitempty"`
+	Description string    `json:"description,omitempty"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Billable    *bool     `json:"billable,omitempty"`
+	Error       string    `json:"error,omitempty"` // Why the line could not be read
+}
+
+// ImportRowStatus represents what importing an export line did or would do
+type ImportRowStatus string
+
+const (
+	ImportRowCreated   ImportRowStatus = "created"
+	ImportRowDuplicate ImportRowStatus = "duplicate" // Imported before
+	ImportRowConflict  ImportRowStatus = "conflict"  // Overlaps an existing entry
+	ImportRowInvalid   ImportRowStatus = "invalid"
+)
+
+// ImportRow represents the outcome for one export line
+type ImportRow struct {
+	Line        int             `json:"line"`
+	ProjectName string          `json:"project_name,omitempty"`
+	TaskTitle   string          `json:"task_title,omitempty"`
+	StartTime   time.Time       `json:"start_time"`
+	EndTime     time.Time       `json:"end_time"`
+	Status      ImportRowStatus `json:"status"`
+	Message     string          `json:"message,omitempty"`
+	TimeEntryID *int            `json:"time_entry_id,omitempty"` // Set once committed
+}
+
+// ImportResult represents the outcome of importing an export, or what it would be for a dry run
+type ImportResult struct {
+	Source          ImportSource `json:"source"`
+	DryRun          bool         `json:"dry_run"`
+	Created         int          `json:"created"`
+	Duplicates      int          `json:"duplicates"`
+	Conflicts       int          `json:"conflicts"`
+	Invalid         int          `json:"invalid"`
+	ProjectsCreated []string     `json:"projects_created"`
+	TasksCreated    []string     `json:"tasks_created"`
+	Rows            []ImportRow  `json:"rows"`
+}
+
+// CalendarTimeEntry represents a stopped time entry with the task and project shown in a calendar feed
+type CalendarTimeEntry struct {
+	TimeEntry
+	UpdatedAt   time.Time `json:"updated_at"`
+	Revision    int       `json:"revision"`
+	TaskTitle   string    `json:"task_title"`
+	ProjectID   int       `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+}
+
+// CalendarFeed represents the tasks with due dates and the logged time published as a calendar
+type CalendarFeed struct {
+	Tasks       []TaskWithProject   `json:"tasks"`
+	TimeEntries []CalendarTimeEntry `json:"time_entries"`
+}
+
+// CalendarBlock represents one occurrence of an imported calendar event, such as a meeting, that blocks time
+type CalendarBlock struct {
+	ID          int       `json:"id" db:"id"`
+	Calendar    string    `json:"calendar" db:"calendar"` // Name the calendar was imported under
+	UID         string    `json:"uid" db:"uid"`
+	Summary     string    `json:"summary" db:"summary"`
+	StartTime   time.Time `json:"start_time" db:"start_time"`
+	EndTime     time.Time `json:"end_time" db:"end_time"`
+	AllDay      bool      `json:"all_day" db:"all_day"`
+	TimeEntryID *int      `json:"time_entry_id,omitempty" db:"time_entry_id"` // Set once converted to a time entry
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CalendarImportResult represents the outcome of importing a calendar's events within a window
+type CalendarImportResult struct {
+	Calendar string          `json:"calendar"`
+	From     time.Time       `json:"from"`
+	Until    time.Time       `json:"until"`
+	Created  int             `json:"created"`
+	Updated  int             `json:"updated"`
+	Removed  int             `json:"removed"` // Blocks in the window no longer in the calendar
+	Blocks   []CalendarBlock `json:"blocks"`
+}
+
+// ConvertCalendarBlocksRequest represents the request payload for logging calendar blocks as time entries
+type ConvertCalendarBlocksRequest struct {
+	BlockIDs []int `json:"block_ids" validate:"required,min=1,max=100,dive,gt=0"`
+	TaskID   int   `json:"task_id" validate:"required,gt=0"` // E.g. a "Meetings" task
+}
+
+// CalendarBlockConversion represents the outcome of converting one calendar block
+type CalendarBlockConversion struct {
+	BlockID     int    `json:"block_id"`
+	TimeEntryID *int   `json:"time_entry_id,omitempty"`
+	Skipped     string `json:"skipped,omitempty"` // Why the block was not converted
+}
+
+// ConvertCalendarBlocksResult represents the outcome of converting calendar blocks to time entries
+type ConvertCalendarBlocksResult struct {
+	Converted int                       `json:"converted"`
+	Skipped   int                       `json:"skipped"`
+	Blocks    []CalendarBlockConversion `json:"blocks"`
+}
+
+// DailyPlanItem represents a task committed to on a day, with how it went
+type DailyPlanItem struct {
+	Position    int             `json:"position"` // 1 is the first task of the day
+	Task        TaskWithProject `json:"task"`
+	CarriedFrom *string         `json:"carried_from,omitempty"` // Plan date the task was carried over from
+	CarriedTo   *string         `json:"carried_to,omitempty"`   // Plan date the unfinished task was carried forward to
+	Completed   bool            `json:"completed"`              // Completed by the end of the day
+	Tracked     int             `json:"tracked"`                // Seconds tracked on the task during the day
+}
+
+// DailyPlanTask represents a task worked on or completed during a day without being planned
+type DailyPlanTask struct {
+	TaskID    int    `json:"task_id"`
+	Title     string `json:"title"`
+	ProjectID int    `json:"project_id"`
+	Completed bool   `json:"completed"` // Completed during the day
+	Tracked   int    `json:"tracked"`   // Seconds
+}
+
+// DailyPlanReview represents how a day's plan compared with what was completed and tracked
+type DailyPlanReview struct {
+	Planned        int             `json:"planned"`
+	Completed      int             `json:"completed"`
+	CompletionRate float64         `json:"completion_rate"` // Share of planned tasks completed, 0 to 1
+	TrackedPlanned int             `json:"tracked_planned"` // Seconds tracked on planned tasks
+	TrackedOther   int             `json:"tracked_other"`   // Seconds tracked on other tasks
+	Unplanned      []DailyPlanTask `json:"unplanned"`
+}
+
+// DailyPlan represents the ordered tasks committed to on a day
+type DailyPlan struct {
+	Date      string          `json:"date"` // YYYY-MM-DD in the plan's timezone
+	SlotLimit int             `json:"slot_limit"`
+	Items     []DailyPlanItem `json:"items"`
+	Review    DailyPlanReview `json:"review"`
+}
+
+// SetDailyPlanRequest represents the request payload for replacing a day's plan with tasks in order
+type SetDailyPlanRequest struct {
+	TaskIDs []int `json:"task_ids" validate:"max=100,dive,gt=0"`
+}
+
+// CarryForwardRequest represents the request payload for moving a plan's unfinished tasks to another day
+type CarryForwardRequest struct {
+	To string `json:"to,omitempty"` // YYYY-MM-DD, defaults to the next day
+}
+
+// CarryForwardSkip represents an unfinished task that could not be carried forward
+type CarryForwardSkip struct {
+	TaskID int    `json:"task_id"`
+	Reason string `json:"reason"`
+}
+
+// CarryForwardResult represents the outcome of carrying a plan's unfinished tasks forward
+type CarryForwardResult struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	Carried []int              `json:"carried"` // Task IDs added to the target plan
+	Skipped []CarryForwardSkip `json:"skipped"`
+	Plan    DailyPlan          `json:"plan"` // The target plan after carrying forward
+}
+
+// PlannedBlock represents time set aside to work on a task, e.g. 9:00 to 10:30 on task 42
+type PlannedBlock struct {
+	ID        int       `json:"id" db:"id"`
+	TaskID    int       `json:"task_id" db:"task_id"`
+	TaskTitle string    `json:"task_title"`
+	ProjectID int       `json:"project_id"`
+	StartTime time.Time `json:"start_time" db:"start_time"`
+	EndTime   time.Time `json:"end_time" db:"end_time"`
+	Note      string    `json:"note,omitempty" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreatePlannedBlockRequest represents the request payload for creating or replacing a planned block
+type CreatePlannedBlockRequest struct {
+	TaskID    int       `json:"task_id" validate:"required,gt=0"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+	Note      string    `json:"note,omitempty" validate:"max=500"`
+}
+
+// PlannedBlockAdherence represents how the time of one planned block was spent. The parts add up to
+// the block's duration.
+type PlannedBlockAdherence struct {
+	Block   PlannedBlock `json:"block"`
+	OnPlan  int          `json:"on_plan"`  // Seconds tracked on the block's task
+	OffPlan int          `json:"off_plan"` // Seconds tracked only on other tasks
+	Missed  int          `json:"missed"`   // Seconds with nothing tracked
+}
+
+// AdherenceTotals represents planned against tracked time over a span. Times are wall clock seconds,
+// so entries tracked in parallel count once.
+type AdherenceTotals struct {
+	Planned   int     `json:"planned"`
+	OnPlan    int     `json:"on_plan"`
+	OffPlan   int     `json:"off_plan"`
+	Missed    int     `json:"missed"`
+	Unplanned int     `json:"unplanned"` // Seconds tracked outside planned blocks
+	Adherence float64 `json:"adherence"` // Share of planned time spent on plan, 0 to 1
+}
+
+// AdherenceDay represents planned against tracked time for one day
+type AdherenceDay struct {
+	Date string `json:"date"` // YYYY-MM-DD in the report's timezone
+	AdherenceTotals
+}
+
+// PlanAdherence represents a week of planned blocks overlaid on tracked time
+type PlanAdherence struct {
+	Week     string                  `json:"week"` // ISO week holding most of the days, e.g. 2024-W05
+	Timezone string                  `json:"timezone"`
+	Start    time.Time               `json:"start"`
+	End      time.Time               `json:"end"`
+	Totals   AdherenceTotals         `json:"totals"`
+	Days     []AdherenceDay          `json:"days"`
+	Blocks   []PlannedBlockAdherence `json:"blocks"`
+}
+
+// WorkPeriod represents a span of working time within a day, in minutes after midnight
+type WorkPeriod struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// WorkingHours represents the working periods of each weekday, indexed by time.Weekday
+type WorkingHours [7][]WorkPeriod
+
+// SetTaskEstimateRequest represents the request payload for setting a task's time estimate
+type SetTaskEstimateRequest struct {
+	Minutes *int `json:"minutes" validate:"omitempty,min=1,max=100000"` // Null clears the estimate
+}
+
+// TaskEstimate represents how long a task is expected to take
+type TaskEstimate struct {
+	TaskID  int  `json:"task_id"`
+	Minutes *int `json:"minutes"`
+}
+
+// ProposeScheduleRequest represents the request payload for proposing a schedule
+type ProposeScheduleRequest struct {
+	Days      int   `json:"days,omitempty" validate:"omitempty,min=1,max=60"` // Days to fill starting today, defaults to 14
+	ProjectID *int  `json:"project_id,omitempty" validate:"omitempty,gt=0"`
+	TaskIDs   []int `json:"task_ids,omitempty" validate:"omitempty,max=500,dive,gt=0"`
+	Commit    bool  `json:"commit"` // Save the proposed blocks as planned blocks
+}
+
+// ProposedBlock represents free time the scheduler assigned to a task
+type ProposedBlock struct {
+	TaskID    int       `json:"task_id"`
+	TaskTitle string    `json:"task_title"`
+	ProjectID int       `json:"project_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ScheduledTask represents a task the scheduler placed and when it will be finished
+type ScheduledTask struct {
+	TaskID    int        `json:"task_id"`
+	TaskTitle string     `json:"task_title"`
+	ProjectID int        `json:"project_id"`
+	Priority  int        `json:"priority"`
+	DueDate   *time.Time `json:"due_date,omitempty"`
+	Minutes   int        `json:"minutes"` // Time scheduled
+	Finish    time.Time  `json:"finish"`
+	Late      bool       `json:"late"` // Whether it finishes after it is due
+}
+
+// UnscheduledTask represents a task the scheduler could not place
+type UnscheduledTask struct {
+	TaskID    int        `json:"task_id"`
+	TaskTitle string     `json:"task_title"`
+	ProjectID int        `json:"project_id"`
+	DueDate   *time.Time `json:"due_date,omitempty"`
+	Minutes   int        `json:"minutes"` // Time still needed
+	Reason    string     `json:"reason"`
+}
+
+// ScheduleProposal represents tasks placed into the free working time of the coming days
+type ScheduleProposal struct {
+	Timezone    string            `json:"timezone"`
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	FreeMinutes int               `json:"free_minutes"` // Free working time in the window before scheduling
+	Blocks      []ProposedBlock   `json:"blocks"`
+	Tasks       []ScheduledTask   `json:"tasks"`
+	Unscheduled []UnscheduledTask `json:"unscheduled"`
+	Late        int               `json:"late"`                // Tasks that finish after they are due or were not placed before it
+	Committed   []PlannedBlock    `json:"committed,omitempty"` // Planned blocks created when committing
+}
+
+// TimeOff represents holidays or leave spanning whole days, during which no working hours are available
+type TimeOff struct {
+	ID        int       `json:"id" db:"id"`
+	StartDate string    `json:"start_date" db:"start_date"` // YYYY-MM-DD
+	EndDate   string    `json:"end_date" db:"end_date"`     // YYYY-MM-DD, inclusive
+	Note      string    `json:"note,omitempty" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateTimeOffRequest represents the request payload for adding time off
+type CreateTimeOffRequest struct {
+	StartDate string `json:"start_date" validate:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date,omitempty"`             // YYYY-MM-DD, inclusive, defaults to the start date
+	Note      string `json:"note,omitempty" validate:"max=200"`
+}
+
+// CapacityTask represents the work left on a task due within a capacity window
+type CapacityTask struct {
+	TaskID    int       `json:"task_id"`
+	TaskTitle string    `json:"task_title"`
+	ProjectID int       `json:"project_id"`
+	DueDate   time.Time `json:"due_date"`
+	Minutes   int       `json:"minutes"` // Estimate or default duration less the time tracked
+	Overdue   bool      `json:"overdue"` // Due before the window, counted on its first day
+}
+
+// CapacityDay compares a day's available working time with the work due on it
+type CapacityDay struct {
+	Date                string         `json:"date"`
+	TimeOff             bool           `json:"time_off"`
+	WorkingMinutes      int            `json:"working_minutes"`   // Working hours left in the day
+	BlockedMinutes      int            `json:"blocked_minutes"`   // Working time taken by calendar blocks
+	AvailableMinutes    int            `json:"available_minutes"` // Working time less blocked time
+	WorkloadMinutes     int            `json:"workload_minutes"`  // Work left on tasks due that day
+	CumulativeAvailable int            `json:"cumulative_available"`
+	CumulativeWorkload  int            `json:"cumulative_workload"`
+	Overloaded          bool           `json:"overloaded"` // More work is due by the end of the day than time is available until then
+	Tasks               []CapacityTask `json:"tasks"`
+}
+
+// CapacityWeek sums the capacity days of a week within the window
+type CapacityWeek struct {
+	Week             string    `json:"week"` // ISO week, e.g. 2024-W03
+	Start            time.Time `json:"start"`
+	AvailableMinutes int       `json:"available_minutes"`
+	WorkloadMinutes  int       `json:"workload_minutes"`
+	Overloaded       bool      `json:"overloaded"` // More work is due in the week than time is available in it
+	OverloadedDays   int       `json:"overloaded_days"`
+}
+
+// Capacity compares available working time with the work due over the coming days
+type Capacity struct {
+	Timezone         string         `json:"timezone"`
+	Start            time.Time      `json:"start"`
+	End              time.Time      `json:"end"`
+	AvailableMinutes int            `json:"available_minutes"`
+	WorkloadMinutes  int            `json:"workload_minutes"`
+	OverloadedDays   int            `json:"overloaded_days"`
+	Days             []CapacityDay  `json:"days"`
+	Weeks            []CapacityWeek `json:"weeks"`
+}
+
+// Quadrant represents a quadrant of the Eisenhower matrix
+type Quadrant string
+
+const (
+	QuadrantDo        Quadrant = "do"        // Important and urgent
+	QuadrantSchedule  Quadrant = "schedule"  // Important, not urgent
+	QuadrantDelegate  Quadrant = "delegate"  // Urgent, not important
+	QuadrantEliminate Quadrant = "eliminate" // Neither important nor urgent
+)
+
+// MatrixRules represents how tasks without an explicit classification are placed in the Eisenhower matrix
+type MatrixRules struct {
+	ImportantPriority int  `json:"important_priority"` // Tasks of at least this priority are important
+	DeriveUrgency     bool `json:"derive_urgency"`     // Whether tasks due soon are urgent
+	UrgentWithinDays  int  `json:"urgent_within_days"` // Tasks due within this many days of today, or overdue, are urgent
+}
+
+// MatrixTask represents a task classified in the Eisenhower matrix
+type MatrixTask struct {
+	TaskWithProject
+	Important        bool     `json:"important"`
+	Urgent           bool     `json:"urgent"`
+	ImportanceSource string   `json:"importance_source"` // explicit or priority
+	UrgencySource    string   `json:"urgency_source"`    // explicit, due_date or none
+	Quadrant         Quadrant `json:"quadrant"`
+}
+
+// MatrixQuadrant represents the tasks in one quadrant of the Eisenhower matrix
+type MatrixQuadrant struct {
+	Quadrant  Quadrant     `json:"quadrant"`
+	Important bool         `json:"important"`
+	Urgent    bool         `json:"urgent"`
+	Tasks     []MatrixTask `json:"tasks"`
+}
+
+// Matrix represents open tasks across projects sorted into the four quadrants of the Eisenhower matrix
+type Matrix struct {
+	Rules     MatrixRules      `json:"rules"`
+	Total     int              `json:"total"`
+	Quadrants []MatrixQuadrant `json:"quadrants"`
+}
+
+// ClassifyTasksRequest represents the request payload for reclassifying tasks in bulk. Quadrant sets both
+// importance and urgency; otherwise Important and Urgent set whichever is given. Reset returns the tasks
+// to classification by the matrix rules.
+type ClassifyTasksRequest struct {
+	TaskIDs   []int    `json:"task_ids" validate:"required,min=1,max=500,dive,gt=0"`
+	Quadrant  Quadrant `json:"quadrant,omitempty" validate:"omitempty,oneof=do schedule delegate eliminate"`
+	Important *bool    `json:"important,omitempty"`
+	Urgent    *bool    `json:"urgent,omitempty"`
+	Reset     bool     `json:"reset,omitempty"`
+}
+
+// ClassifyTasksResult represents the outcome of reclassifying tasks
+type ClassifyTasksResult struct {
+	Updated int          `json:"updated"`
+	Tasks   []MatrixTask `json:"tasks"`
+}
+
+// TaskReviewAction represents what a review did with a stale task
+type TaskReviewAction string
+
+const (
+	TaskReviewReviewed TaskReviewAction = "reviewed" // Looked at and kept as is
+	TaskReviewDefer    TaskReviewAction = "defer"    // Kept out of the review queue until a later time
+	TaskReviewCancel   TaskReviewAction = "cancel"   // Cancelled
+	TaskReviewBump     TaskReviewAction = "bump"     // Priority raised
+)
+
+// StaleTask represents an open task without changes or tracked time for a while
+type StaleTask struct {
+	TaskWithProject
+	LastActivityAt time.Time  `json:"last_activity_at"` // Latest of the last change and the last tracked time
+	LastTrackedAt  *time.Time `json:"last_tracked_at,omitempty"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+	IdleDays       int        `json:"idle_days"`
+}
+
+// TaskReviewQueue represents the stale tasks waiting for review
+type TaskReviewQueue struct {
+	StaleDays int         `json:"stale_days"`
+	Cutoff    time.Time   `json:"cutoff"` // Tasks without activity since are stale
+	Tasks     []StaleTask `json:"tasks"`
+}
+
+// ReviewTasksRequest represents the request payload for reviewing stale tasks in batch
+type ReviewTasksRequest struct {
+	TaskIDs      []int            `json:"task_ids" validate:"required,min=1,max=500,dive,gt=0"`
+	Action       TaskReviewAction `json:"action" validate:"required,oneof=reviewed defer cancel bump"`
+	DeferUntil   *time.Time       `json:"defer_until,omitempty"`                           // Required to defer
+	PriorityStep int              `json:"priority_step,omitempty" validate:"min=0,max=10"` // Added when bumping, defaults to 1
+}
+
+// ReviewTasksResult represents the tasks after a batch review
+type ReviewTasksResult struct {
+	Reviewed int    `json:"reviewed"`
+	Tasks    []Task `json:"tasks"`
+}
+
+// TaskReviewLogEntry represents one review of a task
+type TaskReviewLogEntry struct {
+	ID         int              `json:"id"`
+	TaskID     int              `json:"task_id"`
+	TaskTitle  string           `json:"task_title"`
+	ProjectID  int              `json:"project_id"`
+	Action     TaskReviewAction `json:"action"`
+	ReviewedAt time.Time        `json:"reviewed_at"`
+}
+
+// TaskReviewCounts counts reviews by action
+type TaskReviewCounts struct {
+	Reviewed  int `json:"reviewed"`
+	Deferred  int `json:"deferred"`
+	Cancelled int `json:"cancelled"`
+	Bumped    int `json:"bumped"`
+}
+
+// TaskReviewSummary represents a week of task reviews alongside the tasks created and completed in it
+type TaskReviewSummary struct {
+	Week          string               `json:"week"` // ISO week, e.g. 2024-W03
+	Timezone      string               `json:"timezone"`
+	Start         time.Time            `json:"start"`
+	End           time.Time            `json:"end"`
+	Actions       TaskReviewCounts     `json:"actions"`
+	TasksReviewed int                  `json:"tasks_reviewed"` // Distinct tasks reviewed
+	Created       int                  `json:"created"`
+	Completed     int                  `json:"completed"`
+	StaleNow      int                  `json:"stale_now"` // Tasks waiting for review at the time of the request
+	Log           []TaskReviewLogEntry `json:"log"`
+}